@@ -0,0 +1,131 @@
+package self
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ChainedConfigStore reads from an ordered list of ConfigStores, e.g. a local cache followed by a remote store.
+//
+// Reads return the first non-empty result, falling through to the next store on a miss or an error.
+// Writes go to the designated writer if one is set, otherwise to every store in the chain.
+type ChainedConfigStore struct {
+	stores []ConfigStore
+	writer ConfigStore
+}
+
+// Compile-time check to ensure ChainedConfigStore implements ConfigStore and ActionIdResolver interfaces
+var _ ConfigStore = (*ChainedConfigStore)(nil)
+var _ ActionIdResolver = (*ChainedConfigStore)(nil)
+var _ ConfigPrefetcher = (*ChainedConfigStore)(nil)
+
+// NewChainedConfigStore creates a ChainedConfigStore that reads from stores in order and writes to all of them
+func NewChainedConfigStore(stores ...ConfigStore) *ChainedConfigStore {
+	return &ChainedConfigStore{stores: stores}
+}
+
+// NewChainedConfigStoreWithWriter creates a ChainedConfigStore that reads from stores in order and only writes to writer
+func NewChainedConfigStoreWithWriter(writer ConfigStore, stores ...ConfigStore) *ChainedConfigStore {
+	return &ChainedConfigStore{stores: stores, writer: writer}
+}
+
+// GetConfig returns the first non-empty configuration found in the chain.
+// An error is only returned if no store has the config and at least one store failed.
+func (store *ChainedConfigStore) GetConfig(ctx context.Context, id string) (VerificationConfig, error) {
+	var errs []error
+	for _, s := range store.stores {
+		config, err := s.GetConfig(ctx, id)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !isEmptyVerificationConfig(config) {
+			return config, nil
+		}
+	}
+	return VerificationConfig{}, errors.Join(errs...)
+}
+
+// SetConfig stores the configuration in the writer, or in every store of the chain.
+// When writing to every store, the returned boolean is the one reported by the last store.
+func (store *ChainedConfigStore) SetConfig(ctx context.Context, id string, config VerificationConfig) (bool, error) {
+	if store.writer != nil {
+		return store.writer.SetConfig(ctx, id, config)
+	}
+
+	var created bool
+	var errs []error
+	for _, s := range store.stores {
+		c, err := s.SetConfig(ctx, id, config)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		created = c
+	}
+	return created, errors.Join(errs...)
+}
+
+// GetActionId returns the first non-empty action ID found in the chain.
+// An error is only returned if no store resolves the action ID and at least one store failed.
+func (store *ChainedConfigStore) GetActionId(ctx context.Context, userIdentifier string, userDefinedData string) (string, error) {
+	return store.ResolveActionId(ctx, ActionIdRequest{UserIdentifier: userIdentifier, UserDefinedData: userDefinedData})
+}
+
+// ResolveActionId returns the first non-empty action ID found in the chain, passing the full
+// request to stores implementing ActionIdResolver
+func (store *ChainedConfigStore) ResolveActionId(ctx context.Context, request ActionIdRequest) (string, error) {
+	var errs []error
+	for _, s := range store.stores {
+		actionId, err := resolveActionId(ctx, s, request)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if actionId != "" {
+			return actionId, nil
+		}
+	}
+	return "", errors.Join(errs...)
+}
+
+// PrefetchConfigs primes the cache stores of the chain: each config is read from the first store
+// holding it and copied into the stores before it that missed, e.g. a local cache in front of a
+// remote store. Call it before an instance takes traffic. The returned error matches
+// ErrConfigNotFound for IDs no store holds.
+func (store *ChainedConfigStore) PrefetchConfigs(ctx context.Context, ids ...string) error {
+	var errs []error
+	for _, id := range ids {
+		if err := store.prefetchConfig(ctx, id); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// prefetchConfig copies the config with the given ID into the stores preceding the first store holding it
+func (store *ChainedConfigStore) prefetchConfig(ctx context.Context, id string) error {
+	var misses []ConfigStore
+	var errs []error
+	for _, s := range store.stores {
+		config, err := s.GetConfig(ctx, id)
+		if err != nil {
+			errs = append(errs, err)
+			misses = append(misses, s)
+			continue
+		}
+		if isEmptyVerificationConfig(config) {
+			misses = append(misses, s)
+			continue
+		}
+
+		for _, miss := range misses {
+			if _, err := miss.SetConfig(ctx, id, config); err != nil {
+				return fmt.Errorf("failed to prefetch config %s: %w", id, err)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %s: %w", ErrConfigNotFound, id, errors.Join(errs...))
+}
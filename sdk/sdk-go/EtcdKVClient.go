@@ -0,0 +1,167 @@
+package self
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// EtcdKVClient is a KVClient for etcd v3, talking to its JSON gateway (/v3/kv/*, /v3/watch) so no
+// gRPC dependency is needed
+type EtcdKVClient struct {
+	endpoint   string
+	httpClient *http.Client
+}
+
+// Compile-time check to ensure EtcdKVClient implements KVClient interface
+var _ KVClient = (*EtcdKVClient)(nil)
+
+// NewEtcdKVClient creates an EtcdKVClient for the etcd endpoint, e.g. "http://etcd:2379".
+// httpClient may be nil to use http.DefaultClient; set it to configure TLS. It must not set a
+// timeout, which would end watches.
+func NewEtcdKVClient(endpoint string, httpClient *http.Client) *EtcdKVClient {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &EtcdKVClient{endpoint: strings.TrimRight(endpoint, "/"), httpClient: httpClient}
+}
+
+// etcdKeyValue is a key-value pair in etcd gateway responses; []byte fields are base64 encoded
+type etcdKeyValue struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// Get returns the value stored at key
+func (client *EtcdKVClient) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	var response struct {
+		Kvs []etcdKeyValue `json:"kvs"`
+	}
+	request := map[string]interface{}{"key": []byte(key)}
+	if err := client.call(ctx, "/v3/kv/range", request, &response); err != nil {
+		return nil, false, err
+	}
+	if len(response.Kvs) == 0 {
+		return nil, false, nil
+	}
+	return response.Kvs[0].Value, true, nil
+}
+
+// Put stores value at key, reporting whether the key already existed
+func (client *EtcdKVClient) Put(ctx context.Context, key string, value []byte) (bool, error) {
+	var response struct {
+		PrevKv *etcdKeyValue `json:"prev_kv"`
+	}
+	request := map[string]interface{}{"key": []byte(key), "value": value, "prev_kv": true}
+	if err := client.call(ctx, "/v3/kv/put", request, &response); err != nil {
+		return false, err
+	}
+	return response.PrevKv != nil, nil
+}
+
+// Watch streams changes to every key starting with prefix
+func (client *EtcdKVClient) Watch(ctx context.Context, prefix string) (<-chan KVEvent, error) {
+	request := map[string]interface{}{
+		"create_request": map[string]interface{}{
+			"key":       []byte(prefix),
+			"range_end": etcdPrefixRangeEnd(prefix),
+		},
+	}
+	body, err := client.post(ctx, "/v3/watch", request)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan KVEvent)
+	go func() {
+		defer close(events)
+		defer body.Close()
+		decoder := json.NewDecoder(body)
+		for {
+			var message struct {
+				Result struct {
+					Canceled bool `json:"canceled"`
+					Events   []struct {
+						Type string       `json:"type"` // Omitted for PUT
+						Kv   etcdKeyValue `json:"kv"`
+					} `json:"events"`
+				} `json:"result"`
+			}
+			if err := decoder.Decode(&message); err != nil || message.Result.Canceled {
+				return
+			}
+			for _, event := range message.Result.Events {
+				kvEvent := KVEvent{Key: string(event.Kv.Key), Value: event.Kv.Value}
+				if event.Type == "DELETE" {
+					kvEvent = KVEvent{Key: string(event.Kv.Key), Deleted: true}
+				}
+				select {
+				case events <- kvEvent:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// call posts request to the gateway path and decodes the response into response
+func (client *EtcdKVClient) call(ctx context.Context, path string, request, response interface{}) error {
+	body, err := client.post(ctx, path, request)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	if err := json.NewDecoder(body).Decode(response); err != nil {
+		return fmt.Errorf("etcd %s: failed to decode response: %v", path, err)
+	}
+	return nil
+}
+
+// post sends request to the gateway path, returning the response body of a successful call
+func (client *EtcdKVClient) post(ctx context.Context, path string, request interface{}) (io.ReadCloser, error) {
+	encoded, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, client.endpoint+path, bytes.NewReader(encoded))
+	if err != nil {
+		return nil, err
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+
+	httpResponse, err := client.httpClient.Do(httpRequest)
+	if err != nil {
+		return nil, fmt.Errorf("etcd %s: %v", path, err)
+	}
+	if httpResponse.StatusCode != http.StatusOK {
+		defer httpResponse.Body.Close()
+		var gatewayErr struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(io.LimitReader(httpResponse.Body, 1<<16)).Decode(&gatewayErr)
+		if gatewayErr.Message == "" {
+			gatewayErr.Message = httpResponse.Status
+		}
+		return nil, fmt.Errorf("etcd %s: %s", path, gatewayErr.Message)
+	}
+	return httpResponse.Body, nil
+}
+
+// etcdPrefixRangeEnd returns the range end matching every key starting with prefix
+func etcdPrefixRangeEnd(prefix string) []byte {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	// Every byte is 0xff, so the range is every key from prefix on
+	return []byte{0}
+}
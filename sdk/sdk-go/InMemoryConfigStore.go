@@ -2,47 +2,112 @@ package self
 
 import (
 	"context"
-
+	"fmt"
+	"sync"
 )
 
 // GetActionIdFunc is a function type for custom action ID generation
 type GetActionIdFunc func(ctx context.Context, userIdentifier string, userDefinedData string) (string, error)
 
-// InMemoryConfigStore provides an in-memory implementation of ConfigStore with custom action ID logic
+// InMemoryConfigStore provides an in-memory implementation of ConfigStore with custom action ID logic.
+// It is safe for concurrent use, and configs updated with SetConfig apply to the next verification.
 type InMemoryConfigStore struct {
+	mu              sync.RWMutex
 	configs         map[string]VerificationConfig
+	actionIds       map[string]string
 	getActionIdFunc GetActionIdFunc
 }
 
 // Compile-time check to ensure InMemoryConfigStore implements ConfigStore interface
 var _ ConfigStore = (*InMemoryConfigStore)(nil)
 
+// Compile-time check to ensure InMemoryConfigStore implements ConfigLister interface
+var _ ConfigLister = (*InMemoryConfigStore)(nil)
+
 // NewInMemoryConfigStore creates a new instance of InMemoryConfigStore
 func NewInMemoryConfigStore(getActionIdFunc GetActionIdFunc) *InMemoryConfigStore {
 	return &InMemoryConfigStore{
 		configs:         make(map[string]VerificationConfig),
+		actionIds:       make(map[string]string),
 		getActionIdFunc: getActionIdFunc,
 	}
 }
 
-// GetActionId uses the custom function to generate action IDs
+// GetActionId returns the action ID stored for userDefinedData with SetActionId, falling back to
+// the custom function
 func (store *InMemoryConfigStore) GetActionId(ctx context.Context, userIdentifier string, userDefinedData string) (string, error) {
+	store.mu.RLock()
+	actionId, exists := store.actionIds[userDefinedData]
+	store.mu.RUnlock()
+	if exists || store.getActionIdFunc == nil {
+		return actionId, nil
+	}
 	return store.getActionIdFunc(ctx, userIdentifier, userDefinedData)
 }
 
-// SetConfig stores a configuration with the given ID
+// SetActionId maps userDefinedData to the given action ID, taking precedence over the custom function
+func (store *InMemoryConfigStore) SetActionId(userDefinedData string, actionId string) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.actionIds[userDefinedData] = actionId
+}
+
+// SetConfig stores a configuration with the given ID, normalized with NormalizeCountryLists
 // Returns true if the configuration was newly created, false if it was updated
 func (store *InMemoryConfigStore) SetConfig(ctx context.Context, id string, config VerificationConfig) (bool, error) {
+	config, err := NormalizeCountryLists(config)
+	if err != nil {
+		return false, fmt.Errorf("invalid config %q: %w", id, err)
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
 	_, existed := store.configs[id]
-	store.configs[id] = config
+	store.configs[id] = cloneVerificationConfig(config)
 	return !existed, nil
 }
 
-// GetConfig retrieves a configuration by ID
+// GetConfig retrieves a copy of the configuration with the given ID
 	func (store *InMemoryConfigStore) GetConfig(ctx context.Context, id string) (VerificationConfig, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
 	config, exists := store.configs[id]
 	if !exists {
 		return VerificationConfig{}, nil
 	}
-	return config, nil
+	return cloneVerificationConfig(config), nil
+}
+
+// ListConfigs returns a page of the stored configs, ordered by ID
+func (store *InMemoryConfigStore) ListConfigs(ctx context.Context, request ListRequest) (ConfigPage, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	ids := make([]string, 0, len(store.configs))
+	for id := range store.configs {
+		ids = append(ids, id)
+	}
+
+	ids, nextCursor := pageKeys(ids, request)
+	page := ConfigPage{Configs: make([]ConfigEntry, 0, len(ids)), NextCursor: nextCursor}
+	for _, id := range ids {
+		page.Configs = append(page.Configs, ConfigEntry{Id: id, Config: cloneVerificationConfig(store.configs[id])})
+	}
+	return page, nil
+}
+
+// ListActionIds returns a page of the mappings stored with SetActionId, ordered by userDefinedData
+func (store *InMemoryConfigStore) ListActionIds(ctx context.Context, request ListRequest) (ActionIdPage, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	keys := make([]string, 0, len(store.actionIds))
+	for userDefinedData := range store.actionIds {
+		keys = append(keys, userDefinedData)
+	}
+
+	keys, nextCursor := pageKeys(keys, request)
+	page := ActionIdPage{Mappings: make([]ActionIdMapping, 0, len(keys)), NextCursor: nextCursor}
+	for _, userDefinedData := range keys {
+		page.Mappings = append(page.Mappings, ActionIdMapping{UserDefinedData: userDefinedData, ActionId: store.actionIds[userDefinedData]})
+	}
+	return page, nil
 }
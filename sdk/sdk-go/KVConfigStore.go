@@ -0,0 +1,239 @@
+package self
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultKVConfigPrefix is the key prefix under which KVConfigStore keeps configs when none is given
+const DefaultKVConfigPrefix = "self/configs/"
+
+// kvWatchRetryDelay is how long KVConfigStore waits before re-establishing a failed watch
+const kvWatchRetryDelay = time.Second
+
+// KVEvent describes a change to a watched key
+type KVEvent struct {
+	Key   string
+	Value []byte
+	// Deleted is set when the key was removed, in which case Value is nil
+	Deleted bool
+}
+
+// KVClient is the subset of a key-value store (etcd, Consul, ...) used by KVConfigStore
+type KVClient interface {
+	// Get returns the value stored at key, and false if the key does not exist
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Put stores value at key, reporting whether the key already existed
+	Put(ctx context.Context, key string, value []byte) (bool, error)
+	// Watch streams changes to every key starting with prefix until ctx is done or the watch
+	// fails, after which the channel is closed
+	Watch(ctx context.Context, prefix string) (<-chan KVEvent, error)
+}
+
+// KVConfigStore implements ConfigStore on top of a KVClient, storing each config as JSON under
+// prefix+id. Configs are cached after the first read and the cache is kept current by watching
+// the prefix, so a config updated in the KV store applies to the next verification without a
+// round-trip per call. The cache is dropped whenever the watch is interrupted.
+//
+// A config written with SetConfig is read from the KV store until the watch delivers the write, so
+// a late event for an earlier value never hides it.
+type KVConfigStore struct {
+	client          KVClient
+	prefix          string
+	getActionIdFunc GetActionIdFunc
+
+	mu       sync.RWMutex
+	cache    map[string]VerificationConfig
+	pending  map[string][]string // Values written by SetConfig whose watch events have not arrived yet
+	version  uint64              // Bumped on every change, so reads racing a change are not cached
+	watching bool                // Reads are only cached while the watch is up to keep them current
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// Compile-time check to ensure KVConfigStore implements ConfigStore interface
+var _ ConfigStore = (*KVConfigStore)(nil)
+
+// NewKVConfigStore creates a KVConfigStore reading configs under prefix (DefaultKVConfigPrefix when
+// empty) and starts watching it. Call Close to stop the watch.
+//
+// getActionIdFunc resolves config IDs as in InMemoryConfigStore; when nil, the user defined data is used as the ID.
+func NewKVConfigStore(client KVClient, prefix string, getActionIdFunc GetActionIdFunc) (*KVConfigStore, error) {
+	if prefix == "" {
+		prefix = DefaultKVConfigPrefix
+	}
+	watchCtx, cancel := context.WithCancel(context.Background())
+	events, err := client.Watch(watchCtx, prefix)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to watch %q: %v", prefix, err)
+	}
+
+	store := &KVConfigStore{
+		client:          client,
+		prefix:          prefix,
+		getActionIdFunc: getActionIdFunc,
+		cache:           make(map[string]VerificationConfig),
+		pending:         make(map[string][]string),
+		watching:        true,
+		cancel:          cancel,
+		done:            make(chan struct{}),
+	}
+	go store.watch(watchCtx, events)
+	return store, nil
+}
+
+// GetConfig returns a copy of the cached config, reading it from the KV store on a miss.
+// A missing key returns an empty config, as with InMemoryConfigStore.
+func (store *KVConfigStore) GetConfig(ctx context.Context, id string) (VerificationConfig, error) {
+	store.mu.RLock()
+	config, cached := store.cache[id]
+	version := store.version
+	store.mu.RUnlock()
+	if cached {
+		return cloneVerificationConfig(config), nil
+	}
+
+	value, exists, err := store.client.Get(ctx, store.prefix+id)
+	if err != nil {
+		return VerificationConfig{}, fmt.Errorf("failed to read config %q: %v", id, err)
+	}
+	if !exists {
+		return VerificationConfig{}, nil
+	}
+	if err := json.Unmarshal(value, &config); err != nil {
+		return VerificationConfig{}, fmt.Errorf("failed to decode config %q: %v", id, err)
+	}
+
+	store.mu.Lock()
+	// A change since the read began may be newer than the value read
+	if store.watching && store.version == version && len(store.pending[id]) == 0 {
+		store.cache[id] = cloneVerificationConfig(config)
+	}
+	store.mu.Unlock()
+	return config, nil
+}
+
+// SetConfig writes the config, normalized with NormalizeCountryLists, to the KV store. The cache
+// picks it up from the watch. Returns true if the configuration was newly created, false if it was updated.
+func (store *KVConfigStore) SetConfig(ctx context.Context, id string, config VerificationConfig) (bool, error) {
+	config, err := NormalizeCountryLists(config)
+	if err != nil {
+		return false, fmt.Errorf("invalid config %q: %w", id, err)
+	}
+	value, err := json.Marshal(config)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode config %q: %v", id, err)
+	}
+	// Registered before the write, as its event may arrive before Put returns
+	store.mu.Lock()
+	delete(store.cache, id)
+	store.version++
+	if store.watching {
+		store.pending[id] = append(store.pending[id], string(value))
+	}
+	store.mu.Unlock()
+
+	existed, err := store.client.Put(ctx, store.prefix+id, value)
+	if err != nil {
+		store.mu.Lock()
+		store.removePending(id, string(value))
+		store.mu.Unlock()
+		return false, fmt.Errorf("failed to write config %q: %v", id, err)
+	}
+	return !existed, nil
+}
+
+// GetActionId resolves the config ID with the custom function, or returns userDefinedData when none is set
+func (store *KVConfigStore) GetActionId(ctx context.Context, userIdentifier string, userDefinedData string) (string, error) {
+	if store.getActionIdFunc == nil {
+		return userDefinedData, nil
+	}
+	return store.getActionIdFunc(ctx, userIdentifier, userDefinedData)
+}
+
+// Close stops watching the KV store and waits for the watch to exit
+func (store *KVConfigStore) Close() error {
+	store.cancel()
+	<-store.done
+	return nil
+}
+
+// watch applies KV events to the cache, re-establishing the watch until ctx is done
+func (store *KVConfigStore) watch(ctx context.Context, events <-chan KVEvent) {
+	defer close(store.done)
+	for {
+		for event := range events {
+			store.apply(event)
+		}
+		// Changes may have been missed while the watch was down
+		store.setWatching(false)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(kvWatchRetryDelay):
+			}
+			var err error
+			if events, err = store.client.Watch(ctx, store.prefix); err == nil {
+				break
+			}
+		}
+		store.setWatching(true)
+	}
+}
+
+// setWatching records whether the watch is up, dropping the cache when it goes down
+func (store *KVConfigStore) setWatching(watching bool) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.watching = watching
+	store.version++
+	if !watching {
+		// Events for pending writes may be lost with the watch
+		store.cache = make(map[string]VerificationConfig)
+		store.pending = make(map[string][]string)
+	}
+}
+
+// apply updates the cache with a single KV event
+func (store *KVConfigStore) apply(event KVEvent) {
+	id, ok := strings.CutPrefix(event.Key, store.prefix)
+	if !ok {
+		return
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.version++
+	store.removePending(id, string(event.Value))
+	var config VerificationConfig
+	if len(store.pending[id]) > 0 || event.Deleted || json.Unmarshal(event.Value, &config) != nil {
+		// Writes still pending are read from the KV store until their events arrive, and
+		// undecodable values are dropped so the next read reports the error
+		delete(store.cache, id)
+		return
+	}
+	store.cache[id] = config
+}
+
+// removePending forgets one pending write of value to id; the caller holds store.mu
+func (store *KVConfigStore) removePending(id string, value string) {
+	values := store.pending[id]
+	for i, pending := range values {
+		if pending == value {
+			values = append(values[:i], values[i+1:]...)
+			break
+		}
+	}
+	if len(values) == 0 {
+		delete(store.pending, id)
+	} else {
+		store.pending[id] = values
+	}
+}
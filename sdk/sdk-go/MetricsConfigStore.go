@@ -0,0 +1,108 @@
+package self
+
+import (
+	"context"
+	"time"
+)
+
+// ConfigStoreOperation names a ConfigStore method
+type ConfigStoreOperation string
+
+const (
+	ConfigStoreGetConfig   ConfigStoreOperation = "GetConfig"
+	ConfigStoreSetConfig   ConfigStoreOperation = "SetConfig"
+	ConfigStoreGetActionId ConfigStoreOperation = "GetActionId"
+)
+
+// ConfigStoreEvent describes a single completed ConfigStore call
+type ConfigStoreEvent struct {
+	Operation ConfigStoreOperation
+	Duration  time.Duration
+	// Hit reports whether a read found a config or action ID. It is always false for SetConfig.
+	Hit bool
+	Err error
+}
+
+// ConfigStoreMetricsFunc receives an event after every ConfigStore call, e.g. to export latency
+// histograms and hit rates. It runs synchronously and should not block.
+type ConfigStoreMetricsFunc func(ctx context.Context, event ConfigStoreEvent)
+
+// MetricsConfigStore wraps a ConfigStore and reports the duration and outcome of every call
+type MetricsConfigStore struct {
+	store   ConfigStore
+	metrics ConfigStoreMetricsFunc
+}
+
+// Compile-time check to ensure MetricsConfigStore implements ConfigStore and the resolver interfaces
+var _ ConfigStore = (*MetricsConfigStore)(nil)
+var _ ActionIdResolver = (*MetricsConfigStore)(nil)
+var _ CandidateActionIdResolver = (*MetricsConfigStore)(nil)
+var _ ConfigPrefetcher = (*MetricsConfigStore)(nil)
+
+// NewMetricsConfigStore creates a MetricsConfigStore reporting the calls to store to metrics
+func NewMetricsConfigStore(store ConfigStore, metrics ConfigStoreMetricsFunc) *MetricsConfigStore {
+	return &MetricsConfigStore{store: store, metrics: metrics}
+}
+
+// GetConfig retrieves the configuration from the wrapped store
+func (store *MetricsConfigStore) GetConfig(ctx context.Context, id string) (VerificationConfig, error) {
+	start := time.Now()
+	config, err := store.store.GetConfig(ctx, id)
+	store.report(ctx, ConfigStoreGetConfig, start, err == nil && !isEmptyVerificationConfig(config), err)
+	return config, err
+}
+
+// SetConfig stores the configuration in the wrapped store
+func (store *MetricsConfigStore) SetConfig(ctx context.Context, id string, config VerificationConfig) (bool, error) {
+	start := time.Now()
+	created, err := store.store.SetConfig(ctx, id, config)
+	store.report(ctx, ConfigStoreSetConfig, start, false, err)
+	return created, err
+}
+
+// GetActionId retrieves the action ID from the wrapped store
+func (store *MetricsConfigStore) GetActionId(ctx context.Context, userIdentifier string, userDefinedData string) (string, error) {
+	start := time.Now()
+	actionId, err := store.store.GetActionId(ctx, userIdentifier, userDefinedData)
+	store.report(ctx, ConfigStoreGetActionId, start, err == nil && actionId != "", err)
+	return actionId, err
+}
+
+// ResolveActionId resolves the action ID through the wrapped store, reported as ConfigStoreGetActionId
+func (store *MetricsConfigStore) ResolveActionId(ctx context.Context, request ActionIdRequest) (string, error) {
+	start := time.Now()
+	actionId, err := resolveActionId(ctx, store.store, request)
+	store.report(ctx, ConfigStoreGetActionId, start, err == nil && actionId != "", err)
+	return actionId, err
+}
+
+// ResolveCandidateActionIds resolves the candidate action IDs through the wrapped store, reported as
+// ConfigStoreGetActionId
+func (store *MetricsConfigStore) ResolveCandidateActionIds(ctx context.Context, request ActionIdRequest) ([]string, error) {
+	start := time.Now()
+	actionIds, err := resolveActionIds(ctx, store.store, request)
+	store.report(ctx, ConfigStoreGetActionId, start, err == nil && len(actionIds) > 0, err)
+	return actionIds, err
+}
+
+// PrefetchConfigs prefetches the configs through the wrapped store. Stores that do not prefetch
+// are read once per ID, each read reported as ConfigStoreGetConfig.
+func (store *MetricsConfigStore) PrefetchConfigs(ctx context.Context, ids ...string) error {
+	if prefetcher, ok := store.store.(ConfigPrefetcher); ok {
+		return prefetcher.PrefetchConfigs(ctx, ids...)
+	}
+	return loadConfigs(ctx, store, ids)
+}
+
+// report sends the event for a finished call to the metrics function
+func (store *MetricsConfigStore) report(ctx context.Context, operation ConfigStoreOperation, start time.Time, hit bool, err error) {
+	if store.metrics == nil {
+		return
+	}
+	store.metrics(ctx, ConfigStoreEvent{
+		Operation: operation,
+		Duration:  time.Since(start),
+		Hit:       hit,
+		Err:       err,
+	})
+}
@@ -0,0 +1,96 @@
+package self
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrUnauthorized is returned by an AuthorizeFunc to deny a request
+var ErrUnauthorized = errors.New("unauthorized")
+
+// AuthorizeFunc decides whether r may use an admin endpoint, returning an error to deny it
+type AuthorizeFunc func(r *http.Request) error
+
+// BearerTokenAuthorizer allows requests carrying "Authorization: Bearer <token>", compared in constant time
+func BearerTokenAuthorizer(token string) AuthorizeFunc {
+	return func(r *http.Request) error {
+		provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			return ErrUnauthorized
+		}
+		return nil
+	}
+}
+
+// ConfigHandler is an http.Handler that creates or updates verification configs at runtime.
+// Mount it on a path with an {id} wildcard:
+//
+//	mux.Handle("POST /api/config/{id}", self.NewConfigHandler(store, self.BearerTokenAuthorizer(token)))
+type ConfigHandler struct {
+	store     ConfigStore
+	authorize AuthorizeFunc
+
+	// MaxBodyBytes limits the size of the request body (DefaultMaxBodyBytes when zero)
+	MaxBodyBytes int64
+}
+
+// ConfigUpdateResponse is the JSON body returned after a config is stored
+type ConfigUpdateResponse struct {
+	Status  string `json:"status"`
+	Id      string `json:"id"`
+	Created bool   `json:"created"` // False when an existing config was updated
+}
+
+// NewConfigHandler creates a ConfigHandler writing to store. Every request must pass authorize;
+// a nil authorize denies all requests, so the endpoint is never open by accident.
+func NewConfigHandler(store ConfigStore, authorize AuthorizeFunc) *ConfigHandler {
+	return &ConfigHandler{store: store, authorize: authorize}
+}
+
+// ServeHTTP validates the VerificationConfig in the request body and stores it under the {id} path
+// value (or the last path segment), answering 201 when the config was created and 200 when it was updated
+func (h *ConfigHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.authorize == nil || h.authorize(r) != nil {
+		writeJSON(w, http.StatusUnauthorized, NewErrorResponse(ErrorCodeUnauthorized, "Unauthorized"))
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, NewErrorResponse(ErrorCodeInvalidRequest, "Method not allowed"))
+		return
+	}
+
+	id := r.PathValue("id")
+	if id == "" {
+		id = r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	}
+	if id == "" {
+		validationErr := &ValidationError{}
+		validationErr.add("id", "is required")
+		writeError(w, http.StatusBadRequest, validationErr)
+		return
+	}
+
+	maxBodyBytes := h.MaxBodyBytes
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = DefaultMaxBodyBytes
+	}
+	config, err := DecodeVerificationConfig(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	created, err := h.store.SetConfig(r.Context(), id, config)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	writeJSON(w, status, ConfigUpdateResponse{Status: "success", Id: id, Created: created})
+}
@@ -0,0 +1,145 @@
+package self
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// ErrUnknownAttestationType is returned in strict mode (WithStrictAttestationIds) for attestation IDs
+// that are neither built in nor registered with RegisterAttestationVerifier. It matches
+// ErrAttestationNotAllowed.
+var ErrUnknownAttestationType = fmt.Errorf("%w: unknown attestation type", ErrAttestationNotAllowed)
+
+// AttestationNotAllowedError reports a proof of an attestation type missing from the verifier's
+// allowedIds, with the allowed types so handlers can tell the user which documents are accepted. It
+// is the error of the InvalidId issue and matches ErrAttestationNotAllowed.
+type AttestationNotAllowedError struct {
+	AttestationId AttestationId
+	Allowed       []AttestationId // In ascending order
+}
+
+func (e *AttestationNotAllowedError) Error() string {
+	return fmt.Sprintf("%v: received %d, allowed %v", ErrAttestationNotAllowed, e.AttestationId, e.Allowed)
+}
+
+// Is reports whether target is ErrAttestationNotAllowed
+func (e *AttestationNotAllowedError) Is(target error) bool {
+	return target == ErrAttestationNotAllowed
+}
+
+// attestationNotAllowedIssue returns the InvalidId issue for a proof of attestationId, which the
+// verifier does not allow
+func (s *BackendVerifier) attestationNotAllowedIssue(attestationId AttestationId) ConfigIssue {
+	var allowed []AttestationId
+	for id, ok := range s.allowedIDs {
+		if ok {
+			allowed = append(allowed, id)
+		}
+	}
+	slices.Sort(allowed)
+	return ConfigIssue{
+		Type:    InvalidId,
+		Message: fmt.Sprintf("Attestation ID is not allowed, received: %d, allowed: %v", attestationId, allowed),
+		Err:     &AttestationNotAllowedError{AttestationId: attestationId, Allowed: allowed},
+	}
+}
+
+// WithStrictAttestationIds makes Verify reject unknown attestation IDs before any other check, with
+// ErrUnknownAttestationType naming the received value. A wrong attestation ID is the most common
+// client mistake; without strict mode it is only reported once the public signals were processed.
+func WithStrictAttestationIds() VerifierOption {
+	return func(s *BackendVerifier) {
+		s.strictAttestationIds = true
+	}
+}
+
+// ParseAttestationType maps an attestation ID received from a client to a known AttestationId: a
+// built-in type or one registered with RegisterAttestationVerifier. Unknown IDs fail with
+// ErrUnknownAttestationType.
+func (s *BackendVerifier) ParseAttestationType(id int) (AttestationId, error) {
+	attestationId := AttestationId(id)
+	if !AllIds[attestationId] && s.attestationVerifier(attestationId) == nil {
+		return 0, fmt.Errorf("%w: received %d, expected %d (passport), %d (EU ID card), %d (Aadhaar) or a registered type",
+			ErrUnknownAttestationType, id, Passport, EUCard, Aadhaar)
+	}
+	return attestationId, nil
+}
+
+// AttestationVerifier verifies proofs of a custom attestation type, e.g. a document type with its own circuit
+type AttestationVerifier interface {
+	// Verify checks the proof and returns its result, or an error if it cannot be verified
+	Verify(ctx context.Context, proof VcAndDiscloseProof, publicSignals []string, userContextData string) (*VerificationResult, error)
+}
+
+// RegisterAttestationVerifier verifies proofs of attestation type id with v. The ID must also be
+// allowed in the verifier's allowedIds. Built-in attestation types always use the default
+// verification and cannot be overridden.
+func (s *BackendVerifier) RegisterAttestationVerifier(id AttestationId, v AttestationVerifier) error {
+	if AllIds[id] {
+		return fmt.Errorf("attestation ID %d is built in and cannot be overridden", id)
+	}
+	if v == nil {
+		return fmt.Errorf("attestation verifier for ID %d must not be nil", id)
+	}
+
+	s.attestationVerifiersMu.Lock()
+	defer s.attestationVerifiersMu.Unlock()
+	if s.attestationVerifiers == nil {
+		s.attestationVerifiers = make(map[AttestationId]AttestationVerifier)
+	}
+	s.attestationVerifiers[id] = v
+	return nil
+}
+
+// attestationVerifier returns the custom verifier registered for id, or nil
+func (s *BackendVerifier) attestationVerifier(id AttestationId) AttestationVerifier {
+	s.attestationVerifiersMu.RLock()
+	defer s.attestationVerifiersMu.RUnlock()
+	return s.attestationVerifiers[id]
+}
+
+// verifyCustomAttestation verifies a proof with the custom verifier v registered for attestationId
+func (s *BackendVerifier) verifyCustomAttestation(
+	ctx context.Context,
+	v AttestationVerifier,
+	attestationId AttestationId,
+	proof VcAndDiscloseProof,
+	publicSignals []string,
+	userContextData string,
+) (*VerificationResult, error) {
+	if !s.allowedIDs[attestationId] {
+		return nil, NewConfigMismatchError([]ConfigIssue{s.attestationNotAllowedIssue(attestationId)})
+	}
+
+	var result *VerificationResult
+	var err, panicErr error
+	step := fmt.Sprintf("attestation %d verification", attestationId)
+	proofVerificationTime, budgetErr := s.runProofStep(ctx, step, func(ctx context.Context) {
+		panicErr = s.recoverPanic(step, func() {
+			if keyed, ok := v.(KeyedAttestationVerifier); ok && s.keyCache != nil {
+				result, err = s.verifyWithKeySource(ctx, keyed, attestationId, proof, publicSignals, userContextData)
+				return
+			}
+			result, err = v.Verify(ctx, proof, publicSignals, userContextData)
+		})
+	})
+	if budgetErr != nil {
+		return nil, budgetErr
+	}
+	if panicErr != nil {
+		return nil, panicErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, fmt.Errorf("attestation verifier for ID %d returned no result", attestationId)
+	}
+	if err := verifyDisclosureSalts(ctx, v, attestationId, result, publicSignals); err != nil {
+		return nil, err
+	}
+	result.AttestationId = attestationId
+	result.ProofVerificationTime = proofVerificationTime
+	return result, nil
+}
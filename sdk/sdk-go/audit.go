@@ -0,0 +1,194 @@
+package self
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// VerificationAudit is the audit record written after each verification.
+// It identifies the user by the pseudonymous user identifier bound by the proof, but never contains
+// raw userContextData or disclosed attributes: failures are recorded by ErrorCode and a message
+// naming the failed check, without the values the detailed error may carry.
+type VerificationAudit struct {
+	Timestamp       time.Time     `json:"timestamp"`
+	AttestationId   AttestationId `json:"attestationId"`
+	UserIdentifier  string        `json:"userIdentifier,omitempty"`
+	UserContextData string        `json:"userContextData,omitempty"` // Redacted with RedactUserContextData
+	ConfigId        string        `json:"configId,omitempty"`
+	ConfigHash      string        `json:"configHash,omitempty"` // VerificationConfig.Hash of the policy in force
+	Nullifier       string        `json:"nullifier,omitempty"`
+	IsValid         bool          `json:"isValid"`
+	ErrorCode       ErrorCode     `json:"errorCode,omitempty"`
+	Error           string        `json:"error,omitempty"` // Message of the ErrorCode's error, see auditErrorMessage
+	// OFACOverride is set when an OFACOverrideStore cleared an OFAC hit
+	OFACOverride bool `json:"ofacOverride,omitempty"`
+	// RawDisclosure is set when the result carried the unmasked disclosure (see WithIncludeRawDisclosure)
+	RawDisclosure bool `json:"rawDisclosure,omitempty"`
+	// MRZLines is set when the result carried the reconstructed MRZ lines (see WithIncludeMRZLines)
+	MRZLines bool `json:"mrzLines,omitempty"`
+	// Cached is set when the result was answered from the ResultCache, e.g. for a retried request
+	Cached bool `json:"cached,omitempty"`
+}
+
+// AuditSink persists verification audit records
+type AuditSink interface {
+	// Record stores a single audit record
+	Record(ctx context.Context, audit VerificationAudit) error
+}
+
+// recordAudit builds the audit record for a finished verification and writes it to the audit sink.
+// Sink failures are logged and never change the verification outcome.
+func (s *BackendVerifier) recordAudit(
+	ctx context.Context,
+	attestationId AttestationId,
+	userContextData string,
+	state *verificationState,
+	result *VerificationResult,
+	err error,
+) {
+	audit := VerificationAudit{
+		Timestamp:       time.Now().UTC(),
+		AttestationId:   attestationId,
+		UserIdentifier:  state.userIdentifier,
+		UserContextData: RedactUserContextData(userContextData),
+		ConfigId:        state.configId,
+	}
+	if state.config != nil {
+		audit.ConfigHash = state.config.Hash()
+	}
+
+	switch {
+	case err != nil:
+		audit.ErrorCode = ErrorCodeFor(err)
+		audit.Error = auditErrorMessage(err)
+	case !result.IsValidDetails.IsValid:
+		audit.ErrorCode = ErrorCodeInvalidProof
+		audit.Error = ErrInvalidProof.Error()
+	default:
+		audit.IsValid = true
+	}
+	if result != nil {
+		audit.Nullifier = result.DiscloseOutput.Nullifier
+		audit.RawDisclosure = result.RawDiscloseOutput != nil
+		audit.MRZLines = result.MRZLines != nil
+		audit.OFACOverride = result.OFACOverride != nil
+		audit.Cached = result.Cached
+	}
+
+	if recordErr := s.auditSink.Record(ctx, audit); recordErr != nil {
+		s.logger.Printf("self: failed to record audit for userContextData %s: %v", audit.UserContextData, recordErr)
+	}
+}
+
+// auditErrorMessage returns the message of the verification error err matches, which names the failed
+// check but none of the values, e.g. nationality or date of birth, in the issue messages of err
+func auditErrorMessage(err error) string {
+	for _, entry := range errorCodes {
+		if errors.Is(err, entry.err) {
+			return entry.err.Error()
+		}
+	}
+	return "internal error"
+}
+
+// InMemoryAuditSink keeps audit records in memory, mainly for tests and development
+type InMemoryAuditSink struct {
+	mu      sync.RWMutex
+	records []VerificationAudit
+}
+
+// Compile-time check to ensure InMemoryAuditSink implements AuditSink interface
+var _ AuditSink = (*InMemoryAuditSink)(nil)
+
+// NewInMemoryAuditSink creates an empty InMemoryAuditSink
+func NewInMemoryAuditSink() *InMemoryAuditSink {
+	return &InMemoryAuditSink{}
+}
+
+// Record appends the audit record
+func (sink *InMemoryAuditSink) Record(ctx context.Context, audit VerificationAudit) error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	sink.records = append(sink.records, audit)
+	return nil
+}
+
+// Records returns a copy of all recorded audits in recording order
+func (sink *InMemoryAuditSink) Records() []VerificationAudit {
+	sink.mu.RLock()
+	defer sink.mu.RUnlock()
+	records := make([]VerificationAudit, len(sink.records))
+	copy(records, sink.records)
+	return records
+}
+
+// FileAuditSink appends audit records to a file as JSON lines
+type FileAuditSink struct {
+	file *jsonLinesFile
+}
+
+// Compile-time check to ensure FileAuditSink implements AuditSink interface
+var _ AuditSink = (*FileAuditSink)(nil)
+
+// NewFileAuditSink opens (or creates) the file at path for appending audit records
+func NewFileAuditSink(path string) (*FileAuditSink, error) {
+	file, err := openJSONLinesFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file: %v", err)
+	}
+	return &FileAuditSink{file: file}, nil
+}
+
+// Record writes the audit record as a single JSON line
+func (sink *FileAuditSink) Record(ctx context.Context, audit VerificationAudit) error {
+	if err := sink.file.write(audit); err != nil {
+		return fmt.Errorf("failed to write audit record: %v", err)
+	}
+	return nil
+}
+
+// Close closes the underlying file
+func (sink *FileAuditSink) Close() error {
+	return sink.file.close()
+}
+
+// jsonLinesFile appends JSON values to a file, one per line
+type jsonLinesFile struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// openJSONLinesFile opens (or creates) the file at path for appending
+func openJSONLinesFile(path string) (*jsonLinesFile, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &jsonLinesFile{file: file}, nil
+}
+
+// write encodes value as a single JSON line
+func (f *jsonLinesFile) write(value interface{}) error {
+	line, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, err = f.file.Write(line)
+	return err
+}
+
+// close closes the file
+func (f *jsonLinesFile) close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
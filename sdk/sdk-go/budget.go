@@ -0,0 +1,44 @@
+package self
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// runProofStep runs fn, the proof verification step, and returns how long it took. With a budget
+// set by WithVerificationBudget, fn runs under a context expiring after the budget and is abandoned
+// once it expires, failing with ErrVerificationBudgetExceeded. Steps that block without observing
+// the context keep running in the background until they return, but no longer hold up the request.
+func (s *BackendVerifier) runProofStep(ctx context.Context, step string, fn func(ctx context.Context)) (time.Duration, error) {
+	start := time.Now()
+	if s.verificationBudget <= 0 {
+		fn(ctx)
+		return time.Since(start), nil
+	}
+
+	budgetCtx, cancel := context.WithTimeout(ctx, s.verificationBudget)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(budgetCtx)
+	}()
+
+	select {
+	case <-done:
+	case <-budgetCtx.Done():
+	}
+	elapsed := time.Since(start)
+
+	// The step may have failed because the budget cut it short, so the budget takes precedence
+	if budgetCtx.Err() != nil && ctx.Err() == nil {
+		s.logger.Printf("self: %s aborted after %s, exceeding the verification budget of %s", step, elapsed, s.verificationBudget)
+		return elapsed, fmt.Errorf("%w: %s exceeded %s", ErrVerificationBudgetExceeded, step, s.verificationBudget)
+	}
+	if ctx.Err() != nil {
+		return elapsed, ctx.Err()
+	}
+	return elapsed, nil
+}
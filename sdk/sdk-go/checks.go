@@ -0,0 +1,134 @@
+package self
+
+import "fmt"
+
+// CheckName identifies a policy check applied from a VerificationConfig
+type CheckName string
+
+const (
+	CheckMinimumAge        CheckName = "minimumAge"
+	CheckExcludedCountries CheckName = "excludedCountries"
+	CheckOfac              CheckName = "ofac"
+	CheckTimestamp         CheckName = "timestamp"
+	// CheckSanctionedNationalities is the nationality sanctions check, kept separate from CheckExcludedCountries
+	CheckSanctionedNationalities CheckName = "sanctionedNationalities"
+	// CheckAllowedIssuingStates is the document provenance check, kept separate from nationality checks
+	CheckAllowedIssuingStates CheckName = "allowedIssuingStates"
+	// CheckBiometricCommitment is the biometric commitment requirement
+	CheckBiometricCommitment CheckName = "biometricCommitment"
+	// CheckNationalityMatchesIssuer is the nationality and issuing state consistency check
+	CheckNationalityMatchesIssuer CheckName = "nationalityMatchesIssuer"
+	// CheckAllowedDocumentSubtypes is the passport subtype check
+	CheckAllowedDocumentSubtypes CheckName = "allowedDocumentSubtypes"
+	// CheckIssuedAsMinor is the rejection of documents issued to minors
+	CheckIssuedAsMinor CheckName = "issuedAsMinor"
+)
+
+// issueChecks maps the ConfigMismatch types that policy checks report to their CheckName.
+// Cryptographic and binding issues (scope, root, user context, attestation ID), inconsistent or
+// placeholder disclosures and fields missing from the disclosures requested for the call are
+// always blocking.
+var issueChecks = map[ConfigMismatch]CheckName{
+	InvalidMinimumAge:             CheckMinimumAge,
+	InvalidForbiddenCountriesList: CheckExcludedCountries,
+	InvalidOfac:                   CheckOfac,
+	InvalidTimestamp:              CheckTimestamp,
+	InvalidSanctionedNationality:  CheckSanctionedNationalities,
+	InvalidIssuingState:           CheckAllowedIssuingStates,
+	MissingBiometric:              CheckBiometricCommitment,
+	InvalidNationalityIssuer:      CheckNationalityMatchesIssuer,
+	InvalidDocumentSubtype:        CheckAllowedDocumentSubtypes,
+	InvalidIssuedAsMinor:          CheckIssuedAsMinor,
+}
+
+// isAdvisory reports whether the config marks check as advisory
+func (c VerificationConfig) isAdvisory(check CheckName) bool {
+	for _, advisory := range c.AdvisoryChecks {
+		if advisory == check {
+			return true
+		}
+	}
+	return false
+}
+
+// partitionAdvisoryIssues splits issues into blocking issues and issues raised by advisory checks
+func (c VerificationConfig) partitionAdvisoryIssues(issues []ConfigIssue) (blocking []ConfigIssue, advisory []ConfigIssue) {
+	for _, issue := range issues {
+		if check, ok := issueChecks[issue.Type]; ok && c.isAdvisory(check) {
+			advisory = append(advisory, issue)
+		} else {
+			blocking = append(blocking, issue)
+		}
+	}
+	return blocking, advisory
+}
+
+// CheckDescriptor describes a check a config applies, for consent screens and policy documentation
+type CheckDescriptor struct {
+	Name        CheckName `json:"name"`
+	Description string    `json:"description"`
+	// Advisory is set when failures are reported in AdvisoryIssues instead of failing the verification
+	Advisory bool `json:"advisory,omitempty"`
+	// Parameters holds the configured values of the check, keyed by VerificationConfig JSON field
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// ActiveChecks returns the checks Verify applies under the config, in a stable order, without
+// needing a proof. The timestamp check always runs; the others run only when configured. Binding
+// checks (scope, root, user context, attestation ID) and disclosure consistency checks always run
+// and are not listed.
+func (c VerificationConfig) ActiveChecks() []CheckDescriptor {
+	var checks []CheckDescriptor
+	add := func(name CheckName, description string, parameters map[string]interface{}) {
+		checks = append(checks, CheckDescriptor{
+			Name:        name,
+			Description: description,
+			Advisory:    c.isAdvisory(name),
+			Parameters:  parameters,
+		})
+	}
+
+	if c.MinimumAge != 0 {
+		parameters := map[string]interface{}{"minimumAge": c.MinimumAge}
+		description := fmt.Sprintf("The holder is at least %d years old", c.MinimumAge)
+		if c.MinimumAgeMarginDays > 0 {
+			parameters["minimumAgeMarginDays"] = c.MinimumAgeMarginDays
+			description += fmt.Sprintf(", by at least %d days, from the disclosed date of birth", c.MinimumAgeMarginDays)
+		}
+		add(CheckMinimumAge, description, parameters)
+	}
+	if len(c.ExcludedCountries) > 0 {
+		add(CheckExcludedCountries, "The proof excludes holders of the listed nationalities",
+			map[string]interface{}{"excludedCountries": canonicalCountries(c.ExcludedCountries)})
+	}
+	if len(c.SanctionedNationalities) > 0 {
+		add(CheckSanctionedNationalities, "The proof excludes holders of the sanctioned nationalities",
+			map[string]interface{}{"sanctionedNationalities": canonicalCountries(c.SanctionedNationalities)})
+	}
+	if c.Ofac {
+		parameters := map[string]interface{}{"ofacMode": c.ofacMode()}
+		if c.TrustProofOfac {
+			parameters["trustProofOfac"] = true
+		}
+		add(CheckOfac, "The holder is not on the OFAC sanctions lists", parameters)
+	}
+	if len(c.AllowedIssuingStates) > 0 {
+		add(CheckAllowedIssuingStates, "The document was issued by one of the allowed states, from the disclosed issuing state",
+			map[string]interface{}{"allowedIssuingStates": canonicalCountries(c.AllowedIssuingStates)})
+	}
+	if len(c.AllowedDocumentSubtypes) > 0 {
+		add(CheckAllowedDocumentSubtypes, "Passports are of one of the allowed subtypes, from the disclosed document code",
+			map[string]interface{}{"allowedDocumentSubtypes": canonicalDocumentSubtypes(c.AllowedDocumentSubtypes)})
+	}
+	if c.RequireNationalityMatchesIssuer {
+		add(CheckNationalityMatchesIssuer, "The disclosed nationality matches the disclosed issuing state", nil)
+	}
+	if c.RejectIssuedAsMinor {
+		add(CheckIssuedAsMinor, "The document was not issued to a minor, when its date of issue and the date of birth are disclosed", nil)
+	}
+	if c.RequireBiometricCommitment {
+		add(CheckBiometricCommitment, "The proof commits to a biometric hash", nil)
+	}
+	add(CheckTimestamp, "The proof was generated recently and is not dated in the future", nil)
+	return checks
+}
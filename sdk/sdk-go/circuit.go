@@ -0,0 +1,137 @@
+package self
+
+import "fmt"
+
+// CircuitVersion identifies a revision of the disclose circuit's public signal layout
+type CircuitVersion int
+
+const (
+	// CircuitVersionUnknown is reported for public signals that match no known circuit layout
+	CircuitVersionUnknown CircuitVersion = 0
+	// CircuitVersionV1 is the first revision of a disclose circuit layout; no V1 layout is accepted by default
+	CircuitVersionV1 CircuitVersion = 1
+	// CircuitVersionV2 is the disclose circuit layout used by current Self clients
+	CircuitVersionV2 CircuitVersion = 2
+)
+
+// CircuitLayout describes the public signals produced by one version of a disclose circuit
+type CircuitLayout struct {
+	Version            CircuitVersion
+	PublicSignalsCount int
+	Indices            DiscloseIndicesEntry
+}
+
+// circuitLayouts holds the circuit layouts accepted and recognized per attestation ID
+type circuitLayouts struct {
+	supported map[AttestationId][]CircuitLayout
+	retired   map[AttestationId][]CircuitLayout
+}
+
+// defaultCircuitLayouts maps attestation IDs to the circuit layouts the verifier accepts, newest first.
+//
+// When a circuit changes its signal layout, the new layout is prepended here and the previous
+// one is kept until clients generating it are no longer supported, then moved to the retired
+// layouts, so proofs generated by it are rejected with their version instead of as unknown.
+// Layouts are told apart by their signal count, so each layout of an attestation ID needs its own.
+var defaultCircuitLayouts = circuitLayouts{
+	supported: map[AttestationId][]CircuitLayout{
+		Passport: {
+			{Version: CircuitVersionV2, PublicSignalsCount: 21, Indices: DiscloseIndices[Passport]},
+		},
+		EUCard: {
+			{Version: CircuitVersionV2, PublicSignalsCount: 21, Indices: DiscloseIndices[EUCard]},
+		},
+		Aadhaar: {
+			{Version: CircuitVersionV2, PublicSignalsCount: 19, Indices: DiscloseIndices[Aadhaar]},
+		},
+	},
+	retired: map[AttestationId][]CircuitLayout{},
+}
+
+// WithCircuitLayouts replaces the circuit layouts the verifier accepts for attestationId, newest first,
+// and the retired layouts it recognizes but rejects, e.g. to stop accepting a layout ahead of an SDK
+// release. Layouts of other attestation IDs keep their defaults.
+func WithCircuitLayouts(attestationId AttestationId, supported, retired []CircuitLayout) VerifierOption {
+	return func(s *BackendVerifier) {
+		s.circuitLayouts = s.circuitLayouts.with(attestationId, supported, retired)
+	}
+}
+
+// with returns a copy of the layouts with those of attestationId replaced
+func (c circuitLayouts) with(attestationId AttestationId, supported, retired []CircuitLayout) circuitLayouts {
+	updated := circuitLayouts{
+		supported: make(map[AttestationId][]CircuitLayout, len(c.supported)+1),
+		retired:   make(map[AttestationId][]CircuitLayout, len(c.retired)+1),
+	}
+	for id, layouts := range c.supported {
+		updated.supported[id] = layouts
+	}
+	for id, layouts := range c.retired {
+		updated.retired[id] = layouts
+	}
+	updated.supported[attestationId] = append([]CircuitLayout(nil), supported...)
+	updated.retired[attestationId] = append([]CircuitLayout(nil), retired...)
+	return updated
+}
+
+// SupportedCircuitVersions returns the circuit versions accepted by default for an attestation ID, newest first
+func SupportedCircuitVersions(attestationId AttestationId) []CircuitVersion {
+	return defaultCircuitLayouts.versions(attestationId)
+}
+
+// versions returns the circuit versions these layouts accept for an attestation ID, newest first
+func (c circuitLayouts) versions(attestationId AttestationId) []CircuitVersion {
+	layouts := c.supported[attestationId]
+	versions := make([]CircuitVersion, 0, len(layouts))
+	for _, layout := range layouts {
+		versions = append(versions, layout.Version)
+	}
+	return versions
+}
+
+// DetectCircuitVersion determines which of the default circuit layouts produced the given public signals.
+//
+// Signals matching a supported layout's count are read with that layout. Signals beyond the newest
+// layout's count are not covered by the proof, so longer arrays are read with the newest layout
+// and the trailing signals ignored.
+//
+// Returns:
+//   - The matching CircuitLayout
+//   - An *UnsupportedCircuitVersionError carrying the detected version if the signals match a
+//     retired layout, or CircuitVersionUnknown if they match none
+//   - An error if the attestation ID is unknown
+func DetectCircuitVersion(attestationId AttestationId, publicSignals []string) (CircuitLayout, error) {
+	return defaultCircuitLayouts.detect(attestationId, publicSignals)
+}
+
+// detect implements DetectCircuitVersion against these layouts
+func (c circuitLayouts) detect(attestationId AttestationId, publicSignals []string) (CircuitLayout, error) {
+	layouts, exists := c.supported[attestationId]
+	if !exists || len(layouts) == 0 {
+		return CircuitLayout{}, fmt.Errorf("invalid attestation ID: %d", attestationId)
+	}
+
+	for _, layout := range layouts {
+		if len(publicSignals) == layout.PublicSignalsCount {
+			return layout, nil
+		}
+	}
+
+	detected := CircuitVersionUnknown
+	for _, layout := range c.retired[attestationId] {
+		if len(publicSignals) == layout.PublicSignalsCount {
+			detected = layout.Version
+			break
+		}
+	}
+	if detected == CircuitVersionUnknown && len(publicSignals) > layouts[0].PublicSignalsCount {
+		return layouts[0], nil
+	}
+
+	return CircuitLayout{}, &UnsupportedCircuitVersionError{
+		AttestationId:      attestationId,
+		Version:            detected,
+		PublicSignalsCount: len(publicSignals),
+		Supported:          c.versions(attestationId),
+	}
+}
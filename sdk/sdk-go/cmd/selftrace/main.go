@@ -0,0 +1,77 @@
+// Command selftrace replays verification traces recorded with self.WithTraceSink against a verifier
+// configured from the SELF_* environment variables (see self.LoadVerifierEnvConfig), reporting the
+// scope, signal and user context mismatches behind each failure.
+//
+// Usage:
+//
+//	SELF_SCOPE=my-app SELF_ENDPOINT=https://my-app.com/api/verify selftrace traces.jsonl
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: selftrace <traces.jsonl | ->")
+		os.Exit(2)
+	}
+
+	input := io.Reader(os.Stdin)
+	if path := os.Args[1]; path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "selftrace: %v\n", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	verifier, err := self.NewBackendVerifierFromEnv(self.NewDefaultConfigStore(self.VerificationConfig{}))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "selftrace: %v\n", err)
+		os.Exit(1)
+	}
+
+	failed := false
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		var trace self.VerificationTrace
+		if err := json.Unmarshal(scanner.Bytes(), &trace); err != nil {
+			fmt.Printf("line %d: invalid trace: %v\n", line, err)
+			failed = true
+			continue
+		}
+
+		fmt.Printf("line %d: attestation %d at %s failed with %s\n", line, trace.AttestationId, trace.Timestamp.Format(time.RFC3339), trace.ErrorCode)
+		issues, err := verifier.ReplayTrace(trace)
+		if err != nil {
+			fmt.Printf("  replay error: %v\n", err)
+			failed = true
+			continue
+		}
+		if len(issues) == 0 {
+			fmt.Println("  no scope, signal or user context mismatch against this verifier")
+		}
+		for _, issue := range issues {
+			fmt.Printf("  [%s] %s\n", issue.Type, issue.Message)
+			failed = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "selftrace: %v\n", err)
+		os.Exit(1)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -2,9 +2,31 @@ package self
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/selfxyz/self/sdk/sdk-go/common"
 )
 
-// ConfigStore interface defines methods for storing and retrieving verification configurations
+// ConfigStore interface defines methods for storing and retrieving verification configurations.
+// BackendVerifier reads the config on every verification and never caches it, so a config
+// changed with SetConfig applies to the next call to Verify.
+//
+// Implementations must be safe for concurrent use, as Verify reads configs from many goroutines
+// while they are updated:
+//   - GetConfig returns a config exactly as stored by one SetConfig call (or the empty config),
+//     never a mix of concurrent writes, and the caller may modify it without affecting the store
+//   - once SetConfig returns, GetConfig on the same store returns that config or a later one;
+//     writes made by other processes may take a while to show
+//   - of concurrent SetConfig calls creating the same ID, exactly one reports it as created
+//
+// The storetest package checks an implementation against this contract.
 type ConfigStore interface {
 	// GetConfig retrieves a verification configuration by ID
 	GetConfig(ctx context.Context, id string) (VerificationConfig, error)
@@ -14,8 +36,10 @@ type ConfigStore interface {
 	GetActionId(ctx context.Context, userIdentifier string, actionId string) (string, error)
 }
 
-// DefaultConfigStore provides a simple in-memory implementation of ConfigStore
+// DefaultConfigStore provides a simple in-memory implementation of ConfigStore, serving the same
+// config for every ID
 type DefaultConfigStore struct {
+	mu     sync.RWMutex
 	config VerificationConfig
 }
 
@@ -28,12 +52,16 @@ func NewDefaultConfigStore(config VerificationConfig) *DefaultConfigStore {
 
 // GetConfig returns the stored configuration
 func (store *DefaultConfigStore) GetConfig(ctx context.Context, id string) (VerificationConfig, error) {
-	return store.config, nil
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	return cloneVerificationConfig(store.config), nil
 }
 
 // SetConfig updates the stored configuration
 	func (store *DefaultConfigStore) SetConfig(ctx context.Context, id string, config VerificationConfig) (bool, error) {
-	store.config = config
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.config = cloneVerificationConfig(config)
 	return true, nil
 }
 
@@ -41,3 +69,230 @@ func (store *DefaultConfigStore) GetConfig(ctx context.Context, id string) (Veri
 func (store *DefaultConfigStore) GetActionId(ctx context.Context, userIdentifier string, userDefinedData string) (string, error) {
 	return "random-id", nil
 }
+
+// ActionIdRequest carries everything known about a verification when resolving its config ID
+type ActionIdRequest struct {
+	UserIdentifier  string
+	UserDefinedData string
+	// Scope is the verifier's application scope, as passed to NewBackendVerifier
+	Scope         string
+	AttestationId AttestationId
+}
+
+// ActionIdResolver is an optional ConfigStore extension for stores that route on more than the
+// user identifier, e.g. multi-tenant stores keyed by scope or attestation type.
+// When a ConfigStore implements it, ResolveActionId is used instead of GetActionId.
+type ActionIdResolver interface {
+	ResolveActionId(ctx context.Context, request ActionIdRequest) (string, error)
+}
+
+// resolveActionId resolves the config ID through ResolveActionId when the store supports it, else GetActionId
+func resolveActionId(ctx context.Context, store ConfigStore, request ActionIdRequest) (string, error) {
+	if resolver, ok := store.(ActionIdResolver); ok {
+		return resolver.ResolveActionId(ctx, request)
+	}
+	return store.GetActionId(ctx, request.UserIdentifier, request.UserDefinedData)
+}
+
+// CandidateActionIdResolver is an optional ConfigStore extension for stores that resolve a
+// verification to several candidate config IDs, e.g. to run policy experiments. Verify accepts
+// a proof satisfying any candidate, trying them in order, and reports the matching ID in
+// VerificationResult.ConfigId. When a ConfigStore implements it, ResolveCandidateActionIds is
+// used instead of ResolveActionId and GetActionId.
+type CandidateActionIdResolver interface {
+	ResolveCandidateActionIds(ctx context.Context, request ActionIdRequest) ([]string, error)
+}
+
+// ConfigPrefetcher is an optional ConfigStore extension for caching stores that can load configs
+// ahead of use, so an instance serves its first verifications from a warm cache. PrefetchConfigs
+// returns an error matching ErrConfigNotFound naming every ID that could not be loaded.
+type ConfigPrefetcher interface {
+	PrefetchConfigs(ctx context.Context, ids ...string) error
+}
+
+// prefetchConfigs loads ids through PrefetchConfigs when the store supports it, else with loadConfigs
+func prefetchConfigs(ctx context.Context, store ConfigStore, ids []string) error {
+	if prefetcher, ok := store.(ConfigPrefetcher); ok {
+		return prefetcher.PrefetchConfigs(ctx, ids...)
+	}
+	return loadConfigs(ctx, store, ids)
+}
+
+// loadConfigs reads each of ids and its parents from the store, failing for those it does not find
+func loadConfigs(ctx context.Context, store ConfigStore, ids []string) error {
+	var errs []error
+	for _, id := range ids {
+		config, err := ResolveConfig(ctx, store, id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w: %s: %w", ErrConfigNotFound, id, err))
+		} else if isEmptyVerificationConfig(config) {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrConfigNotFound, id))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// resolveActionIds resolves the candidate config IDs through ResolveCandidateActionIds when the store
+// supports it, else the single ID from resolveActionId. Empty IDs are dropped.
+func resolveActionIds(ctx context.Context, store ConfigStore, request ActionIdRequest) ([]string, error) {
+	var configIds []string
+	if resolver, ok := store.(CandidateActionIdResolver); ok {
+		candidates, err := resolver.ResolveCandidateActionIds(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		configIds = candidates
+	} else {
+		configId, err := resolveActionId(ctx, store, request)
+		if err != nil {
+			return nil, err
+		}
+		configIds = []string{configId}
+	}
+
+	candidates := make([]string, 0, len(configIds))
+	for _, configId := range configIds {
+		if configId != "" {
+			candidates = append(candidates, configId)
+		}
+	}
+	return candidates, nil
+}
+
+// callConfigStore runs a ConfigStore call, bounding it by the verifier's config store timeout.
+// A call that does not return in time fails with ErrConfigStoreTimeout; it keeps running in the
+// background and its result is discarded, so stores ignoring ctx cannot block Verify.
+func callConfigStore[T any](s *BackendVerifier, ctx context.Context, call func(ctx context.Context) (T, error)) (T, error) {
+	if s.configStoreTimeout <= 0 {
+		return call(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.configStoreTimeout)
+	defer cancel()
+
+	type outcome struct {
+		value T
+		err   error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		value, err := call(ctx)
+		done <- outcome{value, err}
+	}()
+	select {
+	case result := <-done:
+		return result.value, result.err
+	case <-ctx.Done():
+		var zero T
+		return zero, fmt.Errorf("%w after %s", ErrConfigStoreTimeout, s.configStoreTimeout)
+	}
+}
+
+// configStoreTimeoutFallback returns the fallback config when one is allowed, else err to fail closed
+func (s *BackendVerifier) configStoreTimeoutFallback(err error, userContextData string) (VerificationConfig, error) {
+	if s.configStoreFallback == nil {
+		return VerificationConfig{}, err
+	}
+	s.logger.Printf("self: %v, verifying userContextData %s against the fallback config",
+		err, RedactUserContextData(userContextData))
+	return *s.configStoreFallback, nil
+}
+
+// Hash returns a stable hex-encoded SHA-256 hash of the config, suitable for cache keys and audit.
+// Configs that verify identically hash identically: country and check lists are compared as sets,
+// nil and empty lists are equivalent, and OfacMode and TrustProofOfac are ignored unless Ofac is set.
+func (c VerificationConfig) Hash() string {
+	canonical := VerificationConfig{
+		MinimumAge:                      c.MinimumAge,
+		ExcludedCountries:               canonicalCountries(c.ExcludedCountries),
+		Ofac:                            c.Ofac,
+		AdvisoryChecks:                  canonicalChecks(c.AdvisoryChecks),
+		SanctionedNationalities:         canonicalCountries(c.SanctionedNationalities),
+		Disclosures:                     c.Disclosures,
+		AllowedIssuingStates:            canonicalCountries(c.AllowedIssuingStates),
+		RequireBiometricCommitment:      c.RequireBiometricCommitment,
+		RequireNationalityMatchesIssuer: c.RequireNationalityMatchesIssuer,
+		MinimumAgeMarginDays:            c.MinimumAgeMarginDays,
+		AllowedDocumentSubtypes:         canonicalDocumentSubtypes(c.AllowedDocumentSubtypes),
+		RejectIssuedAsMinor:             c.RejectIssuedAsMinor,
+		Parent:                          c.Parent,
+	}
+	if c.ExpiryDisclosure != ExpiryDisclosureDate {
+		canonical.ExpiryDisclosure = c.ExpiryDisclosure
+	}
+	if c.Ofac {
+		canonical.OfacMode = c.ofacMode()
+		canonical.TrustProofOfac = c.TrustProofOfac
+	}
+
+	// Marshalling a struct writes its fields in declaration order, so the encoding is deterministic
+	encoded, _ := json.Marshal(canonical)
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:])
+}
+
+// canonicalCountries returns the sorted, deduplicated country codes, or nil if there are none
+func canonicalCountries(countries []common.Country3LetterCode) []common.Country3LetterCode {
+	seen := make(map[common.Country3LetterCode]bool, len(countries))
+	var canonical []common.Country3LetterCode
+	for _, country := range countries {
+		if !seen[country] {
+			seen[country] = true
+			canonical = append(canonical, country)
+		}
+	}
+	sort.Slice(canonical, func(i, j int) bool { return canonical[i] < canonical[j] })
+	return canonical
+}
+
+// canonicalChecks returns the sorted, deduplicated check names, or nil if there are none
+func canonicalChecks(checks []CheckName) []CheckName {
+	seen := make(map[CheckName]bool, len(checks))
+	var canonical []CheckName
+	for _, check := range checks {
+		if !seen[check] {
+			seen[check] = true
+			canonical = append(canonical, check)
+		}
+	}
+	sort.Slice(canonical, func(i, j int) bool { return canonical[i] < canonical[j] })
+	return canonical
+}
+
+// resolvedConfigKey is the context key under which ContextWithResolvedConfig stores the config
+type resolvedConfigKey struct{}
+
+// ResolvedConfig is a verification config together with the ID it was resolved from
+type ResolvedConfig struct {
+	ConfigId string
+	Config   VerificationConfig
+}
+
+// ContextWithResolvedConfig returns a copy of ctx carrying the config result was verified against,
+// so downstream handlers use the same config as the verification rather than reading the store again
+func ContextWithResolvedConfig(ctx context.Context, result *VerificationResult) context.Context {
+	return context.WithValue(ctx, resolvedConfigKey{}, ResolvedConfig{
+		ConfigId: result.ConfigId,
+		Config:   result.Config,
+	})
+}
+
+// ResolvedConfigFromContext returns the config stored by ContextWithResolvedConfig, if any
+func ResolvedConfigFromContext(ctx context.Context) (ResolvedConfig, bool) {
+	resolved, ok := ctx.Value(resolvedConfigKey{}).(ResolvedConfig)
+	return resolved, ok
+}
+
+// cloneVerificationConfig returns a copy of config sharing no slices or pointers with it, so
+// stores can hand out configs that callers may modify
+func cloneVerificationConfig(config VerificationConfig) VerificationConfig {
+	config.ExcludedCountries = slices.Clone(config.ExcludedCountries)
+	config.AdvisoryChecks = slices.Clone(config.AdvisoryChecks)
+	config.SanctionedNationalities = slices.Clone(config.SanctionedNationalities)
+	config.AllowedIssuingStates = slices.Clone(config.AllowedIssuingStates)
+	config.AllowedDocumentSubtypes = slices.Clone(config.AllowedDocumentSubtypes)
+	if config.Disclosures != nil {
+		disclosures := *config.Disclosures
+		config.Disclosures = &disclosures
+	}
+	return config
+}
@@ -0,0 +1,73 @@
+package self
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInconsistentDisclosure is reported when a proof discloses values that contradict each other
+var ErrInconsistentDisclosure = errors.New("proof discloses conflicting data")
+
+// InconsistentDisclosureError names the disclosed fields that contradict each other. It is the
+// error of the InconsistentDisclosure issue and matches ErrInconsistentDisclosure.
+type InconsistentDisclosureError struct {
+	Fields []string // GenericDiscloseOutput JSON field names, e.g. "dateOfBirth" and "minimumAge"
+	Reason string
+}
+
+func (e *InconsistentDisclosureError) Error() string {
+	return fmt.Sprintf("%v: %s (%s)", ErrInconsistentDisclosure, e.Reason, strings.Join(e.Fields, ", "))
+}
+
+// Is reports whether target is ErrInconsistentDisclosure
+func (e *InconsistentDisclosureError) Is(target error) bool {
+	return target == ErrInconsistentDisclosure
+}
+
+// validateDisclosureConsistency checks that the disclosed dates agree with each other and with the
+// proven minimum age, on the proof's generation date. Unlike policy checks these always run and are
+// never advisory: a valid proof cannot contradict itself.
+//
+// Nationality and issuing state are not compared, as they legitimately differ for refugee and
+// stateless travel documents; RequireNationalityMatchesIssuer enforces that as a policy.
+func validateDisclosureConsistency(
+	attestationId AttestationId,
+	output GenericDiscloseOutput,
+	publicSignals []string,
+	discloseIndices DiscloseIndicesEntry,
+	issues *[]ConfigIssue,
+) {
+	if !isFieldDisclosedIn(attestationId, output, DisclosureDateOfBirth) {
+		return
+	}
+	referenceDate, ok := circuitDate(attestationId, publicSignals, discloseIndices)
+	if !ok {
+		referenceDate = time.Now().UTC()
+	}
+	dateOfBirth, ok := parseDateOfBirth(attestationId, removeNullBytes(output.DateOfBirth), referenceDate)
+	if !ok {
+		return
+	}
+
+	report := func(reason string, fields ...string) {
+		err := &InconsistentDisclosureError{Fields: fields, Reason: reason}
+		*issues = append(*issues, ConfigIssue{Type: InconsistentDisclosure, Message: err.Error(), Err: err})
+	}
+
+	// The circuit proves the holder is at least the minimum age; "00" proves nothing
+	if provenAge, err := strconv.Atoi(removeNullBytes(output.MinimumAge)); err == nil && provenAge > 0 {
+		if age := ageAt(dateOfBirth, referenceDate); age < provenAge {
+			report(fmt.Sprintf("date of birth makes the holder %d on %s, the proof claims at least %d",
+				age, referenceDate.Format(isoDateLayout), provenAge), "dateOfBirth", "minimumAge")
+		}
+	}
+
+	if isFieldDisclosedIn(attestationId, output, DisclosureExpiryDate) {
+		if expiry, ok := parseExpiryDate(removeNullBytes(output.ExpiryDate)); ok && !expiry.After(dateOfBirth) {
+			report("document expires before the holder was born", "dateOfBirth", "expiryDate")
+		}
+	}
+}
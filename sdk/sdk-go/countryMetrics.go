@@ -0,0 +1,168 @@
+package self
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/selfxyz/self/sdk/sdk-go/common"
+)
+
+// Country labels of CountryMetrics buckets that are not a country
+const (
+	// CountryLabelOther aggregates every country outside the top N
+	CountryLabelOther = "other"
+	// CountryLabelUndisclosed counts verifications that did not disclose the country
+	CountryLabelUndisclosed = "undisclosed"
+)
+
+// DefaultCountryMetricsTopN is the number of countries CountryMetrics reports when none is given
+const DefaultCountryMetricsTopN = 20
+
+// CountryStats counts the outcomes of verifications for one country label
+type CountryStats struct {
+	Country string `json:"country"`
+	Total   int64  `json:"total"`
+	Valid   int64  `json:"valid"`
+	Invalid int64  `json:"invalid"`
+	Errors  int64  `json:"errors"`
+}
+
+// SuccessRate returns the share of verifications that were valid, 0 when there were none
+func (s CountryStats) SuccessRate() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Valid) / float64(s.Total)
+}
+
+// CountryMetricsSnapshot holds the per-country counts of a CountryMetrics, each list ordered by
+// Total, most frequent first, and ending with the CountryLabelOther bucket when it is not empty
+type CountryMetricsSnapshot struct {
+	IssuingStates []CountryStats `json:"issuingStates"`
+	Nationalities []CountryStats `json:"nationalities"`
+}
+
+// CountryMetrics is an OutcomeRecorder counting outcomes per issuing state and nationality, e.g. to
+// export success rates for fraud monitoring. To bound the cardinality of exported labels, snapshots
+// report the top N countries and aggregate the rest under CountryLabelOther. Values that are not
+// known country codes are counted as other too, so memory stays bounded whatever proofs disclose.
+//
+// Export a snapshot as gauges when your metrics backend scrapes. Use a MultiOutcomeRecorder to record
+// outcomes to other recorders as well.
+type CountryMetrics struct {
+	topN int
+
+	mu            sync.Mutex
+	issuingStates map[string]*CountryStats
+	nationalities map[string]*CountryStats
+}
+
+// Compile-time check to ensure CountryMetrics implements OutcomeRecorder interface
+var _ OutcomeRecorder = (*CountryMetrics)(nil)
+
+// NewCountryMetrics creates a CountryMetrics whose snapshots report the topN most frequent countries
+// (DefaultCountryMetricsTopN when topN is not positive)
+func NewCountryMetrics(topN int) *CountryMetrics {
+	if topN <= 0 {
+		topN = DefaultCountryMetricsTopN
+	}
+	return &CountryMetrics{
+		topN:          topN,
+		issuingStates: make(map[string]*CountryStats),
+		nationalities: make(map[string]*CountryStats),
+	}
+}
+
+// RecordOutcome counts outcome under its issuing state and nationality
+func (m *CountryMetrics) RecordOutcome(ctx context.Context, outcome VerificationOutcome) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	countOutcome(m.issuingStates, countryLabel(outcome.Country), outcome.Outcome)
+	countOutcome(m.nationalities, countryLabel(outcome.Nationality), outcome.Outcome)
+	return nil
+}
+
+// Snapshot returns the current counts
+func (m *CountryMetrics) Snapshot() CountryMetricsSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return CountryMetricsSnapshot{
+		IssuingStates: topCountries(m.issuingStates, m.topN),
+		Nationalities: topCountries(m.nationalities, m.topN),
+	}
+}
+
+// Reset clears the counts, e.g. after exporting a snapshot as deltas
+func (m *CountryMetrics) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.issuingStates = make(map[string]*CountryStats)
+	m.nationalities = make(map[string]*CountryStats)
+}
+
+// countryLabel returns the label a disclosed country is counted under
+func countryLabel(country string) string {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	switch {
+	case country == "":
+		return CountryLabelUndisclosed
+	case common.Country3LetterCode(country).IsKnown():
+		return country
+	default:
+		return CountryLabelOther
+	}
+}
+
+// countOutcome adds outcome to the stats of label
+func countOutcome(stats map[string]*CountryStats, label string, outcome Outcome) {
+	s, ok := stats[label]
+	if !ok {
+		s = &CountryStats{Country: label}
+		stats[label] = s
+	}
+	s.Total++
+	switch outcome {
+	case OutcomeValid:
+		s.Valid++
+	case OutcomeInvalid:
+		s.Invalid++
+	case OutcomeError:
+		s.Errors++
+	}
+}
+
+// topCountries returns the topN labels with the highest totals, followed by the rest summed under
+// CountryLabelOther
+func topCountries(stats map[string]*CountryStats, topN int) []CountryStats {
+	ranked := make([]CountryStats, 0, len(stats))
+	other := CountryStats{Country: CountryLabelOther}
+	for label, s := range stats {
+		if label == CountryLabelOther {
+			other = *s
+			continue
+		}
+		ranked = append(ranked, *s)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Total != ranked[j].Total {
+			return ranked[i].Total > ranked[j].Total
+		}
+		return ranked[i].Country < ranked[j].Country
+	})
+
+	if len(ranked) > topN {
+		for _, s := range ranked[topN:] {
+			other.Total += s.Total
+			other.Valid += s.Valid
+			other.Invalid += s.Invalid
+			other.Errors += s.Errors
+		}
+		ranked = ranked[:topN]
+	}
+	if other.Total > 0 {
+		ranked = append(ranked, other)
+	}
+	return ranked
+}
@@ -0,0 +1,56 @@
+package self
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// Identifiers used in verifiable credentials built by ToVerifiableCredential
+const (
+	CredentialsContextV1     = "https://www.w3.org/2018/credentials/v1"
+	SelfIdentityCredential   = "SelfIdentityCredential"
+	VerifiableCredentialType = "VerifiableCredential"
+)
+
+// VerifiableCredential is a minimal, unsigned W3C Verifiable Credential (data model 1.1) document
+type VerifiableCredential struct {
+	Context           []string               `json:"@context"`
+	Type              []string               `json:"type"`
+	Issuer            string                 `json:"issuer"`
+	IssuanceDate      string                 `json:"issuanceDate"`
+	CredentialSubject map[string]interface{} `json:"credentialSubject"`
+}
+
+// ToVerifiableCredential maps the disclosed subject of a valid result into a W3C Verifiable Credential.
+//
+// The credentialSubject contains only the disclosed fields the result reports in DiscloseOutput, using
+// the GenericDiscloseOutput JSON keys, plus minimumAge and documentValid when present. The document is not
+// signed; wrap it in your own proof or envelope before handing it to third parties.
+func (r *VerificationResult) ToVerifiableCredential(issuer string) (*VerifiableCredential, error) {
+	if issuer == "" {
+		return nil, errors.New("issuer must not be empty")
+	}
+	if !r.IsValidDetails.IsValid {
+		return nil, ErrInvalidProof
+	}
+
+	subject := make(map[string]interface{})
+	for _, field := range r.reportedFieldSet().Fields() {
+		subject[disclosureFieldKeys[field]] = r.DiscloseOutput.fieldValue(field)
+	}
+	if minimumAge, err := strconv.Atoi(r.DiscloseOutput.MinimumAge); err == nil && minimumAge > 0 {
+		subject["minimumAge"] = minimumAge
+	}
+	if r.DiscloseOutput.DocumentValid != nil {
+		subject["documentValid"] = *r.DiscloseOutput.DocumentValid
+	}
+
+	return &VerifiableCredential{
+		Context:           []string{CredentialsContextV1},
+		Type:              []string{VerifiableCredentialType, SelfIdentityCredential},
+		Issuer:            issuer,
+		IssuanceDate:      time.Now().UTC().Format(time.RFC3339),
+		CredentialSubject: subject,
+	}, nil
+}
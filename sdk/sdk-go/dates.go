@@ -0,0 +1,101 @@
+package self
+
+import (
+	"strconv"
+	"time"
+)
+
+// parseDateOfBirth parses a disclosed date of birth: YYYYMMDD for Aadhaar, MRZ YYMMDD otherwise.
+// Two-digit years are resolved to the most recent date that is not after now.
+func parseDateOfBirth(attestationId AttestationId, value string, now time.Time) (time.Time, bool) {
+	if attestationId == Aadhaar {
+		return parseDigitsDate(value, 8, now)
+	}
+	return parseDigitsDate(value, 6, now)
+}
+
+// parseDigitsDate parses YYYYMMDD (length 8) or YYMMDD (length 6) dates.
+// Two-digit years are resolved to the most recent date that is not after now.
+func parseDigitsDate(value string, length int, now time.Time) (time.Time, bool) {
+	if len(value) != length {
+		return time.Time{}, false
+	}
+	digits, err := strconv.Atoi(value)
+	if err != nil || digits < 0 {
+		return time.Time{}, false
+	}
+
+	var year int
+	if length == 8 {
+		year = digits / 10000
+	} else {
+		year = inferPastCentury(digits/10000, now)
+	}
+	month := (digits / 100) % 100
+	day := digits % 100
+
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if length == 6 && date.After(now) {
+		// Later this year, so the two-digit year belongs to the previous century
+		year -= 100
+		date = time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	}
+	// Reject out-of-range components, which time.Date would silently normalise
+	if date.Year() != year || int(date.Month()) != month || date.Day() != day {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// inferPastCentury converts a two-digit year to the most recent four-digit year not after now
+func inferPastCentury(yy int, now time.Time) int {
+	year := now.Year() - now.Year()%100 + yy
+	if year > now.Year() {
+		year -= 100
+	}
+	return year
+}
+
+// ageAt returns the age in whole years of someone born on dateOfBirth, at the given date
+func ageAt(dateOfBirth time.Time, at time.Time) int {
+	age := at.Year() - dateOfBirth.Year()
+	if at.Month() < dateOfBirth.Month() || (at.Month() == dateOfBirth.Month() && at.Day() < dateOfBirth.Day()) {
+		age--
+	}
+	return age
+}
+
+// isoDateLayout is the ISO 8601 calendar date format used for normalized dates
+const isoDateLayout = "2006-01-02"
+
+// FormatDateOfBirthISO converts a disclosed date of birth to ISO 8601 (YYYY-MM-DD), or returns ""
+// if it is not a valid date. MRZ two-digit years resolve to the most recent date not after now,
+// so on 2026-10-15 "261015" is 2026-10-15 and "261016" is 1926-10-16.
+func FormatDateOfBirthISO(attestationId AttestationId, value string, now time.Time) string {
+	date, ok := parseDateOfBirth(attestationId, removeNullBytes(value), now)
+	if !ok {
+		return ""
+	}
+	return date.Format(isoDateLayout)
+}
+
+// FormatExpiryDateISO converts a disclosed MRZ expiry date to ISO 8601 (YYYY-MM-DD), or returns ""
+// if it is not a valid date. Expiry years always resolve to the 21st century.
+func FormatExpiryDateISO(value string) string {
+	date, ok := parseExpiryDate(removeNullBytes(value))
+	if !ok {
+		return ""
+	}
+	return date.Format(isoDateLayout)
+}
+
+// applyISODates sets the ISO 8601 variants of the disclosed dates that are still present on output
+func applyISODates(attestationId AttestationId, output GenericDiscloseOutput, now time.Time) GenericDiscloseOutput {
+	if isFieldDisclosedIn(attestationId, output, DisclosureDateOfBirth) {
+		output.DateOfBirthISO = FormatDateOfBirthISO(attestationId, output.DateOfBirth, now)
+	}
+	if isFieldDisclosedIn(attestationId, output, DisclosureExpiryDate) {
+		output.ExpiryDateISO = FormatExpiryDateISO(output.ExpiryDate)
+	}
+	return output
+}
@@ -0,0 +1,323 @@
+package self
+
+import (
+	"maps"
+	"strings"
+)
+
+// DisclosureField identifies an attribute a proof can selectively disclose
+type DisclosureField string
+
+const (
+	DisclosureIssuingState   DisclosureField = "issuing_state"
+	DisclosureName           DisclosureField = "name"
+	DisclosureDocumentNumber DisclosureField = "document_number"
+	DisclosureNationality    DisclosureField = "nationality"
+	DisclosureDateOfBirth    DisclosureField = "date_of_birth"
+	DisclosureGender         DisclosureField = "gender"
+	DisclosureExpiryDate     DisclosureField = "expiry_date"
+	// DisclosurePersonalNumber is the personal number of passports and ID cards, distinct from the document number
+	DisclosurePersonalNumber DisclosureField = "personal_number"
+)
+
+// AllDisclosureFields lists every DisclosureField in document order
+var AllDisclosureFields = []DisclosureField{
+	DisclosureIssuingState,
+	DisclosureName,
+	DisclosureDocumentNumber,
+	DisclosureNationality,
+	DisclosureDateOfBirth,
+	DisclosureGender,
+	DisclosureExpiryDate,
+	DisclosurePersonalNumber,
+}
+
+// disclosureFieldKeys maps each DisclosureField to its GenericDiscloseOutput JSON key
+var disclosureFieldKeys = map[DisclosureField]string{
+	DisclosureIssuingState:   "issuingState",
+	DisclosureName:           "name",
+	DisclosureDocumentNumber: "idNumber",
+	DisclosureNationality:    "nationality",
+	DisclosureDateOfBirth:    "dateOfBirth",
+	DisclosureGender:         "gender",
+	DisclosureExpiryDate:     "expiryDate",
+	DisclosurePersonalNumber: "personalNumber",
+}
+
+// DisclosureSet is a set of DisclosureFields, the shared vocabulary of requested, disclosed and
+// masked attributes
+type DisclosureSet map[DisclosureField]bool
+
+// NewDisclosureSet returns the set of the given fields
+func NewDisclosureSet(fields ...DisclosureField) DisclosureSet {
+	set := make(DisclosureSet, len(fields))
+	for _, field := range fields {
+		set[field] = true
+	}
+	return set
+}
+
+// Has reports whether field is in the set
+func (s DisclosureSet) Has(field DisclosureField) bool {
+	return s[field]
+}
+
+// Fields returns the fields of the set in document order
+func (s DisclosureSet) Fields() []DisclosureField {
+	var fields []DisclosureField
+	for _, field := range AllDisclosureFields {
+		if s[field] {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// Difference returns the fields of s that are not in other
+func (s DisclosureSet) Difference(other DisclosureSet) DisclosureSet {
+	difference := make(DisclosureSet)
+	for field := range s {
+		if s[field] && !other[field] {
+			difference[field] = true
+		}
+	}
+	return difference
+}
+
+// DisclosureConfig converts the set to the equivalent SelfAppDisclosureConfig
+func (s DisclosureSet) DisclosureConfig() SelfAppDisclosureConfig {
+	return SelfAppDisclosureConfig{
+		IssuingState:   s[DisclosureIssuingState],
+		Name:           s[DisclosureName],
+		PassportNumber: s[DisclosureDocumentNumber],
+		Nationality:    s[DisclosureNationality],
+		DateOfBirth:    s[DisclosureDateOfBirth],
+		Gender:         s[DisclosureGender],
+		ExpiryDate:     s[DisclosureExpiryDate],
+		PersonalNumber: s[DisclosurePersonalNumber],
+	}
+}
+
+// fieldValue returns the disclosed value of field with null bytes removed
+func (o GenericDiscloseOutput) fieldValue(field DisclosureField) string {
+	var value string
+	switch field {
+	case DisclosureIssuingState:
+		value = o.IssuingState
+	case DisclosureName:
+		value = o.Name
+	case DisclosureDocumentNumber:
+		value = o.IdNumber
+	case DisclosureNationality:
+		value = o.Nationality
+	case DisclosureDateOfBirth:
+		value = o.DateOfBirth
+	case DisclosureGender:
+		value = o.Gender
+	case DisclosureExpiryDate:
+		value = o.ExpiryDate
+	case DisclosurePersonalNumber:
+		value = o.PersonalNumber
+	}
+	return removeNullBytes(value)
+}
+
+// DisclosedFieldSet returns the fields the proof actually disclosed, including those the config's
+// policy masked from DiscloseOutput, e.g. to find a client disclosing more than the policy requests.
+//
+// Fields the user chose not to reveal are zero-filled in the proof and are absent from the set.
+// Values implied by the document type rather than revealed (the Aadhaar nationality and
+// expiry date) are not reported as disclosed. For results not returned by Verify, such as those
+// built by custom attestation verifiers or decoded from JSON, the set is read from DiscloseOutput.
+func (r *VerificationResult) DisclosedFieldSet() DisclosureSet {
+	if r.disclosedFields != nil {
+		return maps.Clone(r.disclosedFields)
+	}
+	return r.reportedFieldSet()
+}
+
+// reportedFieldSet returns the fields DiscloseOutput holds a value for once masking is applied
+func (r *VerificationResult) reportedFieldSet() DisclosureSet {
+	return disclosedFieldSet(r.AttestationId, r.DiscloseOutput)
+}
+
+// disclosedFieldSet returns the fields output, disclosed by a proof of attestationId, holds a value for
+func disclosedFieldSet(attestationId AttestationId, output GenericDiscloseOutput) DisclosureSet {
+	disclosed := make(DisclosureSet)
+	for _, field := range AllDisclosureFields {
+		if isFieldDisclosedIn(attestationId, output, field) {
+			disclosed[field] = true
+		}
+	}
+	return disclosed
+}
+
+// isFieldDisclosedIn reports whether output, disclosed by a proof of attestationId, holds a value for field
+func isFieldDisclosedIn(attestationId AttestationId, output GenericDiscloseOutput, field DisclosureField) bool {
+	switch field {
+	case DisclosureIssuingState:
+		return isDisclosedValue(output.IssuingState)
+	case DisclosureName:
+		return isDisclosedValue(output.Name)
+	case DisclosureDocumentNumber:
+		return isDisclosedValue(output.IdNumber)
+	case DisclosureNationality:
+		return attestationId != Aadhaar && isDisclosedValue(output.Nationality)
+	case DisclosureDateOfBirth:
+		if attestationId == Aadhaar {
+			// Aadhaar dates are rendered digit by digit, so an undisclosed date reads as zeros
+			return strings.Trim(output.DateOfBirth, "0") != ""
+		}
+		return isDisclosedValue(output.DateOfBirth)
+	case DisclosureGender:
+		return isDisclosedValue(output.Gender)
+	case DisclosureExpiryDate:
+		return attestationId != Aadhaar && isDisclosedValue(output.ExpiryDate)
+	case DisclosurePersonalNumber:
+		return isDisclosedValue(output.PersonalNumber)
+	default:
+		return false
+	}
+}
+
+// isDisclosedValue reports whether a revealed field holds anything besides null bytes
+func isDisclosedValue(value string) bool {
+	return strings.Trim(value, "\x00") != ""
+}
+
+// SelfAppDisclosureConfig lists the attributes a policy asks the user to disclose, mirroring the
+// disclosure toggles of the Self app request
+type SelfAppDisclosureConfig struct {
+	IssuingState   bool `json:"issuing_state,omitempty"`
+	Name           bool `json:"name,omitempty"`
+	PassportNumber bool `json:"passport_number,omitempty"` // The document number, IdNumber
+	Nationality    bool `json:"nationality,omitempty"`
+	DateOfBirth    bool `json:"date_of_birth,omitempty"`
+	Gender         bool `json:"gender,omitempty"`
+	ExpiryDate     bool `json:"expiry_date,omitempty"`
+	PersonalNumber bool `json:"personal_number,omitempty"`
+}
+
+// Set returns the fields the disclosure config asks for
+func (d SelfAppDisclosureConfig) Set() DisclosureSet {
+	return DisclosureSet{
+		DisclosureIssuingState:   d.IssuingState,
+		DisclosureName:           d.Name,
+		DisclosureDocumentNumber: d.PassportNumber,
+		DisclosureNationality:    d.Nationality,
+		DisclosureDateOfBirth:    d.DateOfBirth,
+		DisclosureGender:         d.Gender,
+		DisclosureExpiryDate:     d.ExpiryDate,
+		DisclosurePersonalNumber: d.PersonalNumber,
+	}
+}
+
+// DisclosureMode selects how a proof disclosing fields the config's Disclosures did not request is handled
+type DisclosureMode string
+
+const (
+	// DisclosureModeLenient accepts the proof and masks the unrequested fields (the default)
+	DisclosureModeLenient DisclosureMode = "lenient"
+	// DisclosureModeStrict rejects the proof with ErrOverDisclosure
+	DisclosureModeStrict DisclosureMode = "strict"
+)
+
+// unrequestedDisclosures returns the fields output discloses that the config did not request,
+// or nil when the config does not restrict disclosures
+func (c VerificationConfig) unrequestedDisclosures(attestationId AttestationId, output GenericDiscloseOutput) []DisclosureField {
+	if c.Disclosures == nil {
+		return nil
+	}
+	disclosed := make(DisclosureSet)
+	for _, field := range AllDisclosureFields {
+		if isFieldDisclosedIn(attestationId, output, field) {
+			disclosed[field] = true
+		}
+	}
+	return disclosed.Difference(c.Disclosures.Set()).Fields()
+}
+
+// MaskDisclosures returns output with every attribute the config's Disclosures does not request
+// blanked out, whether or not the proof disclosed it, so undisclosed filler and values implied by
+// the document type are cleared too. With no requested attributes only predicate results (minimum
+// age, OFAC, forbidden countries) and the nullifier remain. Output is returned unchanged when
+// Disclosures is nil.
+//
+// Verify applies it to every result; custom AttestationVerifiers building their own results can use it too.
+func (c VerificationConfig) MaskDisclosures(output GenericDiscloseOutput) GenericDiscloseOutput {
+	if c.Disclosures == nil {
+		return output
+	}
+	unrequested := NewDisclosureSet(AllDisclosureFields...).Difference(c.Disclosures.Set())
+	return maskDisclosureFields(output, unrequested.Fields())
+}
+
+// DisclosureStatus tells why a field of a verification result holds a value or not
+type DisclosureStatus string
+
+const (
+	// DisclosureStatusDisclosed is a field the proof disclosed and the result reports
+	DisclosureStatusDisclosed DisclosureStatus = "disclosed"
+	// DisclosureStatusNotProvided is a field the policy allows that the proof did not disclose
+	DisclosureStatusNotProvided DisclosureStatus = "not_provided"
+	// DisclosureStatusHiddenByPolicy is a field the config's Disclosures do not request, masked
+	// whether or not the proof disclosed it, or an expiry date replaced by ExpiryDisclosureValidity
+	DisclosureStatusHiddenByPolicy DisclosureStatus = "hidden_by_policy"
+	// DisclosureStatusWithheld is a disclosed field blanked out because the context was not marked
+	// secure; see WithRequireSecureContextForDisclosure
+	DisclosureStatusWithheld DisclosureStatus = "withheld"
+)
+
+// DisclosureStatuses returns the status of every DisclosureField of output, disclosed by a proof of
+// attestationId, once the config's masking is applied. Output must be the disclosure before masking.
+//
+// Verify reports it on every result; custom AttestationVerifiers building their own results can use it too.
+func (c VerificationConfig) DisclosureStatuses(attestationId AttestationId, output GenericDiscloseOutput) map[DisclosureField]DisclosureStatus {
+	var requested DisclosureSet
+	if c.Disclosures != nil {
+		requested = c.Disclosures.Set()
+	}
+	statuses := make(map[DisclosureField]DisclosureStatus, len(AllDisclosureFields))
+	for _, field := range AllDisclosureFields {
+		switch {
+		case requested != nil && !requested[field]:
+			statuses[field] = DisclosureStatusHiddenByPolicy
+		case !isFieldDisclosedIn(attestationId, output, field):
+			statuses[field] = DisclosureStatusNotProvided
+		case field == DisclosureExpiryDate && c.ExpiryDisclosure == ExpiryDisclosureValidity:
+			statuses[field] = DisclosureStatusHiddenByPolicy
+		default:
+			statuses[field] = DisclosureStatusDisclosed
+		}
+	}
+	return statuses
+}
+
+// maskDisclosureFields returns output with the given fields blanked out
+func maskDisclosureFields(output GenericDiscloseOutput, fields []DisclosureField) GenericDiscloseOutput {
+	for _, field := range fields {
+		switch field {
+		case DisclosureIssuingState:
+			output.IssuingState = ""
+		case DisclosureName:
+			output.Name = ""
+		case DisclosureDocumentNumber:
+			output.IdNumber = ""
+		case DisclosureNationality:
+			output.Nationality = ""
+		case DisclosureDateOfBirth:
+			output.DateOfBirth = ""
+		case DisclosureGender:
+			output.Gender = ""
+		case DisclosureExpiryDate:
+			output.ExpiryDate = ""
+		case DisclosurePersonalNumber:
+			output.PersonalNumber = ""
+		}
+	}
+	if len(fields) > 0 {
+		// The MRZ composite data repeats the document number, dates and personal number
+		output.MRZ = nil
+	}
+	return output
+}
@@ -0,0 +1,72 @@
+package self
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrDisclosureOverrideNotAllowed is reported when the disclosures requested for a call ask for
+// fields the config's Disclosures do not. It matches ErrOverDisclosure.
+var ErrDisclosureOverrideNotAllowed = fmt.Errorf("%w: requested disclosures exceed the config's", ErrOverDisclosure)
+
+// ErrMissingDisclosure is reported when a proof does not disclose a field requested for the call
+// with ContextWithDisclosures
+var ErrMissingDisclosure = errors.New("proof does not disclose the requested fields")
+
+// requestDisclosuresKey is the context key under which ContextWithDisclosures stores the disclosures
+type requestDisclosuresKey struct{}
+
+// ContextWithDisclosures returns a copy of ctx under which Verify requires proofs to disclose
+// exactly the given fields, for screens requesting different attributes against one policy.
+//
+// The disclosures replace the config's Disclosures for the call: every requested field must be
+// disclosed (ErrMissingDisclosure), others are masked or rejected according to the DisclosureMode,
+// and the result's Config reports them. When the config restricts Disclosures, the call may only
+// request a subset of them (ErrDisclosureOverrideNotAllowed).
+func ContextWithDisclosures(ctx context.Context, disclosures SelfAppDisclosureConfig) context.Context {
+	return context.WithValue(ctx, requestDisclosuresKey{}, disclosures)
+}
+
+// disclosuresFromContext returns the disclosures stored by ContextWithDisclosures, if any
+func disclosuresFromContext(ctx context.Context) (SelfAppDisclosureConfig, bool) {
+	disclosures, ok := ctx.Value(requestDisclosuresKey{}).(SelfAppDisclosureConfig)
+	return disclosures, ok
+}
+
+// applyDisclosureOverride replaces the config's Disclosures with the disclosures requested for the
+// call, unless they request fields the config does not
+func applyDisclosureOverride(config *VerificationConfig, disclosures SelfAppDisclosureConfig, issues *[]ConfigIssue) {
+	if config.Disclosures != nil {
+		if exceeding := disclosures.Set().Difference(config.Disclosures.Set()); len(exceeding) > 0 {
+			*issues = append(*issues, ConfigIssue{
+				Type:    InvalidDisclosure,
+				Message: fmt.Sprintf("Requested disclosures %v are not requested by the config", exceeding.Fields()),
+				Err:     ErrDisclosureOverrideNotAllowed,
+			})
+			return
+		}
+	}
+	config.Disclosures = &disclosures
+}
+
+// validateRequiredDisclosures checks that output discloses every field requested for the call
+func validateRequiredDisclosures(
+	attestationId AttestationId,
+	disclosures SelfAppDisclosureConfig,
+	output GenericDiscloseOutput,
+	issues *[]ConfigIssue,
+) {
+	var missing []DisclosureField
+	for _, field := range disclosures.Set().Fields() {
+		if !isFieldDisclosedIn(attestationId, output, field) {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		*issues = append(*issues, ConfigIssue{
+			Type:    MissingDisclosure,
+			Message: fmt.Sprintf("Proof does not disclose the requested fields %v", missing),
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package self
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DocumentSubtype is the kind of passport, from the second character of the MRZ document code
+type DocumentSubtype string
+
+const (
+	// DocumentSubtypeRegular is an ordinary passport (document code "P<")
+	DocumentSubtypeRegular DocumentSubtype = "regular"
+	// DocumentSubtypeDiplomatic is a diplomatic passport (document code "PD")
+	DocumentSubtypeDiplomatic DocumentSubtype = "diplomatic"
+	// DocumentSubtypeService is a service passport (document code "PS")
+	DocumentSubtypeService DocumentSubtype = "service"
+	// DocumentSubtypeOfficial is an official passport (document code "PO")
+	DocumentSubtypeOfficial DocumentSubtype = "official"
+	// DocumentSubtypeOther is any other passport document code, e.g. emergency passports
+	DocumentSubtypeOther DocumentSubtype = "other"
+)
+
+// AllDocumentSubtypes lists every DocumentSubtype
+var AllDocumentSubtypes = []DocumentSubtype{
+	DocumentSubtypeRegular,
+	DocumentSubtypeDiplomatic,
+	DocumentSubtypeService,
+	DocumentSubtypeOfficial,
+	DocumentSubtypeOther,
+}
+
+// isKnown reports whether the subtype is one of AllDocumentSubtypes
+func (s DocumentSubtype) isKnown() bool {
+	for _, known := range AllDocumentSubtypes {
+		if s == known {
+			return true
+		}
+	}
+	return false
+}
+
+// documentSubtype returns the subtype encoded by the first two revealed bytes, the MRZ document
+// code, or "" for documents without passport subtypes and when the code is not disclosed
+func documentSubtype(attestationId AttestationId, revealedData []byte) DocumentSubtype {
+	if attestationId != Passport || len(revealedData) < 2 || revealedData[0] != 'P' {
+		return ""
+	}
+	switch revealedData[1] {
+	case '<':
+		return DocumentSubtypeRegular
+	case 'D':
+		return DocumentSubtypeDiplomatic
+	case 'S':
+		return DocumentSubtypeService
+	case 'O':
+		return DocumentSubtypeOfficial
+	default:
+		return DocumentSubtypeOther
+	}
+}
+
+// validateAllowedDocumentSubtypes checks that a passport is of one of the config's allowed subtypes.
+// The proof must disclose the document code for the check to pass. Other documents have no subtype
+// and are not checked.
+func validateAllowedDocumentSubtypes(
+	config VerificationConfig,
+	attestationId AttestationId,
+	discloseOutput GenericDiscloseOutput,
+	issues *[]ConfigIssue,
+) {
+	if len(config.AllowedDocumentSubtypes) == 0 || attestationId != Passport {
+		return
+	}
+
+	subtype := discloseOutput.DocumentSubtype
+	if subtype == "" {
+		*issues = append(*issues, ConfigIssue{
+			Type:    InvalidDocumentSubtype,
+			Message: "Document subtype is not disclosed by the proof",
+		})
+		return
+	}
+
+	for _, allowed := range config.AllowedDocumentSubtypes {
+		if allowed == subtype {
+			return
+		}
+	}
+	*issues = append(*issues, ConfigIssue{
+		Type:    InvalidDocumentSubtype,
+		Message: fmt.Sprintf("Document subtype %s is not allowed", subtype),
+	})
+}
+
+// canonicalDocumentSubtypes returns subtypes deduplicated and sorted, or nil when empty
+func canonicalDocumentSubtypes(subtypes []DocumentSubtype) []DocumentSubtype {
+	seen := make(map[DocumentSubtype]bool, len(subtypes))
+	var canonical []DocumentSubtype
+	for _, subtype := range subtypes {
+		if !seen[subtype] {
+			seen[subtype] = true
+			canonical = append(canonical, subtype)
+		}
+	}
+	sort.Slice(canonical, func(i, j int) bool { return canonical[i] < canonical[j] })
+	return canonical
+}
@@ -0,0 +1,206 @@
+package self
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Environment variables read by LoadVerifierEnvConfig
+const (
+	EnvScope              = "SELF_SCOPE"
+	EnvEndpoint           = "SELF_ENDPOINT"
+	EnvNetwork            = "SELF_NETWORK"
+	EnvUserIDType         = "SELF_USER_ID_TYPE"
+	EnvAllowedAttestation = "SELF_ALLOWED_ATTESTATION_IDS"
+)
+
+// Environment variables read by NewKVConfigStoreFromEnv
+const (
+	EnvEtcdEndpoint   = "SELF_ETCD_ENDPOINT"
+	EnvKVConfigPrefix = "SELF_KV_CONFIG_PREFIX"
+)
+
+// Network names accepted in SELF_NETWORK
+const (
+	NetworkMainnet = "mainnet"
+	NetworkTestnet = "testnet"
+)
+
+// VerifierEnvConfig holds the BackendVerifier settings read from the environment
+type VerifierEnvConfig struct {
+	Scope      string
+	Endpoint   string
+	Mainnet    bool
+	UserIDType UserIDType
+	AllowedIds map[AttestationId]bool
+}
+
+// DefaultVerifierEnvConfig returns the settings used for unset variables, suitable for local development
+// against the staging contracts
+func DefaultVerifierEnvConfig() VerifierEnvConfig {
+	return VerifierEnvConfig{
+		Scope:      "self-playground",
+		Endpoint:   "http://localhost:3000",
+		Mainnet:    false,
+		UserIDType: UserIDTypeUUID,
+		AllowedIds: map[AttestationId]bool{
+			Passport: true,
+			EUCard:   true,
+			Aadhaar:  true,
+		},
+	}
+}
+
+// LoadVerifierEnvConfig reads verifier settings from the environment, falling back to
+// DefaultVerifierEnvConfig for unset variables.
+//
+// Variables:
+//   - SELF_SCOPE: application scope, at most 31 ASCII characters
+//   - SELF_ENDPOINT: endpoint URL used for scope hashing
+//   - SELF_NETWORK: "mainnet" or "testnet"
+//   - SELF_USER_ID_TYPE: "hex" or "uuid"
+//   - SELF_ALLOWED_ATTESTATION_IDS: comma-separated attestation names or IDs, e.g. "passport,aadhaar" (see ParseAttestationIDs)
+//
+// Returns an error describing every invalid variable.
+func LoadVerifierEnvConfig() (VerifierEnvConfig, error) {
+	config := DefaultVerifierEnvConfig()
+	var errs []error
+
+	if scope, ok := os.LookupEnv(EnvScope); ok {
+		config.Scope = strings.TrimSpace(scope)
+	}
+	if err := validateScope(config.Scope); err != nil {
+		errs = append(errs, fmt.Errorf("%s: %v", EnvScope, err))
+	}
+
+	if endpoint, ok := os.LookupEnv(EnvEndpoint); ok {
+		config.Endpoint = strings.TrimSpace(endpoint)
+	}
+	if config.Endpoint == "" {
+		errs = append(errs, fmt.Errorf("%s: must not be empty", EnvEndpoint))
+	}
+
+	if network, ok := os.LookupEnv(EnvNetwork); ok {
+		switch strings.ToLower(strings.TrimSpace(network)) {
+		case NetworkMainnet:
+			config.Mainnet = true
+		case NetworkTestnet:
+			config.Mainnet = false
+		default:
+			errs = append(errs, fmt.Errorf("%s: must be %q or %q, got %q", EnvNetwork, NetworkMainnet, NetworkTestnet, network))
+		}
+	}
+
+	if userIDType, ok := os.LookupEnv(EnvUserIDType); ok {
+		switch UserIDType(strings.ToLower(strings.TrimSpace(userIDType))) {
+		case UserIDTypeHex:
+			config.UserIDType = UserIDTypeHex
+		case UserIDTypeUUID:
+			config.UserIDType = UserIDTypeUUID
+		default:
+			errs = append(errs, fmt.Errorf("%s: must be %q or %q, got %q", EnvUserIDType, UserIDTypeHex, UserIDTypeUUID, userIDType))
+		}
+	}
+
+	if allowed, ok := os.LookupEnv(EnvAllowedAttestation); ok {
+		allowedIds, err := ParseAttestationIDs(strings.Split(allowed, ","))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", EnvAllowedAttestation, err))
+		} else {
+			config.AllowedIds = allowedIds
+		}
+	}
+
+	if len(errs) > 0 {
+		return VerifierEnvConfig{}, fmt.Errorf("invalid verifier environment: %w", errors.Join(errs...))
+	}
+	return config, nil
+}
+
+// NewBackendVerifierFromEnv creates a BackendVerifier from the settings returned by LoadVerifierEnvConfig
+func NewBackendVerifierFromEnv(configStorage ConfigStore, opts ...VerifierOption) (*BackendVerifier, error) {
+	config, err := LoadVerifierEnvConfig()
+	if err != nil {
+		return nil, err
+	}
+	return NewBackendVerifier(
+		config.Scope,
+		config.Endpoint,
+		!config.Mainnet,
+		config.AllowedIds,
+		configStorage,
+		config.UserIDType,
+		opts...,
+	)
+}
+
+// NewKVConfigStoreFromEnv creates a KVConfigStore backed by the etcd endpoint in SELF_ETCD_ENDPOINT,
+// reading configs under SELF_KV_CONFIG_PREFIX (DefaultKVConfigPrefix when unset)
+func NewKVConfigStoreFromEnv(getActionIdFunc GetActionIdFunc) (*KVConfigStore, error) {
+	endpoint := strings.TrimSpace(os.Getenv(EnvEtcdEndpoint))
+	if endpoint == "" {
+		return nil, fmt.Errorf("%s: must not be empty", EnvEtcdEndpoint)
+	}
+	prefix := strings.TrimSpace(os.Getenv(EnvKVConfigPrefix))
+	return NewKVConfigStore(NewEtcdKVClient(endpoint, nil), prefix, getActionIdFunc)
+}
+
+// validateScope checks the scope constraints enforced by the Self app
+func validateScope(scope string) error {
+	if scope == "" {
+		return errors.New("must not be empty")
+	}
+	if len(scope) > 31 {
+		return fmt.Errorf("must be at most 31 characters, got %d", len(scope))
+	}
+	for _, char := range scope {
+		if char > 127 {
+			return errors.New("must contain only ASCII characters")
+		}
+	}
+	return nil
+}
+
+// attestationIdNames maps the lower-case names accepted by ParseAttestationIDs to attestation IDs
+var attestationIdNames = map[string]AttestationId{
+	"passport": Passport,
+	"eu_card":  EUCard,
+	"eucard":   EUCard,
+	"eu-card":  EUCard,
+	"id_card":  EUCard,
+	"aadhaar":  Aadhaar,
+}
+
+// ParseAttestationIDs builds an allowedIds map from attestation names ("passport", "eu_card",
+// "aadhaar", matched case-insensitively) or numeric IDs ("1"). Blank entries are ignored.
+//
+// Returns an error listing every unknown entry, or if no attestation ID is given.
+func ParseAttestationIDs(values []string) (map[AttestationId]bool, error) {
+	allowedIds := make(map[AttestationId]bool)
+	var unknown []string
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if id, ok := attestationIdNames[strings.ToLower(value)]; ok {
+			allowedIds[id] = true
+			continue
+		}
+		if id, err := strconv.Atoi(value); err == nil && AllIds[AttestationId(id)] {
+			allowedIds[AttestationId(id)] = true
+			continue
+		}
+		unknown = append(unknown, strconv.Quote(value))
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown attestation IDs %s", strings.Join(unknown, ", "))
+	}
+	if len(allowedIds) == 0 {
+		return nil, errors.New("must list at least one attestation ID")
+	}
+	return allowedIds, nil
+}
@@ -0,0 +1,156 @@
+package self
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupportedCircuitVersion is returned when a proof was generated by a circuit version the verifier does not support
+var ErrUnsupportedCircuitVersion = errors.New("unsupported circuit version")
+
+// UnsupportedCircuitVersionError describes a proof whose public signal layout matches no supported circuit version.
+// Version is the retired circuit version the signals were detected as, or CircuitVersionUnknown.
+type UnsupportedCircuitVersionError struct {
+	AttestationId      AttestationId
+	Version            CircuitVersion
+	PublicSignalsCount int
+	Supported          []CircuitVersion
+}
+
+func (e *UnsupportedCircuitVersionError) Error() string {
+	if e.Version != CircuitVersionUnknown {
+		return fmt.Sprintf("%v: attestation %d proof from circuit version %d, supported versions: %v",
+			ErrUnsupportedCircuitVersion, e.AttestationId, e.Version, e.Supported)
+	}
+	return fmt.Sprintf("%v: attestation %d with %d public signals, supported versions: %v",
+		ErrUnsupportedCircuitVersion, e.AttestationId, e.PublicSignalsCount, e.Supported)
+}
+
+// Is reports whether target is ErrUnsupportedCircuitVersion
+func (e *UnsupportedCircuitVersionError) Is(target error) bool {
+	return target == ErrUnsupportedCircuitVersion
+}
+
+// Errors matching the configuration issues reported by Verify. A *ConfigMismatchError matches
+// (via errors.Is) the error of every issue type it contains.
+var (
+	ErrAttestationNotAllowed = errors.New("attestation ID is not allowed")
+	ErrUserContextMismatch   = errors.New("user context hash does not match the proof")
+	ErrScopeMismatch         = errors.New("scope does not match the proof")
+	ErrInvalidRoot           = errors.New("identity commitment root is not valid")
+	ErrInvalidAttestationId  = errors.New("attestation ID does not match the proof")
+	ErrCountryExcluded       = errors.New("excluded countries are not enforced by the proof")
+	ErrMinimumAgeNotMet      = errors.New("minimum age is not satisfied by the proof")
+	ErrInvalidTimestamp      = errors.New("proof timestamp is out of range")
+	ErrOfacHit               = errors.New("OFAC check failed")
+	ErrSanctionedNationality = errors.New("nationality is sanctioned or sanctions are not enforced by the proof")
+	ErrOverDisclosure        = errors.New("proof discloses fields the policy did not request")
+	ErrConfigNotFound        = errors.New("verification config not found")
+)
+
+// ScopeMismatchError describes a proof generated for another scope. Verify returns it on its own
+// when the scope is the only reason a proof fails; otherwise it is the error of the InvalidScope
+// issue of the *ConfigMismatchError. It matches ErrScopeMismatch.
+type ScopeMismatchError struct {
+	Expected string // Scope hash of the verifier
+	Got      string // Scope hash committed in the proof
+	// ScopeName and Endpoint are the values the verifier hashes into Expected
+	ScopeName string
+	Endpoint  string
+}
+
+func (e *ScopeMismatchError) Error() string {
+	return fmt.Sprintf("%v: expected %s, got %s; the verifier hashes scope %q with endpoint %q, "+
+		"so the frontend must request the proof with the same scope and endpoint",
+		ErrScopeMismatch, e.Expected, e.Got, e.ScopeName, e.Endpoint)
+}
+
+// Is reports whether target is ErrScopeMismatch
+func (e *ScopeMismatchError) Is(target error) bool {
+	return target == ErrScopeMismatch
+}
+
+// Timestamp errors, both matching ErrInvalidTimestamp
+var (
+	ErrProofTooOld     = fmt.Errorf("%w: proof is older than the maximum proof age", ErrInvalidTimestamp)
+	ErrProofFromFuture = fmt.Errorf("%w: proof is dated beyond the allowed future skew", ErrInvalidTimestamp)
+	// ErrProofPredatesSession is reported with WithSessionTimestamp for proofs generated before the
+	// session bound into userContextData started
+	ErrProofPredatesSession = fmt.Errorf("%w: proof was generated before the session started", ErrInvalidTimestamp)
+)
+
+// ErrMinimumAgeMarginNotMet is reported when MinimumAgeMarginDays is set and the holder did not turn
+// MinimumAge at least that many days before the proof date. It matches ErrMinimumAgeNotMet.
+var ErrMinimumAgeMarginNotMet = fmt.Errorf("%w: holder is within the minimum age margin", ErrMinimumAgeNotMet)
+
+// ErrIssuingStateNotAllowed is reported when AllowedIssuingStates is set and the document was issued
+// elsewhere, or the proof does not disclose its issuing state
+var ErrIssuingStateNotAllowed = errors.New("document issuing state is not allowed or not disclosed")
+
+// ErrMissingBiometric is reported when RequireBiometricCommitment is set and the proof does not
+// commit to a biometric hash
+var ErrMissingBiometric = errors.New("proof does not commit to a biometric hash")
+
+// ErrNationalityIssuerMismatch is reported when RequireNationalityMatchesIssuer is set and the
+// disclosed nationality differs from the disclosed issuing state
+var ErrNationalityIssuerMismatch = errors.New("nationality does not match the document issuing state")
+
+// ErrDocumentSubtypeNotAllowed is reported when AllowedDocumentSubtypes is set and the passport is
+// of another subtype, or the proof does not disclose its document code
+var ErrDocumentSubtypeNotAllowed = errors.New("document subtype is not allowed or not disclosed")
+
+// ErrIssuedAsMinor is reported when RejectIssuedAsMinor is set and the document was issued before
+// the holder turned 18
+var ErrIssuedAsMinor = errors.New("document was issued to a minor")
+
+// ErrInvalidProof is reported when the zero-knowledge proof does not verify
+var ErrInvalidProof = errors.New("proof is not valid")
+
+// ErrInternalVerification is returned when proof verification panics, e.g. in a native cryptography
+// backend. The panic and its stack trace are logged and the process keeps running.
+var ErrInternalVerification = errors.New("internal verification error")
+
+// ErrVerificationBudgetExceeded is returned when the proof verification step runs longer than the
+// budget set with WithVerificationBudget
+var ErrVerificationBudgetExceeded = errors.New("proof verification exceeded its budget")
+
+// ErrConfigStoreTimeout is reported when a ConfigStore call exceeds the timeout set with
+// WithConfigStoreTimeout and no fallback config is allowed
+var ErrConfigStoreTimeout = errors.New("config store timed out")
+
+// ErrVerificationTimeout is returned when a verification does not finish before its deadline.
+// It also matches context.DeadlineExceeded.
+var ErrVerificationTimeout = errors.New("verification timed out")
+
+// configMismatchErrors maps each ConfigMismatch to the error it is reported as
+var configMismatchErrors = map[ConfigMismatch]error{
+	InvalidId:                     ErrAttestationNotAllowed,
+	InvalidUserContextHash:        ErrUserContextMismatch,
+	InvalidScope:                  ErrScopeMismatch,
+	InvalidRoot:                   ErrInvalidRoot,
+	InvalidAttestationId:          ErrInvalidAttestationId,
+	InvalidForbiddenCountriesList: ErrCountryExcluded,
+	InvalidMinimumAge:             ErrMinimumAgeNotMet,
+	InvalidTimestamp:              ErrInvalidTimestamp,
+	InvalidOfac:                   ErrOfacHit,
+	InvalidSanctionedNationality:  ErrSanctionedNationality,
+	InvalidDisclosure:             ErrOverDisclosure,
+	InvalidIssuingState:           ErrIssuingStateNotAllowed,
+	InvalidContextDataSignature:   ErrContextDataSignatureInvalid,
+	MissingBiometric:              ErrMissingBiometric,
+	InvalidNationalityIssuer:      ErrNationalityIssuerMismatch,
+	InvalidDocumentSubtype:        ErrDocumentSubtypeNotAllowed,
+	InvalidIssuedAsMinor:          ErrIssuedAsMinor,
+	InconsistentDisclosure:        ErrInconsistentDisclosure,
+	MissingDisclosure:             ErrMissingDisclosure,
+	PlaceholderData:               ErrPlaceholderData,
+	ConfigNotFound:                ErrConfigNotFound,
+}
+
+// Err returns the error matching this ConfigMismatch type
+func (m ConfigMismatch) Err() error {
+	if err, ok := configMismatchErrors[m]; ok {
+		return err
+	}
+	return errors.New(string(m))
+}
@@ -0,0 +1,40 @@
+package self
+
+import "time"
+
+// ExpiryDisclosure selects how a disclosed document expiry date is reported on the result
+type ExpiryDisclosure string
+
+const (
+	// ExpiryDisclosureDate reports the raw expiry date (the default)
+	ExpiryDisclosureDate ExpiryDisclosure = "date"
+	// ExpiryDisclosureValidity masks the expiry date and reports only whether the document is currently valid
+	ExpiryDisclosureValidity ExpiryDisclosure = "validity"
+)
+
+// applyExpiryDisclosure replaces a disclosed expiry date with DocumentValid when the config asks for
+// ExpiryDisclosureValidity. Undisclosed or unparsable expiry dates are masked without a validity.
+func (c VerificationConfig) applyExpiryDisclosure(attestationId AttestationId, output GenericDiscloseOutput, now time.Time) GenericDiscloseOutput {
+	if c.ExpiryDisclosure != ExpiryDisclosureValidity || attestationId == Aadhaar {
+		return output
+	}
+
+	if expiry, ok := parseExpiryDate(removeNullBytes(output.ExpiryDate)); ok {
+		// A document remains valid through its expiry day
+		valid := now.Before(expiry.AddDate(0, 0, 1))
+		output.DocumentValid = &valid
+	}
+	output.ExpiryDate = ""
+	// The MRZ composite data includes the expiry date
+	output.MRZ = nil
+	return output
+}
+
+// parseExpiryDate parses an MRZ YYMMDD expiry date. Expiry dates of documents in circulation
+// all fall in the 21st century.
+func parseExpiryDate(value string) (time.Time, bool) {
+	if len(value) != 6 {
+		return time.Time{}, false
+	}
+	return parseDigitsDate("20"+value, 8, time.Time{})
+}
@@ -0,0 +1,132 @@
+package self
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// ErrInvalidFieldElement is returned when a public signal is not a valid BN254 scalar field element,
+// or a proof coordinate not a valid BN254 base field element
+var ErrInvalidFieldElement = errors.New("invalid field element")
+
+// scalarFieldModulus is the order r of the BN254 scalar field the circuits operate in, bounding public signals
+var scalarFieldModulus, _ = new(big.Int).SetString("21888242871839275222246405745257275088548364400416034343698204186575808495617", 10)
+
+// baseFieldModulus is the order q of the BN254 base field, bounding the curve point coordinates of a proof
+var baseFieldModulus, _ = new(big.Int).SetString("21888242871839275222246405745257275088696311157297823662689037894645226208583", 10)
+
+// decodeFieldElement decodes an element of the field of order modulus encoded as a JSON string
+// (decimal or hex) or number, returning it in base 10
+func decodeFieldElement(data json.RawMessage, modulus *big.Int) (string, error) {
+	var value *big.Int
+	ok := false
+
+	var text string
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var token interface{}
+	if err := decoder.Decode(&token); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidFieldElement, err)
+	}
+	switch v := token.(type) {
+	case string:
+		text = v
+		value, ok = parsePublicSignal(v)
+	case json.Number:
+		text = v.String()
+		value, ok = new(big.Int).SetString(text, 10)
+	default:
+		text = string(data)
+	}
+
+	if !ok || value.Sign() < 0 || value.Cmp(modulus) >= 0 {
+		return "", fmt.Errorf("%w: %s", ErrInvalidFieldElement, text)
+	}
+	return value.String(), nil
+}
+
+// UnmarshalJSON decodes public signals encoded as JSON strings or numbers, normalizing them to base 10.
+// Arrays longer than DefaultMaxPublicSignals are rejected with ErrTooManyPublicSignals as soon as the
+// limit is exceeded.
+func (p *PublicSignals) UnmarshalJSON(data []byte) error {
+	return p.decode(data, DefaultMaxPublicSignals)
+}
+
+// decode implements UnmarshalJSON, rejecting arrays longer than maxSignals
+func (p *PublicSignals) decode(data []byte, maxSignals int) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		*p = nil
+		return nil
+	}
+	if token != json.Delim('[') {
+		return errors.New("public signals must be a JSON array")
+	}
+
+	signals := PublicSignals{}
+	for decoder.More() {
+		if len(signals) >= maxSignals {
+			return fmt.Errorf("%w: more than %d", ErrTooManyPublicSignals, maxSignals)
+		}
+		var element json.RawMessage
+		if err := decoder.Decode(&element); err != nil {
+			return err
+		}
+		signal, err := decodeFieldElement(element, scalarFieldModulus)
+		if err != nil {
+			return fmt.Errorf("public signal %d: %w", len(signals), err)
+		}
+		signals = append(signals, signal)
+	}
+	*p = signals
+	return nil
+}
+
+// UnmarshalJSON decodes a proof whose elements are encoded as JSON strings or numbers,
+// normalizing them to base 10. Elements are point coordinates, so they are checked against the base field.
+func (p *VcAndDiscloseProof) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		A [2]json.RawMessage    `json:"a"`
+		B [2][2]json.RawMessage `json:"b"`
+		C [2]json.RawMessage    `json:"c"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	var proof VcAndDiscloseProof
+	decode := func(name string, element json.RawMessage, target *string) error {
+		// Missing elements are left empty for validation to report
+		if element == nil {
+			return nil
+		}
+		value, err := decodeFieldElement(element, baseFieldModulus)
+		if err != nil {
+			return fmt.Errorf("proof.%s: %w", name, err)
+		}
+		*target = value
+		return nil
+	}
+	for i := 0; i < 2; i++ {
+		if err := decode(fmt.Sprintf("a[%d]", i), raw.A[i], &proof.A[i]); err != nil {
+			return err
+		}
+		if err := decode(fmt.Sprintf("c[%d]", i), raw.C[i], &proof.C[i]); err != nil {
+			return err
+		}
+		for j := 0; j < 2; j++ {
+			if err := decode(fmt.Sprintf("b[%d][%d]", i, j), raw.B[i][j], &proof.B[i][j]); err != nil {
+				return err
+			}
+		}
+	}
+	*p = proof
+	return nil
+}
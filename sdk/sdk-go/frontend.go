@@ -0,0 +1,98 @@
+package self
+
+import (
+	"context"
+	"fmt"
+)
+
+// Endpoint types of the Self frontend SDK, selecting the network the app generates proofs for
+const (
+	FrontendEndpointTypeHTTPS        = "https"
+	FrontendEndpointTypeStagingHTTPS = "staging_https"
+)
+
+// FrontendConfig holds the parameters the frontend SDK (SelfAppBuilder) must use for proofs to pass
+// this verifier. Marshal it to JSON and pass it to the frontend instead of duplicating the values.
+type FrontendConfig struct {
+	Scope        string              `json:"scope"`
+	Endpoint     string              `json:"endpoint"`
+	EndpointType string              `json:"endpointType"`
+	UserIdType   UserIDType          `json:"userIdType"`
+	Disclosures  FrontendDisclosures `json:"disclosures"`
+}
+
+// FrontendDisclosures are the disclosure requirements of a FrontendConfig, in the shape the
+// frontend SDK expects: the circuit checks alongside the attributes to disclose
+type FrontendDisclosures struct {
+	MinimumAge        int      `json:"minimumAge,omitempty"`
+	ExcludedCountries []string `json:"excludedCountries,omitempty"`
+	Ofac              bool     `json:"ofac,omitempty"`
+	SelfAppDisclosureConfig
+}
+
+// BuildFrontendConfig returns the FrontendConfig for proofs checked against the config with the
+// given ID: the verifier's scope (or the scope expected under ctx, see ContextWithExpectedScope),
+// endpoint and network, and the disclosures the config needs (or those requested under ctx, see
+// ContextWithDisclosures).
+//
+// Excluded countries include the config's sanctioned nationalities, which the circuit must enforce
+// too. Attributes that checks read from the disclosure are requested on top of Disclosures: the
+// issuing state for AllowedIssuingStates, nationality and issuing state for
+// RequireNationalityMatchesIssuer and the date of birth for MinimumAgeMarginDays.
+func (s *BackendVerifier) BuildFrontendConfig(ctx context.Context, configId string) (*FrontendConfig, error) {
+	scope, err := s.expectedScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	config, err := ResolveConfig(ctx, s.configStorage, configId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrConfigNotFound, configId, err)
+	}
+	if isEmptyVerificationConfig(config) {
+		return nil, fmt.Errorf("%w: %s", ErrConfigNotFound, configId)
+	}
+	if disclosures, ok := disclosuresFromContext(ctx); ok {
+		var issues []ConfigIssue
+		if applyDisclosureOverride(&config, disclosures, &issues); len(issues) > 0 {
+			return nil, NewConfigMismatchError(issues)
+		}
+	}
+
+	endpointType := FrontendEndpointTypeHTTPS
+	if s.mockPassport {
+		endpointType = FrontendEndpointTypeStagingHTTPS
+	}
+	return &FrontendConfig{
+		Scope:        scope.name,
+		Endpoint:     s.endpoint,
+		EndpointType: endpointType,
+		UserIdType:   s.userIdentifierType,
+		Disclosures:  frontendDisclosures(config),
+	}, nil
+}
+
+// frontendDisclosures returns the disclosure requirements the frontend must request for config
+func frontendDisclosures(config VerificationConfig) FrontendDisclosures {
+	disclosures := FrontendDisclosures{
+		MinimumAge: config.MinimumAge,
+		Ofac:       config.Ofac,
+	}
+	for _, country := range canonicalCountries(mergeCountries(config.ExcludedCountries, config.SanctionedNationalities)) {
+		disclosures.ExcludedCountries = append(disclosures.ExcludedCountries, string(country))
+	}
+
+	if config.Disclosures != nil {
+		disclosures.SelfAppDisclosureConfig = *config.Disclosures
+	}
+	if len(config.AllowedIssuingStates) > 0 {
+		disclosures.IssuingState = true
+	}
+	if config.RequireNationalityMatchesIssuer {
+		disclosures.IssuingState = true
+		disclosures.Nationality = true
+	}
+	if config.MinimumAgeMarginDays > 0 {
+		disclosures.DateOfBirth = true
+	}
+	return disclosures
+}
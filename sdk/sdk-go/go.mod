@@ -8,6 +8,8 @@ require (
 	github.com/ethereum/go-ethereum v1.16.2
 	github.com/iden3/go-iden3-crypto v0.0.17
 	golang.org/x/crypto v0.36.0
+	golang.org/x/text v0.23.0
+	google.golang.org/protobuf v1.34.2
 )
 
 require (
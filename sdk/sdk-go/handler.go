@@ -0,0 +1,140 @@
+package self
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxBodyBytes is the request body limit applied by VerifyHandler when none is configured
+const DefaultMaxBodyBytes int64 = 1 << 20
+
+// VerifyInput holds the parameters of a single verification, as sent by the frontend
+type VerifyInput struct {
+	AttestationId   int                `json:"attestationId"`
+	Proof           VcAndDiscloseProof `json:"proof"`
+	PublicSignals   PublicSignals      `json:"publicSignals"` // Accepts signals encoded as strings or numbers
+	UserContextData string             `json:"userContextData"`
+	// DisclosureSalts optionally opens salted field commitments of the proof, keyed by field; see
+	// ContextWithDisclosureSalts for the attestation types supporting them
+	DisclosureSalts map[DisclosureField]string `json:"disclosureSalts,omitempty"`
+}
+
+// VerifyHandler is an http.Handler that verifies proofs posted by the Self app
+type VerifyHandler struct {
+	verifier *BackendVerifier
+
+	// MaxBodyBytes limits the size of the request body (DefaultMaxBodyBytes when zero)
+	MaxBodyBytes int64
+	// MaxJSONDepth limits the nesting of the request body (DefaultMaxJSONDepth when zero)
+	MaxJSONDepth int
+	// MaxPublicSignals limits the number of public signals in the request body (the verifier's
+	// WithMaxPublicSignals limit when zero)
+	MaxPublicSignals int
+	// SubjectNaming renames the credential subject keys of responses (camelCase when nil)
+	SubjectNaming FieldNaming
+	// Messages, when set, localizes error messages to the request's Accept-Language
+	Messages MessageCatalog
+}
+
+// NewVerifyHandler creates a VerifyHandler backed by the given verifier
+func NewVerifyHandler(verifier *BackendVerifier) *VerifyHandler {
+	return &VerifyHandler{verifier: verifier}
+}
+
+// ServeHTTP decodes a VerifyInput from the request body, verifies it and writes a
+// VerifyResponse, or an ErrorResponse whose code identifies the failure. Invalid requests
+// are rejected with every field-level problem listed in ErrorResponse.Errors. Status codes
+// follow HTTPStatusFor.
+//
+// Bodies sent with Content-Encoding: gzip are decompressed; MaxBodyBytes then limits both the
+// compressed and the decompressed size, so small archives cannot expand without bound.
+func (h *VerifyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, h.localize(r, NewErrorResponse(ErrorCodeInvalidRequest, "Method not allowed")))
+		return
+	}
+
+	maxBodyBytes := h.MaxBodyBytes
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = DefaultMaxBodyBytes
+	}
+
+	requestBody, err := decodeContentEncoding(r.Header.Get("Content-Encoding"), http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		writeJSON(w, http.StatusUnsupportedMediaType, h.localize(r, NewErrorResponse(ErrorCodeInvalidRequest, err.Error())))
+		return
+	}
+	defer requestBody.Close()
+
+	maxPublicSignals := h.MaxPublicSignals
+	if maxPublicSignals <= 0 {
+		maxPublicSignals = h.verifier.maxPublicSignals
+	}
+
+	input, err := decodeVerifyInput(requestBody, DecodeLimits{
+		MaxBytes:         maxBodyBytes,
+		MaxDepth:         h.MaxJSONDepth,
+		MaxPublicSignals: maxPublicSignals,
+	}, h.verifier.anonymousConfigId != "")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, h.localize(r, errorResponseFor(err)))
+		return
+	}
+
+	result, err := h.verifier.verifyInput(r.Context(), input)
+	status, body := NewVerifyHTTPResponse(result, err, h.SubjectNaming)
+	if errorResponse, ok := body.(ErrorResponse); ok {
+		body = h.localize(r, errorResponse)
+	}
+	writeJSON(w, status, body)
+}
+
+// decodeContentEncoding returns the decoded body for the request's Content-Encoding. Gzip header
+// and stream errors surface when the body is read, matching errMalformedGzip.
+func decodeContentEncoding(encoding string, body io.ReadCloser) (io.ReadCloser, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "", "identity":
+		return body, nil
+	case "gzip", "x-gzip":
+		return &gzipBody{body: body}, nil
+	default:
+		return nil, fmt.Errorf("Content-Encoding %q is not supported", encoding)
+	}
+}
+
+// gzipBody decompresses a gzip request body, opening the gzip stream on the first read
+type gzipBody struct {
+	body   io.ReadCloser
+	reader *gzip.Reader
+}
+
+func (b *gzipBody) Read(p []byte) (int, error) {
+	if b.reader == nil {
+		reader, err := gzip.NewReader(b.body)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %w", errMalformedGzip, err)
+		}
+		b.reader = reader
+	}
+	n, err := b.reader.Read(p)
+	if err != nil && err != io.EOF {
+		err = fmt.Errorf("%w: %w", errMalformedGzip, err)
+	}
+	return n, err
+}
+
+func (b *gzipBody) Close() error {
+	return b.body.Close()
+}
+
+// localize translates the message of response when a message catalog is set
+func (h *VerifyHandler) localize(r *http.Request, response ErrorResponse) ErrorResponse {
+	if h.Messages == nil {
+		return response
+	}
+	return h.Messages.Localize(response, requestLocale(r))
+}
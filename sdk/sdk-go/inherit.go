@@ -0,0 +1,114 @@
+package self
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxConfigInheritanceDepth is the longest chain of parents ResolveConfig follows
+const MaxConfigInheritanceDepth = 8
+
+// ErrConfigInheritance is returned by ResolveConfig for parent chains that loop or are too deep
+var ErrConfigInheritance = errors.New("invalid config inheritance")
+
+// ResolveConfig reads the config with the given ID from store and merges it over its Parent chain
+// with MergeConfigs. The result has no Parent. A missing parent fails like a missing config, with an
+// error matching ErrConfigNotFound.
+func ResolveConfig(ctx context.Context, store ConfigStore, id string) (VerificationConfig, error) {
+	config, err := store.GetConfig(ctx, id)
+	if err != nil || config.Parent == "" {
+		return config, err
+	}
+
+	chain := []VerificationConfig{config}
+	seen := map[string]bool{id: true}
+	for parentId := config.Parent; parentId != ""; parentId = chain[len(chain)-1].Parent {
+		if seen[parentId] {
+			return VerificationConfig{}, fmt.Errorf("%w: config %s inherits from itself through %s", ErrConfigInheritance, id, parentId)
+		}
+		if len(chain) > MaxConfigInheritanceDepth {
+			return VerificationConfig{}, fmt.Errorf("%w: config %s has more than %d ancestors", ErrConfigInheritance, id, MaxConfigInheritanceDepth)
+		}
+		seen[parentId] = true
+
+		parent, err := store.GetConfig(ctx, parentId)
+		if err != nil {
+			return VerificationConfig{}, fmt.Errorf("parent config %s of %s: %w", parentId, id, err)
+		}
+		if isEmptyVerificationConfig(parent) {
+			return VerificationConfig{}, fmt.Errorf("%w: parent config %s of %s", ErrConfigNotFound, parentId, id)
+		}
+		chain = append(chain, parent)
+	}
+
+	// Apply the chain from the root down, so nearer configs override further ones
+	resolved := chain[len(chain)-1]
+	for i := len(chain) - 2; i >= 0; i-- {
+		resolved = MergeConfigs(resolved, chain[i])
+	}
+	resolved.Parent = ""
+	return resolved, nil
+}
+
+// MergeConfigs returns child applied over parent, the effective policy of a child config.
+//
+// Each field the child sets (non-zero) overrides the parent's; unset fields are inherited. As a
+// result a child can switch a requirement on but not off: an unset bool inherits the parent's value.
+// TrustProofOfac relaxes screening instead, so it holds only when the parent and the child both set it.
+// The deny lists ExcludedCountries and SanctionedNationalities are merged, so a child can only add
+// countries to them. The allow lists AllowedIssuingStates and AllowedDocumentSubtypes, as well as
+// AdvisoryChecks and Disclosures, are replaced as a whole when the child sets them.
+func MergeConfigs(parent, child VerificationConfig) VerificationConfig {
+	merged := parent
+	merged.Parent = child.Parent
+	if child.MinimumAge != 0 {
+		merged.MinimumAge = child.MinimumAge
+	}
+	merged.ExcludedCountries = mergeCountries(parent.ExcludedCountries, child.ExcludedCountries)
+	merged.Ofac = parent.Ofac || child.Ofac
+	if child.OfacMode != "" {
+		merged.OfacMode = child.OfacMode
+	}
+	if child.AdvisoryChecks != nil {
+		merged.AdvisoryChecks = child.AdvisoryChecks
+	}
+	if child.ExpiryDisclosure != "" {
+		merged.ExpiryDisclosure = child.ExpiryDisclosure
+	}
+	merged.SanctionedNationalities = mergeCountries(parent.SanctionedNationalities, child.SanctionedNationalities)
+	if child.Disclosures != nil {
+		merged.Disclosures = child.Disclosures
+	}
+	if child.AllowedIssuingStates != nil {
+		merged.AllowedIssuingStates = child.AllowedIssuingStates
+	}
+	merged.RequireBiometricCommitment = parent.RequireBiometricCommitment || child.RequireBiometricCommitment
+	merged.RequireNationalityMatchesIssuer = parent.RequireNationalityMatchesIssuer || child.RequireNationalityMatchesIssuer
+	if child.MinimumAgeMarginDays != 0 {
+		merged.MinimumAgeMarginDays = child.MinimumAgeMarginDays
+	}
+	merged.TrustProofOfac = parent.TrustProofOfac && child.TrustProofOfac
+	if child.AllowedDocumentSubtypes != nil {
+		merged.AllowedDocumentSubtypes = child.AllowedDocumentSubtypes
+	}
+	merged.RejectIssuedAsMinor = parent.RejectIssuedAsMinor || child.RejectIssuedAsMinor
+	return merged
+}
+
+// mergeCountries returns the union of two country lists, keeping the parent's order first
+func mergeCountries[T ~string](parent, child []T) []T {
+	if len(child) == 0 {
+		return parent
+	}
+	merged := make([]T, 0, len(parent)+len(child))
+	seen := make(map[string]bool, len(parent)+len(child))
+	for _, country := range append(append([]T(nil), parent...), child...) {
+		if key := strings.ToUpper(string(country)); !seen[key] {
+			seen[key] = true
+			merged = append(merged, country)
+		}
+	}
+	return merged
+}
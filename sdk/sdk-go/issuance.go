@@ -0,0 +1,53 @@
+package self
+
+import (
+	"fmt"
+	"time"
+)
+
+// adultAge is the age from which a holder is no longer a minor, for IssuedAsMinor
+const adultAge = 18
+
+// issuedAsMinor reports whether the document was issued before the holder turned 18, or returns nil
+// when the output does not disclose both its date of issue and the date of birth. Two-digit birth
+// years resolve to the most recent date not after the date of issue.
+func issuedAsMinor(attestationId AttestationId, output GenericDiscloseOutput) *bool {
+	issued, err := time.Parse(isoDateLayout, output.DateOfIssueISO)
+	if err != nil {
+		return nil
+	}
+
+	dateOfBirth, ok := time.Time{}, false
+	if isFieldDisclosedIn(attestationId, output, DisclosureDateOfBirth) {
+		dateOfBirth, ok = parseDateOfBirth(attestationId, removeNullBytes(output.DateOfBirth), issued)
+	}
+	if !ok && output.DateOfBirthISO != "" {
+		dateOfBirth, err = time.Parse(isoDateLayout, output.DateOfBirthISO)
+		ok = err == nil
+	}
+	if !ok {
+		return nil
+	}
+
+	minor := ageAt(dateOfBirth, issued) < adultAge
+	return &minor
+}
+
+// applyIssuedAsMinor sets result.IssuedAsMinor and enforces RejectIssuedAsMinor of the result's
+// config. Documents whose issuance is not disclosed are not applicable and pass.
+func applyIssuedAsMinor(result *VerificationResult) error {
+	result.IssuedAsMinor = issuedAsMinor(result.AttestationId, result.DiscloseOutput)
+	if !result.Config.RejectIssuedAsMinor || result.IssuedAsMinor == nil || !*result.IssuedAsMinor {
+		return nil
+	}
+
+	issue := ConfigIssue{
+		Type:    InvalidIssuedAsMinor,
+		Message: fmt.Sprintf("Document was issued on %s, before the holder turned %d", result.DiscloseOutput.DateOfIssueISO, adultAge),
+	}
+	if result.Config.isAdvisory(CheckIssuedAsMinor) {
+		result.AdvisoryIssues = append(result.AdvisoryIssues, issue)
+		return nil
+	}
+	return NewConfigMismatchError([]ConfigIssue{issue})
+}
@@ -0,0 +1,84 @@
+package self
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/selfxyz/self/sdk/sdk-go/common"
+)
+
+// validateAllowedIssuingStates checks that the document was issued by one of the config's allowed
+// issuing states. The proof must disclose the issuing state for the check to pass.
+func validateAllowedIssuingStates(
+	config VerificationConfig,
+	attestationId AttestationId,
+	discloseOutput GenericDiscloseOutput,
+	issues *[]ConfigIssue,
+) {
+	if len(config.AllowedIssuingStates) == 0 {
+		return
+	}
+
+	issuingState := documentIssuingState(attestationId, discloseOutput)
+	if issuingState == "" {
+		*issues = append(*issues, ConfigIssue{
+			Type:    InvalidIssuingState,
+			Message: "Issuing state is not disclosed by the proof",
+		})
+		return
+	}
+
+	for _, allowed := range config.AllowedIssuingStates {
+		if allowed == issuingState {
+			return
+		}
+	}
+	*issues = append(*issues, ConfigIssue{
+		Type:    InvalidIssuingState,
+		Message: fmt.Sprintf("Issuing state %s is not allowed", issuingState),
+	})
+}
+
+// validateNationalityMatchesIssuer checks that the disclosed nationality equals the disclosed issuing
+// state. Nothing is checked unless the proof discloses both.
+func validateNationalityMatchesIssuer(
+	config VerificationConfig,
+	attestationId AttestationId,
+	discloseOutput GenericDiscloseOutput,
+	issues *[]ConfigIssue,
+) {
+	if !config.RequireNationalityMatchesIssuer ||
+		!isFieldDisclosedIn(attestationId, discloseOutput, DisclosureNationality) ||
+		!isFieldDisclosedIn(attestationId, discloseOutput, DisclosureIssuingState) {
+		return
+	}
+
+	nationality := strings.TrimSpace(removeNullBytes(discloseOutput.Nationality))
+	issuingState := strings.TrimSpace(removeNullBytes(discloseOutput.IssuingState))
+	if nationality != issuingState {
+		*issues = append(*issues, ConfigIssue{
+			Type:    InvalidNationalityIssuer,
+			Message: fmt.Sprintf("Nationality %s does not match issuing state %s", nationality, issuingState),
+		})
+	}
+}
+
+// documentIssuingState returns the country that issued the document, or "" if it is not disclosed.
+// Aadhaar documents are always issued by India; their disclosed issuing state is the Indian state.
+func documentIssuingState(attestationId AttestationId, discloseOutput GenericDiscloseOutput) common.Country3LetterCode {
+	if attestationId == Aadhaar {
+		return common.IND
+	}
+	if !isDisclosedValue(discloseOutput.IssuingState) {
+		return ""
+	}
+	return common.Country3LetterCode(strings.TrimSpace(removeNullBytes(discloseOutput.IssuingState)))
+}
+
+// documentNationality returns the disclosed nationality, or "" when it is not disclosed
+func documentNationality(attestationId AttestationId, discloseOutput GenericDiscloseOutput) common.Country3LetterCode {
+	if !isFieldDisclosedIn(attestationId, discloseOutput, DisclosureNationality) {
+		return ""
+	}
+	return common.Country3LetterCode(strings.TrimSpace(removeNullBytes(discloseOutput.Nationality)))
+}
@@ -0,0 +1,150 @@
+package self
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultJWTTTL is the lifetime of tokens built by ToJWT when the signer sets none
+const DefaultJWTTTL = 5 * time.Minute
+
+// JWTAlgorithm is the JWS "alg" of tokens built by ToJWT
+type JWTAlgorithm string
+
+const (
+	// JWTAlgorithmHS256 signs with HMAC SHA-256 and a []byte secret
+	JWTAlgorithmHS256 JWTAlgorithm = "HS256"
+	// JWTAlgorithmES256 signs with ECDSA P-256 and an *ecdsa.PrivateKey
+	JWTAlgorithmES256 JWTAlgorithm = "ES256"
+	// JWTAlgorithmEdDSA signs with Ed25519 and an ed25519.PrivateKey
+	JWTAlgorithmEdDSA JWTAlgorithm = "EdDSA"
+)
+
+// JWTSigner signs the tokens built by ToJWT with a key provided by the application
+type JWTSigner struct {
+	Algorithm JWTAlgorithm
+	// Key is a []byte secret, an *ecdsa.PrivateKey or an ed25519.PrivateKey, depending on Algorithm
+	Key interface{}
+	// KeyID, when set, is written to the "kid" header so receivers can select the verification key
+	KeyID string
+	// Issuer, when set, is written to the "iss" claim
+	Issuer string
+	// TTL is the token lifetime, DefaultJWTTTL when zero
+	TTL time.Duration
+}
+
+// VerificationClaims are the claims of a token built by ToJWT
+type VerificationClaims struct {
+	Issuer        string        `json:"iss,omitempty"`
+	Subject       string        `json:"sub,omitempty"` // The user identifier
+	IssuedAt      int64         `json:"iat"`
+	ExpiresAt     int64         `json:"exp"`
+	Scope         string        `json:"scope,omitempty"`
+	AttestationId AttestationId `json:"attestationId"`
+	ConfigId      string        `json:"configId,omitempty"`
+	ConfigHash    string        `json:"configHash,omitempty"`
+	Nullifier     string        `json:"nullifier,omitempty"`
+	// CredentialSubject holds the disclosed attributes left after masking, as in VerifyResponse
+	CredentialSubject CredentialSubject `json:"credentialSubject"`
+}
+
+// jwtHeader is the JOSE header of tokens built by ToJWT
+type jwtHeader struct {
+	Algorithm JWTAlgorithm `json:"alg"`
+	Type      string       `json:"typ"`
+	KeyID     string       `json:"kid,omitempty"`
+}
+
+// ToJWT builds a signed JWT (compact JWS) representing a valid result, so services can pass the
+// verification on without re-verifying the proof. The claims are the masked credential subject plus
+// the scope, config and nullifier of the verification; see VerificationClaims.
+func (r *VerificationResult) ToJWT(signer JWTSigner) (string, error) {
+	if !r.IsValidDetails.IsValid {
+		return "", ErrInvalidProof
+	}
+
+	ttl := signer.TTL
+	if ttl <= 0 {
+		ttl = DefaultJWTTTL
+	}
+	now := time.Now()
+	claims := VerificationClaims{
+		Issuer:            signer.Issuer,
+		Subject:           r.UserData.UserIdentifier,
+		IssuedAt:          now.Unix(),
+		ExpiresAt:         now.Add(ttl).Unix(),
+		Scope:             r.Scope,
+		AttestationId:     r.AttestationId,
+		ConfigId:          r.ConfigId,
+		ConfigHash:        r.ConfigHash,
+		Nullifier:         r.DiscloseOutput.Nullifier,
+		CredentialSubject: NewCredentialSubject(r),
+	}
+
+	header, err := json.Marshal(jwtHeader{Algorithm: signer.Algorithm, Type: "JWT", KeyID: signer.KeyID})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWT header: %v", err)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWT claims: %v", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	signature, err := signer.sign([]byte(signingInput))
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// sign returns the JWS signature of signingInput
+func (s JWTSigner) sign(signingInput []byte) ([]byte, error) {
+	switch s.Algorithm {
+	case JWTAlgorithmHS256:
+		secret, ok := s.Key.([]byte)
+		if !ok || len(secret) == 0 {
+			return nil, fmt.Errorf("%s requires a non-empty []byte key", s.Algorithm)
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(signingInput)
+		return mac.Sum(nil), nil
+	case JWTAlgorithmES256:
+		privateKey, ok := s.Key.(*ecdsa.PrivateKey)
+		if !ok || privateKey == nil {
+			return nil, fmt.Errorf("%s requires an *ecdsa.PrivateKey", s.Algorithm)
+		}
+		if privateKey.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("%s requires a P-256 key, got %s", s.Algorithm, privateKey.Curve.Params().Name)
+		}
+		digest := sha256.Sum256(signingInput)
+		r, sig, err := ecdsa.Sign(rand.Reader, privateKey, digest[:])
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign JWT: %v", err)
+		}
+		// JWS encodes ES256 signatures as 32-byte big-endian r followed by 32-byte s
+		signature := make([]byte, 64)
+		r.FillBytes(signature[:32])
+		sig.FillBytes(signature[32:])
+		return signature, nil
+	case JWTAlgorithmEdDSA:
+		privateKey, ok := s.Key.(ed25519.PrivateKey)
+		if !ok || len(privateKey) != ed25519.PrivateKeySize {
+			return nil, fmt.Errorf("%s requires an ed25519.PrivateKey", s.Algorithm)
+		}
+		return ed25519.Sign(privateKey, signingInput), nil
+	case "":
+		return nil, errors.New("JWT signer algorithm must be set")
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", s.Algorithm)
+	}
+}
@@ -0,0 +1,276 @@
+package self
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrKeyMismatch is returned by a KeyedAttestationVerifier when a proof does not verify against the
+// key it was given because it was generated for another one, e.g. after a key rotation. Verify then
+// refreshes the keys from the KeySource and retries once.
+var ErrKeyMismatch = errors.New("verification key mismatch")
+
+// ErrKeyNotFound is returned when the KeySource holds no key for an attestation type
+var ErrKeyNotFound = errors.New("verification key not found")
+
+// maxKeySourceSize bounds the verification keys read from a file or URL
+const maxKeySourceSize = 1 << 20
+
+// VerificationKeys maps attestation IDs to the verification key of their circuit, e.g. the
+// contents of a snarkjs verification_key.json. Encoded as JSON, it is an object keyed by the
+// decimal attestation ID.
+type VerificationKeys map[AttestationId]json.RawMessage
+
+// KeySource provides the current verification keys of custom attestation types. Keys of the built-in
+// types live in the verifier contracts registered with the hub, so they rotate on chain.
+type KeySource interface {
+	// LoadKeys fetches the current verification keys
+	LoadKeys(ctx context.Context) (VerificationKeys, error)
+}
+
+// KeySourceFunc adapts a function to a KeySource
+type KeySourceFunc func(ctx context.Context) (VerificationKeys, error)
+
+// LoadKeys calls f(ctx)
+func (f KeySourceFunc) LoadKeys(ctx context.Context) (VerificationKeys, error) {
+	return f(ctx)
+}
+
+// KeyedAttestationVerifier is implemented by AttestationVerifiers that verify proofs with a key from
+// the verifier's KeySource (see WithKeySource) instead of keys of their own. Verify calls
+// VerifyWithKey instead of Verify when a KeySource is set.
+type KeyedAttestationVerifier interface {
+	AttestationVerifier
+	// VerifyWithKey checks the proof against key and returns its result. It returns an error
+	// matching ErrKeyMismatch when the proof was generated for another key.
+	VerifyWithKey(ctx context.Context, key json.RawMessage, proof VcAndDiscloseProof, publicSignals []string, userContextData string) (*VerificationResult, error)
+}
+
+// WithKeySource makes Verify check proofs of custom attestation types whose AttestationVerifier
+// implements KeyedAttestationVerifier with keys from source. Keys are cached and reloaded once
+// refreshInterval has passed, or at once when a proof fails with ErrKeyMismatch, at most every
+// 10 seconds; a refreshInterval <= 0 only reloads on a mismatch. When a reload fails, the cached
+// keys stay in use until the next reload is due. Concurrent verifications share a single reload.
+//
+// Rotate keys by publishing them to the source, e.g. a file or URL; no redeploy is needed.
+func WithKeySource(source KeySource, refreshInterval time.Duration) VerifierOption {
+	return func(s *BackendVerifier) {
+		s.keyCache = &keyCache{source: source, refreshInterval: refreshInterval, now: time.Now}
+	}
+}
+
+// FileKeySource returns a KeySource reading VerificationKeys as JSON from the file at path on every load
+func FileKeySource(path string) KeySource {
+	return KeySourceFunc(func(ctx context.Context) (VerificationKeys, error) {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open verification keys: %v", err)
+		}
+		defer file.Close()
+		return decodeVerificationKeys(file)
+	})
+}
+
+// EmbeddedKeySource returns a KeySource serving VerificationKeys encoded as JSON in data, e.g. a file
+// embedded in the binary with go:embed. The keys are decoded once; a decoding error is returned by
+// every load.
+func EmbeddedKeySource(data []byte) KeySource {
+	keys, err := decodeVerificationKeys(bytes.NewReader(data))
+	return KeySourceFunc(func(ctx context.Context) (VerificationKeys, error) {
+		return keys, err
+	})
+}
+
+// URLKeySource returns a KeySource fetching VerificationKeys as JSON from url on every load.
+// httpClient may be nil to use http.DefaultClient.
+func URLKeySource(url string, httpClient *http.Client) KeySource {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return KeySourceFunc(func(ctx context.Context) (VerificationKeys, error) {
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		response, err := httpClient.Do(request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch verification keys: %v", err)
+		}
+		defer response.Body.Close()
+		if response.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to fetch verification keys: %s", response.Status)
+		}
+		return decodeVerificationKeys(response.Body)
+	})
+}
+
+// decodeVerificationKeys decodes VerificationKeys from at most maxKeySourceSize bytes of r
+func decodeVerificationKeys(r io.Reader) (VerificationKeys, error) {
+	var keys VerificationKeys
+	if err := json.NewDecoder(io.LimitReader(r, maxKeySourceSize)).Decode(&keys); err != nil {
+		return nil, fmt.Errorf("failed to decode verification keys: %v", err)
+	}
+	return keys, nil
+}
+
+// minKeyRefreshInterval is the minimum time between reloads of the keys triggered by ErrKeyMismatch,
+// so proofs generated for unknown keys cannot make every verification hit the KeySource
+const minKeyRefreshInterval = 10 * time.Second
+
+// keyCache caches the keys of a KeySource. Keys are loaded outside the mutex and concurrent loads
+// are shared, so a slow source blocks only the verifications waiting for its keys.
+type keyCache struct {
+	source          KeySource
+	refreshInterval time.Duration
+	now             func() time.Time
+
+	mu          sync.Mutex
+	keys        VerificationKeys
+	loadedAt    time.Time
+	refreshedAt time.Time // Last reload triggered by ErrKeyMismatch
+	loading     *keyLoad
+}
+
+// keyLoad is a load of the keys in progress, shared by every caller needing it
+type keyLoad struct {
+	done chan struct{}
+	err  error
+}
+
+// key returns the key for attestationId, loading the keys first if none are cached or
+// they are older than the refresh interval. Stale keys are used while another caller reloads them.
+func (c *keyCache) key(ctx context.Context, attestationId AttestationId) (json.RawMessage, error) {
+	c.mu.Lock()
+	stale := c.refreshInterval > 0 && c.now().Sub(c.loadedAt) >= c.refreshInterval
+	needsLoad := c.keys == nil || (stale && c.loading == nil)
+	c.mu.Unlock()
+
+	if needsLoad {
+		if err := c.load(ctx); err != nil && !c.hasKeys() {
+			return nil, err
+		}
+	}
+	return c.lookup(attestationId)
+}
+
+// refresh reloads the keys and returns the key for attestationId. Within minKeyRefreshInterval of
+// the previous refresh it only waits for a load in progress and returns the cached key.
+func (c *keyCache) refresh(ctx context.Context, attestationId AttestationId) (json.RawMessage, error) {
+	c.mu.Lock()
+	now := c.now()
+	if !c.refreshedAt.IsZero() && now.Sub(c.refreshedAt) < minKeyRefreshInterval {
+		loading := c.loading
+		c.mu.Unlock()
+		if loading != nil {
+			if err := loading.wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+		return c.lookup(attestationId)
+	}
+	c.refreshedAt = now
+	c.mu.Unlock()
+
+	if err := c.load(ctx); err != nil {
+		return nil, err
+	}
+	return c.lookup(attestationId)
+}
+
+// load loads the keys from the source, or waits for the load in progress. When it fails the cached
+// keys stay in use until the refresh interval passes again, so an unavailable source is not
+// retried by every verification.
+func (c *keyCache) load(ctx context.Context) error {
+	c.mu.Lock()
+	if loading := c.loading; loading != nil {
+		c.mu.Unlock()
+		return loading.wait(ctx)
+	}
+	loading := &keyLoad{done: make(chan struct{})}
+	c.loading = loading
+	c.mu.Unlock()
+
+	keys, err := c.source.LoadKeys(ctx)
+
+	c.mu.Lock()
+	if err != nil {
+		loading.err = fmt.Errorf("failed to load verification keys: %w", err)
+	} else {
+		if keys == nil {
+			keys = VerificationKeys{}
+		}
+		c.keys = keys
+	}
+	c.loadedAt = c.now()
+	c.loading = nil
+	c.mu.Unlock()
+	close(loading.done)
+	return loading.err
+}
+
+// wait waits for the load to finish and returns its error
+func (l *keyLoad) wait(ctx context.Context) error {
+	select {
+	case <-l.done:
+		return l.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// hasKeys reports whether keys have been loaded
+func (c *keyCache) hasKeys() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.keys != nil
+}
+
+// lookup returns the cached key for attestationId
+func (c *keyCache) lookup(attestationId AttestationId) (json.RawMessage, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key, ok := c.keys[attestationId]
+	if !ok {
+		return nil, fmt.Errorf("%w: attestation ID %d", ErrKeyNotFound, attestationId)
+	}
+	return key, nil
+}
+
+// verifyWithKeySource verifies a proof with the keyed verifier v and a key from the verifier's
+// KeySource, refreshing the keys and retrying once when the proof was generated for another key
+func (s *BackendVerifier) verifyWithKeySource(
+	ctx context.Context,
+	v KeyedAttestationVerifier,
+	attestationId AttestationId,
+	proof VcAndDiscloseProof,
+	publicSignals []string,
+	userContextData string,
+) (*VerificationResult, error) {
+	key, err := s.keyCache.key(ctx, attestationId)
+	if err != nil {
+		return nil, err
+	}
+	result, err := v.VerifyWithKey(ctx, key, proof, publicSignals, userContextData)
+	if !errors.Is(err, ErrKeyMismatch) {
+		return result, err
+	}
+
+	s.logger.Printf("self: attestation %d proof does not match the cached verification key, refreshing keys", attestationId)
+	refreshed, refreshErr := s.keyCache.refresh(ctx, attestationId)
+	if refreshErr != nil {
+		return nil, fmt.Errorf("%w (refresh failed: %v)", err, refreshErr)
+	}
+	if bytes.Equal(refreshed, key) {
+		// Retrying with the same key cannot succeed
+		return nil, err
+	}
+	return v.VerifyWithKey(ctx, refreshed, proof, publicSignals, userContextData)
+}
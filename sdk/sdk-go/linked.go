@@ -0,0 +1,185 @@
+package self
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ErrLinkedRuleViolation is returned when linked proofs verify individually but break a cross-proof rule
+var ErrLinkedRuleViolation = errors.New("linked proofs violate verification rules")
+
+// ErrIdentityMismatch is returned by VerifySamePerson when the proofs cannot be bound to one person
+var ErrIdentityMismatch = errors.New("proofs belong to different people")
+
+// SamePersonFields are the disclosures VerifySamePerson compares to bind proofs to one person
+var SamePersonFields = []DisclosureField{DisclosureName, DisclosureDateOfBirth}
+
+// LinkedRules are the cross-proof constraints enforced by VerifyLinked
+type LinkedRules struct {
+	// SubjectMaximumAge requires the subject to be younger than this age (0 to skip).
+	// The subject's proof must disclose the date of birth.
+	SubjectMaximumAge int
+	// GuardianMinimumAge requires the guardian to be at least this age (0 to skip).
+	// Satisfied by the guardian's proven minimum age or disclosed date of birth.
+	GuardianMinimumAge int
+	// AllowDifferentUserIdentifiers accepts proofs bound to different user identifiers.
+	// By default both proofs must be generated for the same user identifier (the same session).
+	AllowDifferentUserIdentifiers bool
+}
+
+// DefaultGuardianRules returns rules for a minor presenting alongside an adult guardian
+func DefaultGuardianRules() LinkedRules {
+	return LinkedRules{
+		SubjectMaximumAge:  18,
+		GuardianMinimumAge: 18,
+	}
+}
+
+// LinkedResult is the combined result of verifying several linked proofs
+type LinkedResult struct {
+	// Results holds the individual results in input order
+	Results []*VerificationResult `json:"results"`
+	// IsValid is true when every proof is valid and all cross-proof rules hold
+	IsValid bool `json:"isValid"`
+}
+
+// VerifyLinked verifies a subject's proof together with a guardian's proof and enforces rules across them.
+//
+// Each proof goes through the same checks as Verify. The two proofs must come from different
+// people (distinct nullifiers). Results are returned in the order subject, guardian.
+//
+// Returns:
+//   - A LinkedResult, with IsValid false if either proof is not valid
+//   - An error if either verification fails, or ErrLinkedRuleViolation if a cross-proof rule is broken
+func (s *BackendVerifier) VerifyLinked(
+	ctx context.Context,
+	subjectInput VerifyInput,
+	guardianInput VerifyInput,
+	rules LinkedRules,
+) (*LinkedResult, error) {
+	subject, err := s.verifyInput(ctx, subjectInput)
+	if err != nil {
+		return nil, fmt.Errorf("subject proof: %w", err)
+	}
+	guardian, err := s.verifyInput(ctx, guardianInput)
+	if err != nil {
+		return nil, fmt.Errorf("guardian proof: %w", err)
+	}
+
+	linked := &LinkedResult{Results: []*VerificationResult{subject, guardian}}
+	if !subject.IsValidDetails.IsValid || !guardian.IsValidDetails.IsValid {
+		return linked, nil
+	}
+
+	if subject.DiscloseOutput.Nullifier == guardian.DiscloseOutput.Nullifier {
+		return nil, fmt.Errorf("%w: subject and guardian proofs belong to the same person", ErrLinkedRuleViolation)
+	}
+	if !rules.AllowDifferentUserIdentifiers && subject.UserData.UserIdentifier != guardian.UserData.UserIdentifier {
+		return nil, fmt.Errorf("%w: subject and guardian proofs are bound to different user identifiers", ErrLinkedRuleViolation)
+	}
+
+	now := time.Now().UTC()
+	if rules.SubjectMaximumAge > 0 {
+		dateOfBirth, ok := parseDateOfBirth(subject.AttestationId, subject.DiscloseOutput.DateOfBirth, now)
+		if !ok {
+			return nil, fmt.Errorf("%w: subject proof does not disclose a date of birth", ErrLinkedRuleViolation)
+		}
+		if ageAt(dateOfBirth, now) >= rules.SubjectMaximumAge {
+			return nil, fmt.Errorf("%w: subject is not younger than %d", ErrLinkedRuleViolation, rules.SubjectMaximumAge)
+		}
+	}
+
+	if rules.GuardianMinimumAge > 0 && !hasMinimumAge(guardian, rules.GuardianMinimumAge, now) {
+		return nil, fmt.Errorf("%w: guardian is not at least %d", ErrLinkedRuleViolation, rules.GuardianMinimumAge)
+	}
+
+	linked.IsValid = true
+	return linked, nil
+}
+
+// VerifySamePerson verifies several proofs, e.g. two documents for step-up verification, and asserts
+// they belong to the same person. Nullifiers are derived per document, so two documents of one
+// person never share them; instead every proof must be bound to the same user identifier (the same
+// session) and disclose matching SamePersonFields, compared as by GenericDiscloseOutput.Matches.
+// The proofs must also be distinct: a request or nullifier presented twice proves only one document.
+//
+// Each proof goes through the same checks as Verify. Results are returned in input order.
+//
+// Returns:
+//   - A LinkedResult, with IsValid false if any proof is not valid
+//   - An error if any verification fails, or ErrIdentityMismatch if the proofs cannot be bound to one person
+func (s *BackendVerifier) VerifySamePerson(ctx context.Context, inputs []VerifyInput) (*LinkedResult, error) {
+	if len(inputs) < 2 {
+		return nil, fmt.Errorf("%w: at least two proofs are required, got %d", ErrInvalidRequest, len(inputs))
+	}
+
+	cacheKeys := make(map[string]int, len(inputs))
+	for i, input := range inputs {
+		key := ResultCacheKey(input)
+		if j, seen := cacheKeys[key]; seen {
+			return nil, fmt.Errorf("%w: proof %d repeats proof %d", ErrIdentityMismatch, i, j)
+		}
+		cacheKeys[key] = i
+	}
+
+	linked := &LinkedResult{Results: make([]*VerificationResult, 0, len(inputs))}
+	for i, input := range inputs {
+		result, err := s.verifyInput(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("proof %d: %w", i, err)
+		}
+		linked.Results = append(linked.Results, result)
+	}
+	for _, result := range linked.Results {
+		if !result.IsValidDetails.IsValid {
+			return linked, nil
+		}
+	}
+
+	first := linked.Results[0]
+	nullifiers := make(map[string]int, len(linked.Results))
+	for i, result := range linked.Results {
+		if nullifier := result.DiscloseOutput.Nullifier; nullifier != "" {
+			if j, seen := nullifiers[nullifier]; seen {
+				return nil, fmt.Errorf("%w: proof %d has the same nullifier as proof %d", ErrIdentityMismatch, i, j)
+			}
+			nullifiers[nullifier] = i
+		}
+		for _, field := range SamePersonFields {
+			if result.DiscloseOutput.comparableValue(field) == "" {
+				return nil, fmt.Errorf("%w: proof %d does not disclose %s", ErrIdentityMismatch, i, field)
+			}
+		}
+		if i == 0 {
+			continue
+		}
+		if result.UserData.UserIdentifier != first.UserData.UserIdentifier {
+			return nil, fmt.Errorf("%w: proof %d is bound to a different user identifier", ErrIdentityMismatch, i)
+		}
+		if matches, differing := first.DiscloseOutput.Matches(result.DiscloseOutput, SamePersonFields...); !matches {
+			return nil, fmt.Errorf("%w: proof %d discloses a different %s", ErrIdentityMismatch, i, differing[0])
+		}
+	}
+
+	linked.IsValid = true
+	return linked, nil
+}
+
+// verifyInput runs Verify on a VerifyInput, passing its disclosure salts in the context
+func (s *BackendVerifier) verifyInput(ctx context.Context, input VerifyInput) (*VerificationResult, error) {
+	ctx = contextWithInputSalts(ctx, input)
+	return s.Verify(ctx, input.AttestationId, input.Proof, input.PublicSignals, input.UserContextData)
+}
+
+// hasMinimumAge reports whether a result proves the holder is at least minimumAge, from the
+// proven minimum age or, failing that, the disclosed date of birth
+func hasMinimumAge(result *VerificationResult, minimumAge int, now time.Time) bool {
+	if provenAge, err := strconv.Atoi(result.DiscloseOutput.MinimumAge); err == nil && provenAge >= minimumAge {
+		return true
+	}
+	dateOfBirth, ok := parseDateOfBirth(result.AttestationId, result.DiscloseOutput.DateOfBirth, now)
+	return ok && ageAt(dateOfBirth, now) >= minimumAge
+}
@@ -0,0 +1,71 @@
+package self
+
+import (
+	"context"
+	"sort"
+)
+
+// DefaultListLimit is the page size used by ListRequest when Limit is zero
+const DefaultListLimit = 100
+
+// ListRequest selects a page of a ConfigLister listing
+type ListRequest struct {
+	// Cursor is the NextCursor of the previous page, empty for the first page
+	Cursor string
+	// Limit is the maximum number of entries returned (DefaultListLimit when zero)
+	Limit int
+}
+
+// ConfigEntry is a stored verification config with its ID
+type ConfigEntry struct {
+	Id     string             `json:"id"`
+	Config VerificationConfig `json:"config"`
+}
+
+// ActionIdMapping records the config ID a userDefinedData value resolves to
+type ActionIdMapping struct {
+	UserDefinedData string `json:"userDefinedData"`
+	ActionId        string `json:"actionId"`
+}
+
+// ConfigPage is a page of stored configs, ordered by ID
+type ConfigPage struct {
+	Configs []ConfigEntry `json:"configs"`
+	// NextCursor fetches the next page, empty on the last page
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// ActionIdPage is a page of action ID mappings, ordered by userDefinedData
+type ActionIdPage struct {
+	Mappings []ActionIdMapping `json:"mappings"`
+	// NextCursor fetches the next page, empty on the last page
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// ConfigLister is an optional ConfigStore extension for stores that can enumerate their contents,
+// e.g. for an admin dashboard. Action IDs computed by a function rather than stored are not listed.
+type ConfigLister interface {
+	// ListConfigs returns a page of stored configs
+	ListConfigs(ctx context.Context, request ListRequest) (ConfigPage, error)
+	// ListActionIds returns a page of stored action ID mappings
+	ListActionIds(ctx context.Context, request ListRequest) (ActionIdPage, error)
+}
+
+// pageKeys returns the page of keys after request.Cursor in sorted order, and the cursor of the next page
+func pageKeys(keys []string, request ListRequest) ([]string, string) {
+	limit := request.Limit
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+
+	sort.Strings(keys)
+	start := sort.SearchStrings(keys, request.Cursor)
+	if request.Cursor != "" && start < len(keys) && keys[start] == request.Cursor {
+		start++
+	}
+	end := start + limit
+	if end >= len(keys) {
+		return keys[start:], ""
+	}
+	return keys[start:end], keys[end-1]
+}
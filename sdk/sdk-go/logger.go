@@ -0,0 +1,13 @@
+package self
+
+// Logger is the logging interface used by the SDK; *log.Logger satisfies it.
+//
+// The SDK never logs raw userContextData, it is passed through RedactUserContextData first.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// nopLogger discards all log output
+type nopLogger struct{}
+
+func (nopLogger) Printf(format string, v ...interface{}) {}
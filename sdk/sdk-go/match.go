@@ -0,0 +1,74 @@
+package self
+
+import (
+	"strings"
+	"time"
+)
+
+// Matches reports whether o and other hold the same values for the given fields, and returns the
+// fields that differ in document order. It supports re-verification flows that check a new proof
+// against the attributes stored when the user signed up.
+//
+// Values are compared after normalization: names with NormalizedName, ignoring case, and dates as
+// calendar dates, so a date stored as ISO 8601 matches the same date disclosed as YYMMDD or
+// YYYYMMDD. Other values are compared ignoring surrounding whitespace and case. A field missing
+// from either output differs. With no fields, every field either output holds a value for is
+// compared.
+func (o GenericDiscloseOutput) Matches(other GenericDiscloseOutput, fields ...DisclosureField) (bool, []DisclosureField) {
+	if len(fields) == 0 {
+		for _, field := range AllDisclosureFields {
+			if o.comparableValue(field) != "" || other.comparableValue(field) != "" {
+				fields = append(fields, field)
+			}
+		}
+	}
+
+	differing := make(DisclosureSet)
+	for _, field := range fields {
+		value := o.comparableValue(field)
+		if value == "" || !strings.EqualFold(value, other.comparableValue(field)) {
+			differing[field] = true
+		}
+	}
+	return len(differing) == 0, differing.Fields()
+}
+
+// comparableValue returns the normalized value of field, or "" when it holds none
+func (o GenericDiscloseOutput) comparableValue(field DisclosureField) string {
+	switch field {
+	case DisclosureName:
+		return o.NormalizedName()
+	case DisclosureDateOfBirth:
+		return comparableDate(o.DateOfBirthISO, o.fieldValue(field), func(value string) (time.Time, bool) {
+			if len(value) == 8 {
+				return parseDigitsDate(value, 8, time.Now())
+			}
+			return parseDigitsDate(value, 6, time.Now())
+		})
+	case DisclosureExpiryDate:
+		return comparableDate(o.ExpiryDateISO, o.fieldValue(field), func(value string) (time.Time, bool) {
+			if len(value) == 6 {
+				return parseExpiryDate(value)
+			}
+			return parseDigitsDate(value, 8, time.Time{})
+		})
+	default:
+		return strings.TrimSpace(o.fieldValue(field))
+	}
+}
+
+// comparableDate returns a disclosed date as ISO 8601, preferring the ISO variant already set on
+// the output. Values that are not digit dates are returned trimmed, to be compared as they are.
+func comparableDate(iso, value string, parse func(value string) (time.Time, bool)) string {
+	if iso != "" {
+		return iso
+	}
+	value = strings.TrimSpace(value)
+	if date, ok := parse(value); ok {
+		return date.Format(isoDateLayout)
+	}
+	if date, err := time.Parse(isoDateLayout, value); err == nil {
+		return date.Format(isoDateLayout)
+	}
+	return value
+}
@@ -0,0 +1,92 @@
+package self
+
+import (
+	"net/http"
+	"strings"
+)
+
+// DefaultLocale is the locale MessageCatalog falls back to
+const DefaultLocale = "en"
+
+// MessageCatalog maps locales (e.g. "en", "pt-BR") to user-facing messages for each ErrorCode.
+// Error codes are stable, so catalogs maintained outside the SDK keep working across releases.
+type MessageCatalog map[string]map[ErrorCode]string
+
+// DefaultMessages returns a catalog with English messages for every ErrorCode, to extend with translations
+func DefaultMessages() MessageCatalog {
+	return MessageCatalog{
+		DefaultLocale: {
+			ErrorCodeInvalidRequest:            "The verification request is invalid.",
+			ErrorCodeInvalidProof:              "The proof could not be verified.",
+			ErrorCodeAttestationNotAllowed:     "This document type is not accepted.",
+			ErrorCodeUnknownAttestationType:    "This document type is not supported.",
+			ErrorCodeUnsupportedCircuitVersion: "Please update the Self app and try again.",
+			ErrorCodeInvalidPublicSignals:      "The proof is malformed.",
+			ErrorCodeInvalidAttestationId:      "The proof does not match the document type.",
+			ErrorCodeScopeMismatch:             "The proof was generated for another application.",
+			ErrorCodeUserContextMismatch:       "The proof was generated for another request.",
+			ErrorCodeContextDataSignature:      "The request could not be authenticated.",
+			ErrorCodeInvalidRoot:               "The document is not registered.",
+			ErrorCodeInvalidTimestamp:          "The proof has expired. Please try again.",
+			ErrorCodeProofTooOld:               "The proof has expired. Please try again.",
+			ErrorCodeProofFromFuture:           "The proof is dated in the future. Please check your device clock.",
+			ErrorCodeProofPredatesSession:      "The proof was generated before this request. Please try again.",
+			ErrorCodeConfigNotFound:            "This verification request is not configured.",
+			ErrorCodeAgeNotMet:                 "You do not meet the minimum age requirement.",
+			ErrorCodeCountryExcluded:           "Your country is not supported.",
+			ErrorCodeOfacHit:                   "You could not be verified against sanctions lists.",
+			ErrorCodeSanctionedNationality:     "Your nationality is not supported.",
+			ErrorCodeOverDisclosure:            "The proof shares more information than requested.",
+			ErrorCodeIssuingStateNotAllowed:    "Documents from this issuing country are not accepted.",
+			ErrorCodeNationalityIssuerMismatch: "Your document must be issued by your country of nationality.",
+			ErrorCodeMissingBiometric:          "This document type is not accepted.",
+			ErrorCodeDocumentSubtypeNotAllowed: "This type of passport is not accepted.",
+			ErrorCodeIssuedAsMinor:             "Documents issued to minors are not accepted.",
+			ErrorCodeInconsistentDisclosure:    "The proof is malformed.",
+			ErrorCodeSaltMismatch:              "The disclosed information could not be verified.",
+			ErrorCodeMissingDisclosure:         "The proof does not share the requested information.",
+			ErrorCodePlaceholderData:           "The proof contains test data.",
+			ErrorCodeLinkedRuleViolation:       "Your documents do not meet the requirements.",
+			ErrorCodeIdentityMismatch:          "Your documents do not belong to the same person.",
+			ErrorCodeVerificationTimeout:       "Verification took too long. Please try again.",
+			ErrorCodeVerificationBudget:        "Verification took too long. Please try again.",
+			ErrorCodeConfigStoreTimeout:        "Verification is temporarily unavailable. Please try again.",
+			ErrorCodeInternal:                  "Something went wrong. Please try again.",
+			ErrorCodeUnauthorized:              "You are not authorized to perform this action.",
+		},
+	}
+}
+
+// Message returns the message for code in locale, falling back to the locale's base language
+// ("pt" for "pt-BR"), then DefaultLocale, then the code itself
+func (c MessageCatalog) Message(code ErrorCode, locale string) string {
+	candidates := []string{locale}
+	if base, _, found := strings.Cut(locale, "-"); found {
+		candidates = append(candidates, base)
+	}
+	candidates = append(candidates, DefaultLocale)
+	for _, candidate := range candidates {
+		if message, ok := c[candidate][code]; ok {
+			return message
+		}
+	}
+	return string(code)
+}
+
+// Localize returns response with its message replaced by the catalog's message in locale.
+// The code and field errors are unchanged.
+func (c MessageCatalog) Localize(response ErrorResponse, locale string) ErrorResponse {
+	response.Message = c.Message(response.Code, locale)
+	return response
+}
+
+// requestLocale returns the first language of the request's Accept-Language header, or DefaultLocale
+func requestLocale(r *http.Request) string {
+	first, _, _ := strings.Cut(r.Header.Get("Accept-Language"), ",")
+	locale, _, _ := strings.Cut(first, ";")
+	locale = strings.TrimSpace(locale)
+	if locale == "" || locale == "*" {
+		return DefaultLocale
+	}
+	return locale
+}
@@ -0,0 +1,205 @@
+package self
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Middleware wraps an http.Handler with additional behaviour
+type Middleware func(http.Handler) http.Handler
+
+// Chain wraps handler with the given middleware, the first middleware being the outermost
+func Chain(handler http.Handler, middleware ...Middleware) http.Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
+
+// CORSConfig controls the headers written by CORSMiddleware
+type CORSConfig struct {
+	// AllowedOrigins lists the origins allowed to call the server; "*" allows any origin
+	AllowedOrigins []string
+	// AllowedMethods lists the methods advertised on preflight requests
+	AllowedMethods []string
+	// AllowedHeaders lists the request headers advertised on preflight requests
+	AllowedHeaders []string
+	// MaxAge is how long browsers may cache a preflight response (0 to omit)
+	MaxAge time.Duration
+}
+
+// DefaultCORSConfig returns a CORS configuration suitable for the verify endpoint
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{http.MethodPost, http.MethodOptions},
+		AllowedHeaders: []string{"Content-Type"},
+	}
+}
+
+// CORSMiddleware sets CORS headers when the request Origin is allowed and answers preflight requests.
+// Requests from origins that are not allowed are passed through without CORS headers.
+func CORSMiddleware(config CORSConfig) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			allowedOrigin, ok := config.allowedOrigin(origin)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header := w.Header()
+			header.Set("Access-Control-Allow-Origin", allowedOrigin)
+			if allowedOrigin != "*" {
+				header.Add("Vary", "Origin")
+			}
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				header.Set("Access-Control-Allow-Methods", strings.Join(config.AllowedMethods, ", "))
+				header.Set("Access-Control-Allow-Headers", strings.Join(config.AllowedHeaders, ", "))
+				if config.MaxAge > 0 {
+					header.Set("Access-Control-Max-Age", strconv.Itoa(int(config.MaxAge.Seconds())))
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// allowedOrigin returns the value to use for Access-Control-Allow-Origin, if the origin is allowed
+func (c CORSConfig) allowedOrigin(origin string) (string, bool) {
+	if origin == "" {
+		return "", false
+	}
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" {
+			return "*", true
+		}
+		if strings.EqualFold(allowed, origin) {
+			return origin, true
+		}
+	}
+	return "", false
+}
+
+// SecurityHeadersConfig controls the headers written by SecurityHeadersMiddleware
+type SecurityHeadersConfig struct {
+	// Disabled turns the middleware into a pass-through, e.g. for local development
+	Disabled bool
+	// ContentTypeOptions is the X-Content-Type-Options value ("" to omit)
+	ContentTypeOptions string
+	// ReferrerPolicy is the Referrer-Policy value ("" to omit)
+	ReferrerPolicy string
+	// FrameOptions is the X-Frame-Options value ("" to omit)
+	FrameOptions string
+	// HSTSMaxAge enables Strict-Transport-Security when greater than zero.
+	// Only enable it when the server is exclusively reachable over HTTPS.
+	HSTSMaxAge time.Duration
+	// HSTSIncludeSubdomains adds includeSubDomains to the Strict-Transport-Security header
+	HSTSIncludeSubdomains bool
+}
+
+// DefaultSecurityHeadersConfig returns conservative defaults for an API server.
+// HSTS is left off since it must only be sent by HTTPS deployments.
+func DefaultSecurityHeadersConfig() SecurityHeadersConfig {
+	return SecurityHeadersConfig{
+		ContentTypeOptions: "nosniff",
+		ReferrerPolicy:     "no-referrer",
+		FrameOptions:       "DENY",
+	}
+}
+
+// SecurityHeadersMiddleware sets the configured security headers on every response
+func SecurityHeadersMiddleware(config SecurityHeadersConfig) Middleware {
+	return func(next http.Handler) http.Handler {
+		if config.Disabled {
+			return next
+		}
+
+		var hsts string
+		if config.HSTSMaxAge > 0 {
+			hsts = fmt.Sprintf("max-age=%d", int(config.HSTSMaxAge.Seconds()))
+			if config.HSTSIncludeSubdomains {
+				hsts += "; includeSubDomains"
+			}
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := w.Header()
+			if config.ContentTypeOptions != "" {
+				header.Set("X-Content-Type-Options", config.ContentTypeOptions)
+			}
+			if config.ReferrerPolicy != "" {
+				header.Set("Referrer-Policy", config.ReferrerPolicy)
+			}
+			if config.FrameOptions != "" {
+				header.Set("X-Frame-Options", config.FrameOptions)
+			}
+			if hsts != "" {
+				header.Set("Strict-Transport-Security", hsts)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequestIDHeader is the response header carrying the request id generated by LoggingMiddleware
+const RequestIDHeader = "X-Request-ID"
+
+// LoggingMiddleware logs one line per request with its method, path, status, duration and a
+// generated request id, also returned in the X-Request-ID header so clients can quote it.
+// Request bodies, query strings and headers are never logged since they carry proofs and personal data.
+func LoggingMiddleware(logger Logger) Middleware {
+	if logger == nil {
+		logger = nopLogger{}
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestId := newRequestId()
+			w.Header().Set(RequestIDHeader, requestId)
+
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			start := time.Now()
+			next.ServeHTTP(recorder, r)
+			logger.Printf("self: request %s %s %s %d %s", requestId, r.Method, r.URL.Path, recorder.status, time.Since(start))
+		})
+	}
+}
+
+// newRequestId returns a random 16 hex character request id
+func newRequestId() string {
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(id[:])
+}
+
+// statusRecorder records the status code written through an http.ResponseWriter
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap returns the underlying ResponseWriter, for http.ResponseController
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
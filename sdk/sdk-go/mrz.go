@@ -0,0 +1,184 @@
+package self
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrMRZChecksumMismatch is returned when a disclosed MRZ check digit does not match the disclosed field it covers
+var ErrMRZChecksumMismatch = errors.New("MRZ check digit does not match the disclosed data")
+
+// MRZComponents are the machine readable zone components underlying the disclosed document fields.
+// Components the proof did not disclose contain null bytes.
+type MRZComponents struct {
+	DocumentNumberCheckDigit string `json:"documentNumberCheckDigit"`
+	DateOfBirthCheckDigit    string `json:"dateOfBirthCheckDigit"`
+	ExpiryDateCheckDigit     string `json:"expiryDateCheckDigit"`
+	OptionalData             string `json:"optionalData"`
+	CompositeCheckDigit      string `json:"compositeCheckDigit"`
+	// CompositeData is the MRZ data covered by the composite check digit, in MRZ order
+	CompositeData string `json:"compositeData"`
+}
+
+// mrzLayout locates the MRZ components in the revealed data of a document type
+type mrzLayout struct {
+	documentNumberCheck int
+	dateOfBirthCheck    int
+	expiryDateCheck     int
+	optionalData        [][2]int
+	compositeCheck      int
+	compositeData       [][2]int
+}
+
+// mrzLayouts holds the MRZ layout of each MRZ-based attestation type (inclusive byte ranges)
+var mrzLayouts = map[AttestationId]mrzLayout{
+	// TD3 (passport): two lines of 44 characters
+	Passport: {
+		documentNumberCheck: 53,
+		dateOfBirthCheck:    63,
+		expiryDateCheck:     71,
+		optionalData:        [][2]int{{72, 85}},
+		compositeCheck:      87,
+		compositeData:       [][2]int{{44, 53}, {57, 63}, {65, 86}},
+	},
+	// TD1 (ID card): three lines of 30 characters
+	EUCard: {
+		documentNumberCheck: 14,
+		dateOfBirthCheck:    36,
+		expiryDateCheck:     44,
+		optionalData:        [][2]int{{15, 29}, {48, 58}},
+		compositeCheck:      59,
+		compositeData:       [][2]int{{5, 29}, {30, 36}, {38, 44}, {48, 58}},
+	},
+}
+
+// parseMRZComponents extracts the MRZ components from revealed data, or returns nil for
+// attestation types without an MRZ
+func parseMRZComponents(attestationId AttestationId, revealedData []byte) *MRZComponents {
+	layout, ok := mrzLayouts[attestationId]
+	if !ok || len(revealedData) <= layout.compositeCheck {
+		return nil
+	}
+
+	span := func(ranges [][2]int) string {
+		var builder strings.Builder
+		for _, r := range ranges {
+			builder.Write(revealedData[r[0] : r[1]+1])
+		}
+		return builder.String()
+	}
+	return &MRZComponents{
+		DocumentNumberCheckDigit: string(revealedData[layout.documentNumberCheck]),
+		DateOfBirthCheckDigit:    string(revealedData[layout.dateOfBirthCheck]),
+		ExpiryDateCheckDigit:     string(revealedData[layout.expiryDateCheck]),
+		OptionalData:             span(layout.optionalData),
+		CompositeCheckDigit:      string(revealedData[layout.compositeCheck]),
+		CompositeData:            span(layout.compositeData),
+	}
+}
+
+// mrzLineLengths holds the MRZ line lengths of each MRZ-based attestation type, whose revealed data
+// starts with the MRZ lines in order
+var mrzLineLengths = map[AttestationId][]int{
+	Passport: {44, 44},
+	EUCard:   {30, 30, 30},
+}
+
+// ReconstructMRZLines rebuilds the MRZ lines of a passport or ID card from the revealed data of its
+// proof, for manual review. Characters the proof did not disclose are rendered as '?'. The lines
+// contain every disclosed attribute regardless of any config masking, so treat them as PII and
+// never log them. Attestation types without an MRZ return an error.
+func ReconstructMRZLines(attestationId AttestationId, publicSignals PublicSignals) ([]string, error) {
+	lengths, ok := mrzLineLengths[attestationId]
+	if !ok {
+		return nil, fmt.Errorf("attestation ID %d has no MRZ", attestationId)
+	}
+	revealedData, err := GetRevealedDataBytes(attestationId, publicSignals)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]string, 0, len(lengths))
+	offset := 0
+	for _, length := range lengths {
+		if len(revealedData) < offset+length {
+			return nil, fmt.Errorf("revealed data is too short for the MRZ of attestation ID %d", attestationId)
+		}
+		line := make([]byte, length)
+		for i, b := range revealedData[offset : offset+length] {
+			if b == 0 {
+				b = '?'
+			}
+			line[i] = byte(b)
+		}
+		lines = append(lines, string(line))
+		offset += length
+	}
+	return lines, nil
+}
+
+// personalNumber extracts the personal number from the first MRZ optional data field, without
+// its '<' filler, or returns "" for attestation types without an MRZ
+func personalNumber(attestationId AttestationId, revealedData []byte) string {
+	layout, ok := mrzLayouts[attestationId]
+	if !ok || len(layout.optionalData) == 0 || len(revealedData) <= layout.optionalData[0][1] {
+		return ""
+	}
+	optionalData := layout.optionalData[0]
+	return strings.Trim(removeNullBytes(string(revealedData[optionalData[0]:optionalData[1]+1])), "<")
+}
+
+// ValidateMRZ checks every disclosed MRZ check digit against the disclosed data it covers,
+// returning an error matching ErrMRZChecksumMismatch on the first inconsistency. Check digits
+// whose field or digit was not disclosed are skipped, as are outputs without MRZ components.
+func (o GenericDiscloseOutput) ValidateMRZ() error {
+	if o.MRZ == nil {
+		return nil
+	}
+
+	checks := []struct {
+		name  string
+		data  string
+		digit string
+	}{
+		{"document number", o.IdNumber, o.MRZ.DocumentNumberCheckDigit},
+		{"date of birth", o.DateOfBirth, o.MRZ.DateOfBirthCheckDigit},
+		{"expiry date", o.ExpiryDate, o.MRZ.ExpiryDateCheckDigit},
+		{"composite", o.MRZ.CompositeData, o.MRZ.CompositeCheckDigit},
+	}
+	for _, check := range checks {
+		if check.data == "" || strings.Contains(check.data, "\x00") || !isDisclosedValue(check.digit) {
+			continue
+		}
+		expected, ok := mrzCheckDigit(check.data)
+		if !ok {
+			return fmt.Errorf("%w: %s contains characters outside the MRZ alphabet", ErrMRZChecksumMismatch, check.name)
+		}
+		if check.digit != expected {
+			return fmt.Errorf("%w: %s check digit is %s, expected %s", ErrMRZChecksumMismatch, check.name, check.digit, expected)
+		}
+	}
+	return nil
+}
+
+// mrzCheckDigit computes the ICAO 9303 check digit of data
+func mrzCheckDigit(data string) (string, bool) {
+	weights := [3]int{7, 3, 1}
+	sum := 0
+	for i, char := range data {
+		var value int
+		switch {
+		case char >= '0' && char <= '9':
+			value = int(char - '0')
+		case char >= 'A' && char <= 'Z':
+			value = int(char-'A') + 10
+		case char == '<':
+			value = 0
+		default:
+			return "", false
+		}
+		sum += value * weights[i%3]
+	}
+	return fmt.Sprint(sum % 10), true
+}
@@ -0,0 +1,47 @@
+package self
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizedName returns the disclosed name in a canonical form for comparison and storage:
+// null bytes are removed, the name is put in Unicode normalization form NFC and runs of
+// whitespace are collapsed to a single space. Case and diacritics are preserved.
+func (o GenericDiscloseOutput) NormalizedName() string {
+	name := norm.NFC.String(removeNullBytes(o.Name))
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// TransliteratedName returns NormalizedName transliterated to ASCII, for matching names against
+// systems that only store Latin letters: diacritics are stripped ("José" becomes "Jose"),
+// ligatures and special letters are expanded ("Æ" becomes "AE", "ß" becomes "ss") and characters
+// without an ASCII equivalent are dropped. Case is preserved.
+func (o GenericDiscloseOutput) TransliteratedName() string {
+	var transliterated strings.Builder
+	for _, r := range norm.NFD.String(o.NormalizedName()) {
+		switch {
+		case r < unicode.MaxASCII:
+			transliterated.WriteRune(r)
+		case nameTransliterations[r] != "":
+			transliterated.WriteString(nameTransliterations[r])
+		}
+		// Combining marks left by the decomposition and other non-ASCII characters are dropped
+	}
+	return strings.Join(strings.Fields(transliterated.String()), " ")
+}
+
+// nameTransliterations maps letters that do not decompose into an ASCII base letter
+var nameTransliterations = map[rune]string{
+	'Æ': "AE", 'æ': "ae",
+	'Œ': "OE", 'œ': "oe",
+	'Ø': "O", 'ø': "o",
+	'Đ': "D", 'đ': "d",
+	'Ð': "D", 'ð': "d",
+	'Ł': "L", 'ł': "l",
+	'Þ': "TH", 'þ': "th",
+	'ß': "ss",
+	'ı': "i",
+}
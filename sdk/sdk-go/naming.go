@@ -0,0 +1,66 @@
+package self
+
+import "encoding/json"
+
+// FieldNaming renames the JSON keys of the credential subject in a VerifyResponse, so one backend can
+// serve clients expecting different conventions. It maps the default camelCase keys (e.g.
+// "dateOfBirth") to the keys written instead; unmapped keys are written unchanged, and a nil
+// FieldNaming writes the defaults.
+type FieldNaming map[string]string
+
+// CamelCaseNaming returns the default naming, matching the TypeScript SDK (e.g. "dateOfBirth")
+func CamelCaseNaming() FieldNaming {
+	return nil
+}
+
+// SnakeCaseNaming returns a naming that writes snake_case keys (e.g. "date_of_birth")
+func SnakeCaseNaming() FieldNaming {
+	return FieldNaming{
+		"issuingState":   "issuing_state",
+		"idNumber":       "id_number",
+		"dateOfBirth":    "date_of_birth",
+		"expiryDate":     "expiry_date",
+		"minimumAge":     "minimum_age",
+		"documentValid":  "document_valid",
+		"personalNumber": "personal_number",
+	}
+}
+
+// renameSubject returns subject as a JSON object with its keys renamed
+func (n FieldNaming) renameSubject(subject CredentialSubject) (map[string]json.RawMessage, error) {
+	encoded, err := json.Marshal(subject)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, err
+	}
+
+	renamed := make(map[string]json.RawMessage, len(fields))
+	for key, value := range fields {
+		if name, ok := n[key]; ok {
+			key = name
+		}
+		renamed[key] = value
+	}
+	return renamed, nil
+}
+
+// MarshalJSON writes the response with the credential subject keys renamed by its FieldNaming
+func (r VerifyResponse) MarshalJSON() ([]byte, error) {
+	// The alias type drops this method, avoiding infinite recursion
+	type verifyResponse VerifyResponse
+	if len(r.subjectNaming) == 0 {
+		return json.Marshal(verifyResponse(r))
+	}
+
+	subject, err := r.subjectNaming.renameSubject(r.CredentialSubject)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		verifyResponse
+		CredentialSubject map[string]json.RawMessage `json:"credentialSubject"`
+	}{verifyResponse(r), subject})
+}
@@ -0,0 +1,172 @@
+package self
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// OFACMode selects which sources must clear a user when VerificationConfig.Ofac is enabled
+type OFACMode string
+
+const (
+	// OFACModeProof trusts the OFAC checks computed in-circuit by the client (the default)
+	OFACModeProof OFACMode = "proof"
+	// OFACModeBackend requires the verifier's OFACScreener to clear the user
+	OFACModeBackend OFACMode = "backend"
+	// OFACModeBoth requires both the proof and the OFACScreener to clear the user
+	OFACModeBoth OFACMode = "both"
+)
+
+// OFACSource reports where a passing OFAC result came from
+type OFACSource string
+
+const (
+	OFACSourceNone    OFACSource = ""
+	OFACSourceProof   OFACSource = "proof"
+	OFACSourceBackend OFACSource = "backend"
+	OFACSourceBoth    OFACSource = "both"
+	// OFACSourceOverride reports a user failing OFAC who was cleared by compliance (see OFACOverrideStore)
+	OFACSourceOverride OFACSource = "override"
+)
+
+// OFACScreener screens a verified user against sanctions lists on the backend
+type OFACScreener interface {
+	// Screen returns true if the user is clear of sanctions lists
+	Screen(ctx context.Context, discloseOutput GenericDiscloseOutput) (bool, error)
+}
+
+// ofacMode returns the configured OFACMode, defaulting to OFACModeProof
+func (c VerificationConfig) ofacMode() OFACMode {
+	if c.OfacMode == "" {
+		return OFACModeProof
+	}
+	return c.OfacMode
+}
+
+// requiresBackendScreen reports whether the config needs an OFACScreener
+func (c VerificationConfig) requiresBackendScreen() bool {
+	mode := c.ofacMode()
+	return c.Ofac && (mode == OFACModeBackend || mode == OFACModeBoth)
+}
+
+// evaluateOfac combines the in-proof OFAC result with the backend screen as required by the config
+//
+// Returns:
+//   - Whether the user passed OFAC according to the config
+//   - The source(s) that cleared the user, OFACSourceNone if they did not pass
+//   - An error if the backend screen failed to run
+func (s *BackendVerifier) evaluateOfac(
+	ctx context.Context,
+	config VerificationConfig,
+	discloseOutput GenericDiscloseOutput,
+	proofClear bool,
+) (bool, OFACSource, error) {
+	if !config.Ofac {
+		return false, OFACSourceNone, nil
+	}
+
+	mode := config.ofacMode()
+	if mode == OFACModeProof {
+		if proofClear {
+			return true, OFACSourceProof, nil
+		}
+		return false, OFACSourceNone, nil
+	}
+	if config.TrustProofOfac && proofClear {
+		s.logger.Printf("self: OFAC mode %q: trusting the in-proof OFAC result, backend screen skipped", mode)
+		return true, OFACSourceProof, nil
+	}
+
+	if s.ofacScreener == nil {
+		return false, OFACSourceNone, fmt.Errorf("OFAC mode %q requires an OFACScreener", mode)
+	}
+	backendClear, err := s.ofacScreener.Screen(ctx, discloseOutput)
+	if err != nil {
+		return false, OFACSourceNone, fmt.Errorf("OFAC screening failed: %v", err)
+	}
+
+	switch {
+	case mode == OFACModeBackend && backendClear:
+		return true, OFACSourceBackend, nil
+	case mode == OFACModeBoth && backendClear && proofClear:
+		return true, OFACSourceBoth, nil
+	default:
+		return false, OFACSourceNone, nil
+	}
+}
+
+// OFACOverride records that compliance manually cleared a flagged holder
+type OFACOverride struct {
+	Nullifier string    `json:"nullifier"`
+	ClearedBy string    `json:"clearedBy,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	ClearedAt time.Time `json:"clearedAt,omitempty"`
+}
+
+// OFACOverrideStore holds the holders compliance cleared despite an OFAC hit, keyed by nullifier.
+// It is consulted only when a verification fails OFAC.
+type OFACOverrideStore interface {
+	// LookupOverride returns the override for nullifier, or nil if the holder was not cleared
+	LookupOverride(ctx context.Context, nullifier string) (*OFACOverride, error)
+}
+
+// applyOFACOverride returns the override clearing the holder of nullifier, or nil. Lookup failures
+// are logged and keep the OFAC hit.
+func (s *BackendVerifier) applyOFACOverride(ctx context.Context, nullifier string, userContextData string) *OFACOverride {
+	override, err := s.ofacOverrideStore.LookupOverride(ctx, nullifier)
+	if err != nil {
+		s.logger.Printf("self: OFAC override lookup failed for userContextData %s, keeping the OFAC hit: %v",
+			RedactUserContextData(userContextData), err)
+		return nil
+	}
+	if override != nil {
+		s.logger.Printf("self: OFAC hit overridden for userContextData %s, cleared by %q: %s",
+			RedactUserContextData(userContextData), override.ClearedBy, override.Reason)
+	}
+	return override
+}
+
+// InMemoryOFACOverrideStore keeps OFAC overrides in memory
+type InMemoryOFACOverrideStore struct {
+	mu        sync.RWMutex
+	overrides map[string]OFACOverride
+}
+
+// Compile-time check to ensure InMemoryOFACOverrideStore implements OFACOverrideStore interface
+var _ OFACOverrideStore = (*InMemoryOFACOverrideStore)(nil)
+
+// NewInMemoryOFACOverrideStore creates an InMemoryOFACOverrideStore holding overrides
+func NewInMemoryOFACOverrideStore(overrides ...OFACOverride) *InMemoryOFACOverrideStore {
+	store := &InMemoryOFACOverrideStore{overrides: make(map[string]OFACOverride)}
+	for _, override := range overrides {
+		store.Clear(override)
+	}
+	return store
+}
+
+// Clear adds or replaces the override for override.Nullifier
+func (store *InMemoryOFACOverrideStore) Clear(override OFACOverride) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.overrides[override.Nullifier] = override
+}
+
+// Revoke removes the override for nullifier
+func (store *InMemoryOFACOverrideStore) Revoke(nullifier string) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	delete(store.overrides, nullifier)
+}
+
+// LookupOverride returns a copy of the override for nullifier, or nil
+func (store *InMemoryOFACOverrideStore) LookupOverride(ctx context.Context, nullifier string) (*OFACOverride, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	override, ok := store.overrides[nullifier]
+	if !ok {
+		return nil, nil
+	}
+	return &override, nil
+}
@@ -0,0 +1,196 @@
+package self
+
+import "time"
+
+// Defaults for the proof timestamp window
+const (
+	DefaultMaxProofAge = 24 * time.Hour
+	DefaultFutureSkew  = 24 * time.Hour
+)
+
+// DefaultVerifyTimeout bounds a verification whose context has no deadline
+const DefaultVerifyTimeout = 30 * time.Second
+
+// VerifierOption configures optional behaviour of a BackendVerifier
+type VerifierOption func(*BackendVerifier)
+
+// WithLogger sets the logger used to report verification failures
+func WithLogger(logger Logger) VerifierOption {
+	return func(s *BackendVerifier) {
+		if logger != nil {
+			s.logger = logger
+		}
+	}
+}
+
+// WithRPCURL connects to the Celo node at url instead of the public endpoint selected by mockPassport,
+// e.g. a self-hosted node or a provider with higher rate limits. The hub address still follows mockPassport.
+func WithRPCURL(url string) VerifierOption {
+	return func(s *BackendVerifier) {
+		s.rpcURL = url
+	}
+}
+
+// WithMaxPublicSignals limits the public signals Verify accepts, and a VerifyHandler decodes, to n
+// (default DefaultMaxPublicSignals), so oversized arrays are rejected with ErrTooManyPublicSignals
+// before they are processed. n <= 0 keeps the default.
+func WithMaxPublicSignals(n int) VerifierOption {
+	return func(s *BackendVerifier) {
+		if n > 0 {
+			s.maxPublicSignals = n
+		}
+	}
+}
+
+// WithAuditSink records a VerificationAudit to sink after every call to Verify
+func WithAuditSink(sink AuditSink) VerifierOption {
+	return func(s *BackendVerifier) {
+		s.auditSink = sink
+	}
+}
+
+// WithOFACScreener sets the backend OFAC screen used by configs with OFACModeBackend or OFACModeBoth
+func WithOFACScreener(screener OFACScreener) VerifierOption {
+	return func(s *BackendVerifier) {
+		s.ofacScreener = screener
+	}
+}
+
+// WithOFACOverrideStore consults store when a verification fails OFAC, passing holders compliance
+// cleared with OFACSourceOverride and the override recorded on the result and audit record
+func WithOFACOverrideStore(store OFACOverrideStore) VerifierOption {
+	return func(s *BackendVerifier) {
+		s.ofacOverrideStore = store
+	}
+}
+
+// WithScoringFunc attaches an advisory VerificationScore, computed by fn, to every valid result
+func WithScoringFunc(fn ScoringFunc) VerifierOption {
+	return func(s *BackendVerifier) {
+		s.scoringFunc = fn
+	}
+}
+
+// WithMaxProofAge rejects proofs generated longer than d ago with ErrProofTooOld (default DefaultMaxProofAge).
+// Proofs are dated by day, so their age is measured from the end of their generation day.
+func WithMaxProofAge(d time.Duration) VerifierOption {
+	return func(s *BackendVerifier) {
+		s.maxProofAge = d
+	}
+}
+
+// WithFutureSkew rejects proofs dated more than d after now with ErrProofFromFuture (default DefaultFutureSkew)
+func WithFutureSkew(d time.Duration) VerifierOption {
+	return func(s *BackendVerifier) {
+		s.futureSkew = d
+	}
+}
+
+// WithVerifyTimeout bounds each call to Verify whose context has no deadline to d, after which it
+// fails with ErrVerificationTimeout (default DefaultVerifyTimeout, zero disables the timeout).
+// Callers override it per request by passing a context with its own deadline.
+func WithVerifyTimeout(d time.Duration) VerifierOption {
+	return func(s *BackendVerifier) {
+		s.verifyTimeout = d
+	}
+}
+
+// WithVerificationBudget caps the wall-clock time of the proof verification step, the verifier
+// contract call or a custom AttestationVerifier, at d (zero, the default, disables the budget).
+// A step exceeding it is aborted and the verification fails with ErrVerificationBudgetExceeded,
+// so a single expensive proof cannot tie up shared infrastructure. The time the step took is
+// reported in VerificationResult.ProofVerificationTime either way.
+func WithVerificationBudget(d time.Duration) VerifierOption {
+	return func(s *BackendVerifier) {
+		s.verificationBudget = d
+	}
+}
+
+// WithConfigStoreTimeout bounds each ConfigStore call made by Verify to d (zero, the default,
+// disables the timeout). A call that does not return in time fails the verification with
+// ErrConfigStoreTimeout unless WithConfigStoreFallback allows a fallback config.
+func WithConfigStoreTimeout(d time.Duration) VerifierOption {
+	return func(s *BackendVerifier) {
+		s.configStoreTimeout = d
+	}
+}
+
+// WithConfigStoreFallback verifies against config when a ConfigStore call times out, instead of
+// failing closed. Each fallback is logged.
+func WithConfigStoreFallback(config VerificationConfig) VerifierOption {
+	return func(s *BackendVerifier) {
+		s.configStoreFallback = &config
+	}
+}
+
+// WithAnonymousMode accepts proofs sent without userContextData, for flows such as age gates that
+// track no user. Such proofs are checked against the config with ID configId instead of one resolved
+// by GetActionId, with every policy check still enforced, and their result has no UserData. The user
+// context hash and any WithContextDataVerifier signature are not checked, so anonymous proofs are not
+// bound to a user or request: they carry no uniqueness or replay guarantees unless the application
+// rejects reused nullifiers (DiscloseOutput.Nullifier), e.g. with a nullifier store. Proofs sent with
+// userContextData are verified as usual.
+func WithAnonymousMode(configId string) VerifierOption {
+	return func(s *BackendVerifier) {
+		s.anonymousConfigId = configId
+	}
+}
+
+// WithPinnedRoots accepts proofs of attestationId only if they reference one of roots, checked
+// without querying the registry contract. Pin both the old and the new root while the registry
+// rotates, with ExpiresAt ending the old root's window. Each match is logged.
+func WithPinnedRoots(attestationId AttestationId, roots ...PinnedRoot) VerifierOption {
+	return func(s *BackendVerifier) {
+		if s.pinnedRoots == nil {
+			s.pinnedRoots = make(map[AttestationId][]PinnedRoot)
+		}
+		s.pinnedRoots[attestationId] = append(s.pinnedRoots[attestationId], roots...)
+	}
+}
+
+// WithDisclosureMode sets how proofs disclosing fields the config did not request are handled
+// (default DisclosureModeLenient, which masks them)
+func WithDisclosureMode(mode DisclosureMode) VerifierOption {
+	return func(s *BackendVerifier) {
+		s.disclosureMode = mode
+	}
+}
+
+// WithIncludeRawDisclosure sets VerificationResult.RawDiscloseOutput to the disclosure before masking,
+// for diagnosing masking problems. It exposes PII the config masks, so enabling it is logged and
+// recorded in audit records. Off by default.
+func WithIncludeRawDisclosure(include bool) VerifierOption {
+	return func(s *BackendVerifier) {
+		s.includeRawDisclosure = include
+	}
+}
+
+// WithIncludeMRZLines sets VerificationResult.MRZLines to the MRZ lines reconstructed from the proof,
+// for compliance teams manually reviewing cases automated checks leave inconclusive. It is a
+// break-glass option: the lines expose every disclosed attribute regardless of the config's masking,
+// so enabling it is logged and recorded in audit records. The lines are never serialized or logged.
+// Off by default.
+func WithIncludeMRZLines(include bool) VerifierOption {
+	return func(s *BackendVerifier) {
+		s.includeMRZLines = include
+	}
+}
+
+// WithReadOnly disables the write side effects of Verify, for dashboards and tools inspecting or
+// replaying verifications against production stores: no result is added to the ResultCache (cached
+// results are still answered) and no trace, audit record or outcome is recorded. Results are
+// unchanged. The verifier never writes to its ConfigStore or OFACOverrideStore, and marks no
+// nullifiers, so nothing else needs disabling. Off by default.
+func WithReadOnly(readOnly bool) VerifierOption {
+	return func(s *BackendVerifier) {
+		s.readOnly = readOnly
+	}
+}
+
+// WithContextDataVerifier requires userContextData to carry a valid signature from the backend,
+// rejecting proofs with tampered context data with ErrContextDataSignatureInvalid
+func WithContextDataVerifier(verifier ContextDataVerifier) VerifierOption {
+	return func(s *BackendVerifier) {
+		s.contextDataVerifier = &verifier
+	}
+}
@@ -0,0 +1,208 @@
+package self
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Outcome classifies a finished verification
+type Outcome string
+
+const (
+	// OutcomeValid is a proof that verified and passed every check
+	OutcomeValid Outcome = "valid"
+	// OutcomeInvalid is a proof that was rejected, with the reason in ErrorCode
+	OutcomeInvalid Outcome = "invalid"
+	// OutcomeError is a verification that failed internally or timed out, e.g. the RPC endpoint or the
+	// config store was unreachable
+	OutcomeError Outcome = "error"
+)
+
+// VerificationOutcome is the analytics event recorded after each verification. Unlike
+// VerificationAudit it carries no user identifier, nullifier or userContextData, only the
+// categories worth aggregating in a time-series database.
+type VerificationOutcome struct {
+	Timestamp     time.Time     `json:"timestamp"`
+	AttestationId AttestationId `json:"attestationId"`
+	Outcome       Outcome       `json:"outcome"`
+	ErrorCode     ErrorCode     `json:"errorCode,omitempty"`
+	ConfigId      string        `json:"configId,omitempty"`
+	Scope         string        `json:"scope,omitempty"`
+	// Country is the disclosed issuing state and Nationality the disclosed nationality, each empty
+	// when the proof did not disclose it or the config masks it. Rejected proofs report them once
+	// their config was found.
+	Country     string        `json:"country,omitempty"`
+	Nationality string        `json:"nationality,omitempty"`
+	Duration    time.Duration `json:"duration"`
+	// Cached is set when the result was answered from the ResultCache
+	Cached bool `json:"cached,omitempty"`
+}
+
+// OutcomeRecorder receives a VerificationOutcome after each verification. RecordOutcome runs on
+// the request path; wrap slow recorders in a BufferedOutcomeRecorder.
+type OutcomeRecorder interface {
+	RecordOutcome(ctx context.Context, outcome VerificationOutcome) error
+}
+
+// WithOutcomeRecorder records a VerificationOutcome to recorder after every call to Verify
+func WithOutcomeRecorder(recorder OutcomeRecorder) VerifierOption {
+	return func(s *BackendVerifier) {
+		s.outcomeRecorder = recorder
+	}
+}
+
+// recordOutcome builds the outcome of a finished verification and passes it to the outcome recorder.
+// Recorder failures are logged and never change the verification outcome.
+func (s *BackendVerifier) recordOutcome(
+	ctx context.Context,
+	attestationId AttestationId,
+	start time.Time,
+	state *verificationState,
+	result *VerificationResult,
+	err error,
+) {
+	outcome := VerificationOutcome{
+		Timestamp:     start.UTC(),
+		AttestationId: attestationId,
+		ConfigId:      state.configId,
+		Scope:         state.scope.name,
+		Duration:      time.Since(start),
+	}
+
+	switch {
+	case err != nil:
+		outcome.ErrorCode = ErrorCodeFor(err)
+		outcome.Outcome = OutcomeInvalid
+		if isServiceErrorCode(outcome.ErrorCode) {
+			outcome.Outcome = OutcomeError
+		}
+	case !result.IsValidDetails.IsValid:
+		outcome.Outcome = OutcomeInvalid
+		outcome.ErrorCode = ErrorCodeInvalidProof
+	default:
+		outcome.Outcome = OutcomeValid
+	}
+	outcome.Country, outcome.Nationality = state.issuingState, state.nationality
+	if result != nil {
+		outcome.Country = removeNullBytes(result.DiscloseOutput.IssuingState)
+		outcome.Nationality = string(documentNationality(attestationId, result.DiscloseOutput))
+		outcome.Cached = result.Cached
+	}
+
+	if recordErr := s.outcomeRecorder.RecordOutcome(ctx, outcome); recordErr != nil {
+		s.logger.Printf("self: failed to record verification outcome: %v", recordErr)
+	}
+}
+
+// NopOutcomeRecorder discards every outcome
+type NopOutcomeRecorder struct{}
+
+// Compile-time check to ensure NopOutcomeRecorder implements OutcomeRecorder interface
+var _ OutcomeRecorder = NopOutcomeRecorder{}
+
+// RecordOutcome does nothing
+func (NopOutcomeRecorder) RecordOutcome(ctx context.Context, outcome VerificationOutcome) error {
+	return nil
+}
+
+// MultiOutcomeRecorder records each outcome to every recorder in turn, e.g. a CountryMetrics next to
+// an analytics pipeline, returning their errors joined
+type MultiOutcomeRecorder []OutcomeRecorder
+
+// Compile-time check to ensure MultiOutcomeRecorder implements OutcomeRecorder interface
+var _ OutcomeRecorder = MultiOutcomeRecorder{}
+
+// RecordOutcome passes outcome to every recorder
+func (m MultiOutcomeRecorder) RecordOutcome(ctx context.Context, outcome VerificationOutcome) error {
+	var errs []error
+	for _, recorder := range m {
+		if err := recorder.RecordOutcome(ctx, outcome); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ErrOutcomeBufferFull is returned by BufferedOutcomeRecorder.RecordOutcome when the outcome was
+// dropped because the buffer is full or the recorder is closed
+var ErrOutcomeBufferFull = errors.New("outcome buffer is full, outcome dropped")
+
+// BufferedOutcomeRecorder queues outcomes and writes them to another recorder from a background
+// goroutine, so recording never blocks verification. When the buffer is full outcomes are dropped
+// rather than slowing down requests.
+type BufferedOutcomeRecorder struct {
+	recorder OutcomeRecorder
+	logger   Logger
+	outcomes chan VerificationOutcome
+	done     chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// Compile-time check to ensure BufferedOutcomeRecorder implements OutcomeRecorder interface
+var _ OutcomeRecorder = (*BufferedOutcomeRecorder)(nil)
+
+// NewBufferedOutcomeRecorder starts a BufferedOutcomeRecorder holding up to size outcomes (at least
+// 1) for recorder. Errors from recorder are logged to logger, which may be nil. Call Close to flush
+// the buffer on shutdown.
+func NewBufferedOutcomeRecorder(recorder OutcomeRecorder, size int, logger Logger) *BufferedOutcomeRecorder {
+	if size < 1 {
+		size = 1
+	}
+	if logger == nil {
+		logger = nopLogger{}
+	}
+	b := &BufferedOutcomeRecorder{
+		recorder: recorder,
+		logger:   logger,
+		outcomes: make(chan VerificationOutcome, size),
+		done:     make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// run writes queued outcomes to the underlying recorder until the buffer is closed and drained
+func (b *BufferedOutcomeRecorder) run() {
+	defer close(b.done)
+	for outcome := range b.outcomes {
+		if err := b.recorder.RecordOutcome(context.Background(), outcome); err != nil {
+			b.logger.Printf("self: failed to record verification outcome: %v", err)
+		}
+	}
+}
+
+// RecordOutcome queues outcome without blocking, or returns ErrOutcomeBufferFull when it is dropped
+func (b *BufferedOutcomeRecorder) RecordOutcome(ctx context.Context, outcome VerificationOutcome) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return ErrOutcomeBufferFull
+	}
+	select {
+	case b.outcomes <- outcome:
+		return nil
+	default:
+		return ErrOutcomeBufferFull
+	}
+}
+
+// Close stops accepting outcomes and waits until the queued ones are written or ctx is done
+func (b *BufferedOutcomeRecorder) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.outcomes)
+	}
+	b.mu.Unlock()
+
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
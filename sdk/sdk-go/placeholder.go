@@ -0,0 +1,75 @@
+package self
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrPlaceholderData is reported with WithPlaceholderRejection for proofs disclosing placeholder
+// values typical of test data
+var ErrPlaceholderData = errors.New("proof discloses placeholder data")
+
+// unixEpoch is the date of birth placeholder of synthetic documents
+var unixEpoch = time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// placeholderFields are the fields checked for placeholders, with their GenericDiscloseOutput JSON
+// names. The gender is not checked, as "<" legitimately means unspecified.
+var placeholderFields = []struct {
+	field DisclosureField
+	name  string
+	value func(GenericDiscloseOutput) string
+}{
+	{DisclosureIssuingState, "issuingState", func(o GenericDiscloseOutput) string { return o.IssuingState }},
+	{DisclosureName, "name", func(o GenericDiscloseOutput) string { return o.Name }},
+	{DisclosureDocumentNumber, "idNumber", func(o GenericDiscloseOutput) string { return o.IdNumber }},
+	{DisclosureNationality, "nationality", func(o GenericDiscloseOutput) string { return o.Nationality }},
+	{DisclosureDateOfBirth, "dateOfBirth", func(o GenericDiscloseOutput) string { return o.DateOfBirth }},
+	{DisclosureExpiryDate, "expiryDate", func(o GenericDiscloseOutput) string { return o.ExpiryDate }},
+	{DisclosurePersonalNumber, "personalNumber", func(o GenericDiscloseOutput) string { return o.PersonalNumber }},
+}
+
+// WithPlaceholderRejection rejects proofs disclosing placeholder values with ErrPlaceholderData, to
+// keep test documents out of production: fields holding only zeros or MRZ filler, and a date of
+// birth of 1 January 1970. Only disclosed fields are checked.
+//
+// It is off by default since real documents can carry such values; 1 January is commonly recorded
+// for holders whose exact date of birth is unknown.
+func WithPlaceholderRejection() VerifierOption {
+	return func(s *BackendVerifier) {
+		s.rejectPlaceholders = true
+	}
+}
+
+// validatePlaceholderData reports the disclosed fields of output holding placeholder values
+func validatePlaceholderData(attestationId AttestationId, output GenericDiscloseOutput, issues *[]ConfigIssue) {
+	var fields []string
+	for _, f := range placeholderFields {
+		if !isFieldDisclosedIn(attestationId, output, f.field) {
+			continue
+		}
+		value := removeNullBytes(f.value(output))
+		if isPlaceholderValue(value) || (f.field == DisclosureDateOfBirth && isEpochDateOfBirth(attestationId, value)) {
+			fields = append(fields, f.name)
+		}
+	}
+	if len(fields) > 0 {
+		*issues = append(*issues, ConfigIssue{
+			Type:    PlaceholderData,
+			Message: fmt.Sprintf("Proof discloses placeholder values in %s", strings.Join(fields, ", ")),
+		})
+	}
+}
+
+// isPlaceholderValue reports whether value holds nothing but zeros, MRZ filler and separators
+func isPlaceholderValue(value string) bool {
+	return strings.Trim(value, "0< -") == ""
+}
+
+// isEpochDateOfBirth reports whether the disclosed date of birth is 1 January 1970
+func isEpochDateOfBirth(attestationId AttestationId, value string) bool {
+	// Two-digit years resolve to the past, so MRZ "700101" reads as 1970
+	date, ok := parseDateOfBirth(attestationId, value, time.Now().UTC())
+	return ok && date.Equal(unixEpoch)
+}
@@ -0,0 +1,185 @@
+package self
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// Errors returned by VerifierPool.Verify
+var (
+	ErrPoolFull   = errors.New("verifier pool queue is full")
+	ErrPoolClosed = errors.New("verifier pool is closed")
+)
+
+// PoolConfig configures a VerifierPool
+type PoolConfig struct {
+	// Workers is the number of verifications run concurrently (at least 1)
+	Workers int
+	// QueueSize is the number of verifications that may wait for a free worker
+	QueueSize int
+	// Block makes Verify wait for room in the queue, until its context is done, instead of returning ErrPoolFull
+	Block bool
+}
+
+// PoolStats is a snapshot of a VerifierPool's load
+type PoolStats struct {
+	Workers       int    `json:"workers"`
+	QueueCapacity int    `json:"queueCapacity"`
+	QueueDepth    int    `json:"queueDepth"` // Verifications waiting for a worker
+	Active        int64  `json:"active"`     // Verifications currently running
+	Rejected      uint64 `json:"rejected"`   // Verifications refused with ErrPoolFull
+}
+
+// VerifierPool caps the number of concurrent verifications of a BackendVerifier
+// with a fixed set of workers fed from a bounded queue
+type VerifierPool struct {
+	verifier  *BackendVerifier
+	config    PoolConfig
+	jobs      chan poolJob
+	workers   sync.WaitGroup
+	mu        sync.RWMutex
+	closed    bool
+	active    atomic.Int64
+	rejected  atomic.Uint64
+	closeOnce sync.Once
+}
+
+// poolJob is a verification waiting in the queue
+type poolJob struct {
+	ctx             context.Context
+	attestationId   int
+	proof           VcAndDiscloseProof
+	publicSignals   []string
+	userContextData string
+	done            chan poolResult
+}
+
+// poolResult is the outcome of a poolJob
+type poolResult struct {
+	result *VerificationResult
+	err    error
+}
+
+// NewVerifierPool starts config.Workers workers verifying with verifier.
+// Close must be called to stop them.
+func NewVerifierPool(verifier *BackendVerifier, config PoolConfig) (*VerifierPool, error) {
+	if verifier == nil {
+		return nil, errors.New("verifier must not be nil")
+	}
+	if config.Workers < 1 {
+		return nil, errors.New("pool must have at least one worker")
+	}
+	if config.QueueSize < 0 {
+		return nil, errors.New("pool queue size must not be negative")
+	}
+
+	pool := &VerifierPool{
+		verifier: verifier,
+		config:   config,
+		jobs:     make(chan poolJob, config.QueueSize),
+	}
+	pool.workers.Add(config.Workers)
+	for i := 0; i < config.Workers; i++ {
+		go pool.work()
+	}
+	return pool, nil
+}
+
+// Verify queues a verification and waits for its result.
+//
+// Returns ErrPoolFull when the queue is full and the pool does not block, ErrPoolClosed after Close,
+// or the context error if ctx is done before the verification finishes.
+func (p *VerifierPool) Verify(
+	ctx context.Context,
+	attestationId int,
+	proof VcAndDiscloseProof,
+	publicSignals []string,
+	userContextData string,
+) (*VerificationResult, error) {
+	job := poolJob{
+		ctx:             ctx,
+		attestationId:   attestationId,
+		proof:           proof,
+		publicSignals:   publicSignals,
+		userContextData: userContextData,
+		done:            make(chan poolResult, 1),
+	}
+
+	if err := p.submit(ctx, job); err != nil {
+		return nil, err
+	}
+
+	select {
+	case outcome := <-job.done:
+		return outcome.result, outcome.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// submit adds job to the queue
+func (p *VerifierPool) submit(ctx context.Context, job poolJob) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrPoolClosed
+	}
+
+	if p.config.Block {
+		select {
+		case p.jobs <- job:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	select {
+	case p.jobs <- job:
+		return nil
+	default:
+		p.rejected.Add(1)
+		return ErrPoolFull
+	}
+}
+
+// work runs queued verifications until the pool is closed
+func (p *VerifierPool) work() {
+	defer p.workers.Done()
+	for job := range p.jobs {
+		// Skip verifications whose caller has already given up
+		if err := job.ctx.Err(); err != nil {
+			job.done <- poolResult{err: err}
+			continue
+		}
+
+		p.active.Add(1)
+		result, err := p.verifier.Verify(job.ctx, job.attestationId, job.proof, job.publicSignals, job.userContextData)
+		p.active.Add(-1)
+		job.done <- poolResult{result: result, err: err}
+	}
+}
+
+// Stats returns the current load of the pool
+func (p *VerifierPool) Stats() PoolStats {
+	return PoolStats{
+		Workers:       p.config.Workers,
+		QueueCapacity: p.config.QueueSize,
+		QueueDepth:    len(p.jobs),
+		Active:        p.active.Load(),
+		Rejected:      p.rejected.Load(),
+	}
+}
+
+// Close stops accepting verifications and waits for queued ones to finish
+func (p *VerifierPool) Close() {
+	p.closeOnce.Do(func() {
+		p.mu.Lock()
+		p.closed = true
+		close(p.jobs)
+		p.mu.Unlock()
+	})
+	p.workers.Wait()
+}
@@ -0,0 +1,437 @@
+package self
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// MarshalProto encodes the result as a VerificationResult Protocol Buffers message, the schema in
+// proto/verification_result.proto. It is a compact alternative to JSON for internal pipelines;
+// RawDiscloseOutput and MRZLines are never encoded, as in JSON.
+func (r *VerificationResult) MarshalProto() ([]byte, error) {
+	config, err := json.Marshal(r.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %v", err)
+	}
+
+	e := &protoEncoder{}
+	e.int(1, int64(r.AttestationId))
+	e.int(2, int64(r.CircuitVersion))
+	e.message(3, func(e *protoEncoder) {
+		e.bool(1, r.IsValidDetails.IsValid)
+		e.bool(2, r.IsValidDetails.IsMinimumAgeValid)
+		e.bool(3, r.IsValidDetails.IsOfacValid)
+	})
+	e.string(4, string(r.OFACSource))
+	if override := r.OFACOverride; override != nil {
+		e.message(5, func(e *protoEncoder) {
+			e.string(1, override.Nullifier)
+			e.string(2, override.ClearedBy)
+			e.string(3, override.Reason)
+			if !override.ClearedAt.IsZero() {
+				e.int(4, override.ClearedAt.UnixNano())
+			}
+		})
+	}
+	e.strings(6, r.ForbiddenCountriesList)
+	e.message(7, func(e *protoEncoder) { e.discloseOutput(r.DiscloseOutput) })
+	e.message(8, func(e *protoEncoder) {
+		e.string(1, r.UserData.UserIdentifier)
+		e.string(2, r.UserData.UserDefinedData)
+	})
+	if score := r.Score; score != nil {
+		e.message(9, func(e *protoEncoder) {
+			e.double(1, score.Score)
+			e.strings(2, score.Reasons)
+		})
+	}
+	for _, issue := range r.AdvisoryIssues {
+		e.message(10, func(e *protoEncoder) {
+			e.string(1, string(issue.Type))
+			e.string(2, issue.Message)
+		})
+	}
+	e.string(11, string(r.IssuingStateRiskTier))
+	e.string(12, string(r.NationalityRiskTier))
+	e.string(13, r.ConfigId)
+	e.bytes(14, config)
+	e.string(15, r.ConfigHash)
+	e.string(16, r.Scope)
+	e.int(17, int64(r.ProofVerificationTime))
+	e.optionalBool(18, r.IssuedAsMinor)
+	e.bool(19, r.DisclosureWithheld)
+	// Map entries are sorted by field so equal results encode identically
+	fields := slices.Sorted(maps.Keys(r.DisclosureStatus))
+	for _, field := range fields {
+		e.message(20, func(e *protoEncoder) {
+			e.string(1, string(field))
+			e.string(2, string(r.DisclosureStatus[field]))
+		})
+	}
+	return e.b, nil
+}
+
+// UnmarshalProto decodes a VerificationResult Protocol Buffers message encoded by MarshalProto into r.
+// Unknown fields are skipped, so results encoded by newer versions still decode.
+func (r *VerificationResult) UnmarshalProto(data []byte) error {
+	var result VerificationResult
+	err := decodeProto(data, func(field protoField) error {
+		switch field.num {
+		case 1:
+			result.AttestationId = AttestationId(field.varint)
+		case 2:
+			result.CircuitVersion = CircuitVersion(field.varint)
+		case 3:
+			return decodeProto(field.bytes, func(field protoField) error {
+				switch field.num {
+				case 1:
+					result.IsValidDetails.IsValid = field.bool()
+				case 2:
+					result.IsValidDetails.IsMinimumAgeValid = field.bool()
+				case 3:
+					result.IsValidDetails.IsOfacValid = field.bool()
+				}
+				return nil
+			})
+		case 4:
+			result.OFACSource = OFACSource(field.bytes)
+		case 5:
+			override := &OFACOverride{}
+			result.OFACOverride = override
+			return decodeProto(field.bytes, func(field protoField) error {
+				switch field.num {
+				case 1:
+					override.Nullifier = string(field.bytes)
+				case 2:
+					override.ClearedBy = string(field.bytes)
+				case 3:
+					override.Reason = string(field.bytes)
+				case 4:
+					override.ClearedAt = time.Unix(0, int64(field.varint)).UTC()
+				}
+				return nil
+			})
+		case 6:
+			result.ForbiddenCountriesList = append(result.ForbiddenCountriesList, string(field.bytes))
+		case 7:
+			return decodeDiscloseOutputProto(field.bytes, &result.DiscloseOutput)
+		case 8:
+			return decodeProto(field.bytes, func(field protoField) error {
+				switch field.num {
+				case 1:
+					result.UserData.UserIdentifier = string(field.bytes)
+				case 2:
+					result.UserData.UserDefinedData = string(field.bytes)
+				}
+				return nil
+			})
+		case 9:
+			score := &VerificationScore{}
+			result.Score = score
+			return decodeProto(field.bytes, func(field protoField) error {
+				switch field.num {
+				case 1:
+					score.Score = math.Float64frombits(field.varint)
+				case 2:
+					score.Reasons = append(score.Reasons, string(field.bytes))
+				}
+				return nil
+			})
+		case 10:
+			var issue ConfigIssue
+			if err := decodeProto(field.bytes, func(field protoField) error {
+				switch field.num {
+				case 1:
+					issue.Type = ConfigMismatch(field.bytes)
+				case 2:
+					issue.Message = string(field.bytes)
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			result.AdvisoryIssues = append(result.AdvisoryIssues, issue)
+		case 11:
+			result.IssuingStateRiskTier = RiskTier(field.bytes)
+		case 12:
+			result.NationalityRiskTier = RiskTier(field.bytes)
+		case 13:
+			result.ConfigId = string(field.bytes)
+		case 14:
+			if err := json.Unmarshal(field.bytes, &result.Config); err != nil {
+				return fmt.Errorf("invalid config: %v", err)
+			}
+		case 15:
+			result.ConfigHash = string(field.bytes)
+		case 16:
+			result.Scope = string(field.bytes)
+		case 17:
+			result.ProofVerificationTime = time.Duration(field.varint)
+		case 18:
+			issuedAsMinor := field.bool()
+			result.IssuedAsMinor = &issuedAsMinor
+		case 19:
+			result.DisclosureWithheld = field.bool()
+		case 20:
+			var disclosureField DisclosureField
+			var status DisclosureStatus
+			if err := decodeProto(field.bytes, func(field protoField) error {
+				switch field.num {
+				case 1:
+					disclosureField = DisclosureField(field.bytes)
+				case 2:
+					status = DisclosureStatus(field.bytes)
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			if result.DisclosureStatus == nil {
+				result.DisclosureStatus = make(map[DisclosureField]DisclosureStatus)
+			}
+			result.DisclosureStatus[disclosureField] = status
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to decode verification result protobuf: %v", err)
+	}
+	*r = result
+	return nil
+}
+
+// discloseOutput encodes a DiscloseOutput message
+func (e *protoEncoder) discloseOutput(output GenericDiscloseOutput) {
+	e.string(1, output.Nullifier)
+	e.strings(2, output.ForbiddenCountriesListPacked)
+	e.string(3, output.IssuingState)
+	e.string(4, output.Name)
+	e.string(5, output.IdNumber)
+	e.string(6, output.Nationality)
+	e.string(7, output.DateOfBirth)
+	e.string(8, output.Gender)
+	e.string(9, output.ExpiryDate)
+	e.string(10, output.MinimumAge)
+	if len(output.Ofac) > 0 {
+		var packed []byte
+		for _, ofac := range output.Ofac {
+			packed = protowire.AppendVarint(packed, protowire.EncodeBool(ofac))
+		}
+		e.bytes(11, packed)
+	}
+	e.optionalBool(12, output.DocumentValid)
+	if mrz := output.MRZ; mrz != nil {
+		e.message(13, func(e *protoEncoder) {
+			e.string(1, mrz.DocumentNumberCheckDigit)
+			e.string(2, mrz.DateOfBirthCheckDigit)
+			e.string(3, mrz.ExpiryDateCheckDigit)
+			e.string(4, mrz.OptionalData)
+			e.string(5, mrz.CompositeCheckDigit)
+			e.string(6, mrz.CompositeData)
+		})
+	}
+	e.string(14, output.DateOfBirthISO)
+	e.string(15, output.ExpiryDateISO)
+	e.string(16, output.PersonalNumber)
+	e.bool(17, output.BiometricCommitment)
+	e.string(18, string(output.DocumentSubtype))
+	e.string(19, output.DateOfIssueISO)
+}
+
+// decodeDiscloseOutputProto decodes a DiscloseOutput message into output
+func decodeDiscloseOutputProto(data []byte, output *GenericDiscloseOutput) error {
+	return decodeProto(data, func(field protoField) error {
+		switch field.num {
+		case 1:
+			output.Nullifier = string(field.bytes)
+		case 2:
+			output.ForbiddenCountriesListPacked = append(output.ForbiddenCountriesListPacked, string(field.bytes))
+		case 3:
+			output.IssuingState = string(field.bytes)
+		case 4:
+			output.Name = string(field.bytes)
+		case 5:
+			output.IdNumber = string(field.bytes)
+		case 6:
+			output.Nationality = string(field.bytes)
+		case 7:
+			output.DateOfBirth = string(field.bytes)
+		case 8:
+			output.Gender = string(field.bytes)
+		case 9:
+			output.ExpiryDate = string(field.bytes)
+		case 10:
+			output.MinimumAge = string(field.bytes)
+		case 11:
+			if field.typ == protowire.VarintType {
+				output.Ofac = append(output.Ofac, field.bool())
+				return nil
+			}
+			// Packed repeated bools
+			for packed := field.bytes; len(packed) > 0; {
+				value, n := protowire.ConsumeVarint(packed)
+				if n < 0 {
+					return protowire.ParseError(n)
+				}
+				output.Ofac = append(output.Ofac, protowire.DecodeBool(value))
+				packed = packed[n:]
+			}
+		case 12:
+			valid := field.bool()
+			output.DocumentValid = &valid
+		case 13:
+			mrz := &MRZComponents{}
+			output.MRZ = mrz
+			return decodeProto(field.bytes, func(field protoField) error {
+				value := string(field.bytes)
+				switch field.num {
+				case 1:
+					mrz.DocumentNumberCheckDigit = value
+				case 2:
+					mrz.DateOfBirthCheckDigit = value
+				case 3:
+					mrz.ExpiryDateCheckDigit = value
+				case 4:
+					mrz.OptionalData = value
+				case 5:
+					mrz.CompositeCheckDigit = value
+				case 6:
+					mrz.CompositeData = value
+				}
+				return nil
+			})
+		case 14:
+			output.DateOfBirthISO = string(field.bytes)
+		case 15:
+			output.ExpiryDateISO = string(field.bytes)
+		case 16:
+			output.PersonalNumber = string(field.bytes)
+		case 17:
+			output.BiometricCommitment = field.bool()
+		case 18:
+			output.DocumentSubtype = DocumentSubtype(field.bytes)
+		case 19:
+			output.DateOfIssueISO = string(field.bytes)
+		}
+		return nil
+	})
+}
+
+// protoEncoder appends Protocol Buffers fields, omitting proto3 default values of scalar fields
+type protoEncoder struct {
+	b []byte
+}
+
+func (e *protoEncoder) int(num protowire.Number, value int64) {
+	if value != 0 {
+		e.b = protowire.AppendTag(e.b, num, protowire.VarintType)
+		e.b = protowire.AppendVarint(e.b, uint64(value))
+	}
+}
+
+func (e *protoEncoder) bool(num protowire.Number, value bool) {
+	if value {
+		e.b = protowire.AppendTag(e.b, num, protowire.VarintType)
+		e.b = protowire.AppendVarint(e.b, 1)
+	}
+}
+
+// optionalBool encodes an optional bool field, present even when false
+func (e *protoEncoder) optionalBool(num protowire.Number, value *bool) {
+	if value != nil {
+		e.b = protowire.AppendTag(e.b, num, protowire.VarintType)
+		e.b = protowire.AppendVarint(e.b, protowire.EncodeBool(*value))
+	}
+}
+
+func (e *protoEncoder) double(num protowire.Number, value float64) {
+	if value != 0 {
+		e.b = protowire.AppendTag(e.b, num, protowire.Fixed64Type)
+		e.b = protowire.AppendFixed64(e.b, math.Float64bits(value))
+	}
+}
+
+func (e *protoEncoder) string(num protowire.Number, value string) {
+	if value != "" {
+		e.b = protowire.AppendTag(e.b, num, protowire.BytesType)
+		e.b = protowire.AppendString(e.b, value)
+	}
+}
+
+func (e *protoEncoder) bytes(num protowire.Number, value []byte) {
+	if len(value) > 0 {
+		e.b = protowire.AppendTag(e.b, num, protowire.BytesType)
+		e.b = protowire.AppendBytes(e.b, value)
+	}
+}
+
+// strings encodes a repeated string field, including empty elements
+func (e *protoEncoder) strings(num protowire.Number, values []string) {
+	for _, value := range values {
+		e.b = protowire.AppendTag(e.b, num, protowire.BytesType)
+		e.b = protowire.AppendString(e.b, value)
+	}
+}
+
+// message encodes an embedded message, present even when all its fields are defaults
+func (e *protoEncoder) message(num protowire.Number, encode func(e *protoEncoder)) {
+	inner := &protoEncoder{}
+	encode(inner)
+	e.b = protowire.AppendTag(e.b, num, protowire.BytesType)
+	e.b = protowire.AppendBytes(e.b, inner.b)
+}
+
+// protoField is a decoded Protocol Buffers field. Varint and fixed64 values are in varint,
+// length-delimited values in bytes.
+type protoField struct {
+	num    protowire.Number
+	typ    protowire.Type
+	varint uint64
+	bytes  []byte
+}
+
+func (f protoField) bool() bool {
+	return protowire.DecodeBool(f.varint)
+}
+
+// decodeProto calls fn for every field of a message, skipping group and fixed32 fields
+func decodeProto(data []byte, fn func(field protoField) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		field := protoField{num: num, typ: typ}
+		switch typ {
+		case protowire.VarintType:
+			field.varint, n = protowire.ConsumeVarint(data)
+		case protowire.Fixed64Type:
+			field.varint, n = protowire.ConsumeFixed64(data)
+		case protowire.BytesType:
+			field.bytes, n = protowire.ConsumeBytes(data)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+			if n >= 0 {
+				data = data[n:]
+				continue
+			}
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		if err := fn(field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package self
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// VerifyMessage is the message envelope consumed by QueueConsumer: the VerifyInput fields plus a
+// correlation ID echoed on the result
+type VerifyMessage struct {
+	CorrelationId string `json:"correlationId"`
+	VerifyInput
+}
+
+// VerifyResultMessage is published by QueueConsumer for every verified message. Exactly one of
+// Response and Error is set, with the same bodies VerifyHandler returns over HTTP.
+type VerifyResultMessage struct {
+	CorrelationId string          `json:"correlationId"`
+	Response      *VerifyResponse `json:"response,omitempty"`
+	Error         *ErrorResponse  `json:"error,omitempty"`
+}
+
+// DeadLetterMessage is published for messages that could not be processed
+type DeadLetterMessage struct {
+	CorrelationId string          `json:"correlationId,omitempty"`
+	Error         string          `json:"error"`
+	Body          json.RawMessage `json:"body"`
+}
+
+// MessagePublisher publishes an encoded message to a topic of the message broker
+type MessagePublisher interface {
+	Publish(ctx context.Context, message []byte) error
+}
+
+// QueueConsumerConfig configures a QueueConsumer
+type QueueConsumerConfig struct {
+	// Concurrency is the number of messages verified in parallel by Run (at least 1)
+	Concurrency int
+	// Results receives a VerifyResultMessage for every verified message
+	Results MessagePublisher
+	// DeadLetter receives a DeadLetterMessage for messages that could not be processed.
+	// When nil, such messages are only logged.
+	DeadLetter MessagePublisher
+	// SubjectNaming renames the credential subject keys of published responses (camelCase when nil)
+	SubjectNaming FieldNaming
+}
+
+// QueueConsumer verifies proofs received from a message queue (Kafka, NATS, ...) and publishes the results
+type QueueConsumer struct {
+	verifier *BackendVerifier
+	config   QueueConsumerConfig
+}
+
+// NewQueueConsumer creates a QueueConsumer verifying with verifier
+func NewQueueConsumer(verifier *BackendVerifier, config QueueConsumerConfig) (*QueueConsumer, error) {
+	if verifier == nil {
+		return nil, errors.New("verifier must not be nil")
+	}
+	if config.Results == nil {
+		return nil, errors.New("results publisher must not be nil")
+	}
+	if config.Concurrency < 1 {
+		return nil, errors.New("concurrency must be at least 1")
+	}
+	return &QueueConsumer{verifier: verifier, config: config}, nil
+}
+
+// Handle verifies a single encoded VerifyMessage and publishes its VerifyResultMessage.
+//
+// Invalid requests and rejected proofs are published as error results. An error is returned only
+// when the message could not be processed: it is not valid JSON, verification failed internally
+// (e.g. the RPC endpoint is unreachable) or timed out, or the result could not be published. Such messages may
+// be retried or dead-lettered.
+func (c *QueueConsumer) Handle(ctx context.Context, body []byte) error {
+	var envelope struct {
+		CorrelationId string `json:"correlationId"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return fmt.Errorf("invalid message: %v", err)
+	}
+
+	resultMessage := VerifyResultMessage{CorrelationId: envelope.CorrelationId}
+	input, err := DecodeVerifyInput(bytes.NewReader(body))
+	if err == nil {
+		var result *VerificationResult
+		result, err = c.verifier.verifyInput(ctx, input)
+		err = verificationOutcome(result, err)
+		if err == nil {
+			response := newVerifyResponse(result, c.config.SubjectNaming)
+			resultMessage.Response = &response
+		}
+	}
+	if err != nil {
+		if isServiceErrorCode(ErrorCodeFor(err)) {
+			return fmt.Errorf("verification failed: %w", err)
+		}
+		response := errorResponseFor(err)
+		resultMessage.Error = &response
+	}
+
+	encoded, err := json.Marshal(resultMessage)
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %v", err)
+	}
+	if err := c.config.Results.Publish(ctx, encoded); err != nil {
+		return fmt.Errorf("failed to publish result: %v", err)
+	}
+	return nil
+}
+
+// Run handles messages with the configured concurrency until messages is closed or ctx is done.
+// Messages that Handle fails to process are sent to the dead-letter publisher.
+func (c *QueueConsumer) Run(ctx context.Context, messages <-chan []byte) error {
+	var workers sync.WaitGroup
+	workers.Add(c.config.Concurrency)
+	for i := 0; i < c.config.Concurrency; i++ {
+		go func() {
+			defer workers.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case body, ok := <-messages:
+					if !ok {
+						return
+					}
+					if err := c.Handle(ctx, body); err != nil {
+						c.deadLetter(ctx, body, err)
+					}
+				}
+			}
+		}()
+	}
+	workers.Wait()
+	return ctx.Err()
+}
+
+// deadLetter publishes body to the dead-letter publisher, logging when that is not possible
+func (c *QueueConsumer) deadLetter(ctx context.Context, body []byte, cause error) {
+	var envelope struct {
+		CorrelationId string `json:"correlationId"`
+	}
+	json.Unmarshal(body, &envelope)
+
+	message := DeadLetterMessage{CorrelationId: envelope.CorrelationId, Error: cause.Error(), Body: body}
+	if !json.Valid(body) {
+		message.Body, _ = json.Marshal(string(body))
+	}
+
+	if c.config.DeadLetter != nil {
+		encoded, err := json.Marshal(message)
+		if err == nil {
+			err = c.config.DeadLetter.Publish(ctx, encoded)
+		}
+		if err == nil {
+			return
+		}
+		c.verifier.logger.Printf("self: failed to dead-letter message %q: %v", envelope.CorrelationId, err)
+	}
+	c.verifier.logger.Printf("self: dropped message %q: %v", envelope.CorrelationId, cause)
+}
@@ -0,0 +1,357 @@
+package self
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// ErrorCode is a stable, machine-readable identifier for a verification failure.
+// Codes are part of the public API and are never renamed.
+type ErrorCode string
+
+const (
+	ErrorCodeInvalidRequest            ErrorCode = "INVALID_REQUEST"
+	ErrorCodeInvalidProof              ErrorCode = "INVALID_PROOF"
+	ErrorCodeAttestationNotAllowed     ErrorCode = "ATTESTATION_NOT_ALLOWED"
+	ErrorCodeUnknownAttestationType    ErrorCode = "UNKNOWN_ATTESTATION_TYPE"
+	ErrorCodeUnsupportedCircuitVersion ErrorCode = "UNSUPPORTED_CIRCUIT_VERSION"
+	ErrorCodeInvalidPublicSignals      ErrorCode = "INVALID_PUBLIC_SIGNALS"
+	ErrorCodeInvalidAttestationId      ErrorCode = "INVALID_ATTESTATION_ID"
+	ErrorCodeScopeMismatch             ErrorCode = "SCOPE_MISMATCH"
+	ErrorCodeUserContextMismatch       ErrorCode = "USER_CONTEXT_MISMATCH"
+	ErrorCodeInvalidRoot               ErrorCode = "INVALID_ROOT"
+	ErrorCodeInvalidTimestamp          ErrorCode = "INVALID_TIMESTAMP"
+	ErrorCodeProofTooOld               ErrorCode = "PROOF_TOO_OLD"
+	ErrorCodeProofFromFuture           ErrorCode = "PROOF_FROM_FUTURE"
+	ErrorCodeProofPredatesSession      ErrorCode = "PROOF_PREDATES_SESSION"
+	ErrorCodeConfigNotFound            ErrorCode = "CONFIG_NOT_FOUND"
+	ErrorCodeAgeNotMet                 ErrorCode = "AGE_NOT_MET"
+	ErrorCodeCountryExcluded           ErrorCode = "COUNTRY_EXCLUDED"
+	ErrorCodeOfacHit                   ErrorCode = "OFAC_HIT"
+	ErrorCodeSanctionedNationality     ErrorCode = "SANCTIONED_NATIONALITY"
+	ErrorCodeOverDisclosure            ErrorCode = "OVER_DISCLOSURE"
+	ErrorCodeIssuingStateNotAllowed    ErrorCode = "ISSUING_STATE_NOT_ALLOWED"
+	ErrorCodeContextDataSignature      ErrorCode = "CONTEXT_DATA_SIGNATURE_INVALID"
+	ErrorCodeMissingBiometric          ErrorCode = "MISSING_BIOMETRIC"
+	ErrorCodeNationalityIssuerMismatch ErrorCode = "NATIONALITY_ISSUER_MISMATCH"
+	ErrorCodeDocumentSubtypeNotAllowed ErrorCode = "DOCUMENT_SUBTYPE_NOT_ALLOWED"
+	ErrorCodeIssuedAsMinor             ErrorCode = "ISSUED_AS_MINOR"
+	ErrorCodeInconsistentDisclosure    ErrorCode = "INCONSISTENT_DISCLOSURE"
+	ErrorCodeSaltMismatch              ErrorCode = "SALT_MISMATCH"
+	ErrorCodeMissingDisclosure         ErrorCode = "MISSING_DISCLOSURE"
+	ErrorCodePlaceholderData           ErrorCode = "PLACEHOLDER_DATA"
+	ErrorCodeLinkedRuleViolation       ErrorCode = "LINKED_RULE_VIOLATION"
+	ErrorCodeIdentityMismatch          ErrorCode = "IDENTITY_MISMATCH"
+	ErrorCodeVerificationTimeout       ErrorCode = "VERIFICATION_TIMEOUT"
+	ErrorCodeVerificationBudget        ErrorCode = "VERIFICATION_BUDGET_EXCEEDED"
+	ErrorCodeConfigStoreTimeout        ErrorCode = "CONFIG_STORE_TIMEOUT"
+	ErrorCodeInternal                  ErrorCode = "INTERNAL_ERROR"
+	ErrorCodeUnauthorized              ErrorCode = "UNAUTHORIZED"
+)
+
+// AllErrorCodes lists every ErrorCode, e.g. to check that a translation catalog is complete
+var AllErrorCodes = []ErrorCode{
+	ErrorCodeInvalidRequest,
+	ErrorCodeInvalidProof,
+	ErrorCodeAttestationNotAllowed,
+	ErrorCodeUnknownAttestationType,
+	ErrorCodeUnsupportedCircuitVersion,
+	ErrorCodeInvalidPublicSignals,
+	ErrorCodeInvalidAttestationId,
+	ErrorCodeScopeMismatch,
+	ErrorCodeUserContextMismatch,
+	ErrorCodeInvalidRoot,
+	ErrorCodeInvalidTimestamp,
+	ErrorCodeProofTooOld,
+	ErrorCodeProofFromFuture,
+	ErrorCodeProofPredatesSession,
+	ErrorCodeConfigNotFound,
+	ErrorCodeAgeNotMet,
+	ErrorCodeCountryExcluded,
+	ErrorCodeOfacHit,
+	ErrorCodeSanctionedNationality,
+	ErrorCodeOverDisclosure,
+	ErrorCodeIssuingStateNotAllowed,
+	ErrorCodeContextDataSignature,
+	ErrorCodeMissingBiometric,
+	ErrorCodeNationalityIssuerMismatch,
+	ErrorCodeDocumentSubtypeNotAllowed,
+	ErrorCodeIssuedAsMinor,
+	ErrorCodeInconsistentDisclosure,
+	ErrorCodeSaltMismatch,
+	ErrorCodeMissingDisclosure,
+	ErrorCodePlaceholderData,
+	ErrorCodeLinkedRuleViolation,
+	ErrorCodeIdentityMismatch,
+	ErrorCodeVerificationTimeout,
+	ErrorCodeVerificationBudget,
+	ErrorCodeConfigStoreTimeout,
+	ErrorCodeInternal,
+	ErrorCodeUnauthorized,
+}
+
+// errorCodes lists errors and their codes, checked in order so the most fundamental failure wins
+var errorCodes = []struct {
+	err  error
+	code ErrorCode
+}{
+	{ErrVerificationTimeout, ErrorCodeVerificationTimeout},
+	{ErrVerificationBudgetExceeded, ErrorCodeVerificationBudget},
+	{ErrConfigStoreTimeout, ErrorCodeConfigStoreTimeout},
+	{ErrInvalidRequest, ErrorCodeInvalidRequest},
+	{ErrUnknownAttestationType, ErrorCodeUnknownAttestationType},
+	{ErrAttestationNotAllowed, ErrorCodeAttestationNotAllowed},
+	{ErrUnsupportedCircuitVersion, ErrorCodeUnsupportedCircuitVersion},
+	{ErrMisorderedPublicSignals, ErrorCodeInvalidPublicSignals},
+	{ErrTooManyPublicSignals, ErrorCodeInvalidPublicSignals},
+	{ErrInvalidAttestationId, ErrorCodeInvalidAttestationId},
+	{ErrInconsistentDisclosure, ErrorCodeInconsistentDisclosure},
+	{ErrSaltMismatch, ErrorCodeSaltMismatch},
+	{ErrPlaceholderData, ErrorCodePlaceholderData},
+	{ErrScopeMismatch, ErrorCodeScopeMismatch},
+	{ErrUserContextMismatch, ErrorCodeUserContextMismatch},
+	{ErrContextDataSignatureInvalid, ErrorCodeContextDataSignature},
+	{ErrInvalidRoot, ErrorCodeInvalidRoot},
+	{ErrProofTooOld, ErrorCodeProofTooOld},
+	{ErrProofFromFuture, ErrorCodeProofFromFuture},
+	{ErrProofPredatesSession, ErrorCodeProofPredatesSession},
+	{ErrInvalidTimestamp, ErrorCodeInvalidTimestamp},
+	{ErrConfigNotFound, ErrorCodeConfigNotFound},
+	{ErrMinimumAgeNotMet, ErrorCodeAgeNotMet},
+	{ErrSanctionedNationality, ErrorCodeSanctionedNationality},
+	{ErrIssuingStateNotAllowed, ErrorCodeIssuingStateNotAllowed},
+	{ErrNationalityIssuerMismatch, ErrorCodeNationalityIssuerMismatch},
+	{ErrDocumentSubtypeNotAllowed, ErrorCodeDocumentSubtypeNotAllowed},
+	{ErrIssuedAsMinor, ErrorCodeIssuedAsMinor},
+	{ErrMissingBiometric, ErrorCodeMissingBiometric},
+	{ErrCountryExcluded, ErrorCodeCountryExcluded},
+	{ErrOfacHit, ErrorCodeOfacHit},
+	{ErrOverDisclosure, ErrorCodeOverDisclosure},
+	{ErrMissingDisclosure, ErrorCodeMissingDisclosure},
+	{ErrLinkedRuleViolation, ErrorCodeLinkedRuleViolation},
+	{ErrIdentityMismatch, ErrorCodeIdentityMismatch},
+	{ErrInvalidProof, ErrorCodeInvalidProof},
+}
+
+// ErrorCodeFor returns the ErrorCode describing err, or ErrorCodeInternal if err is not a known verification error
+func ErrorCodeFor(err error) ErrorCode {
+	for _, entry := range errorCodes {
+		if errors.Is(err, entry.err) {
+			return entry.code
+		}
+	}
+	return ErrorCodeInternal
+}
+
+// isServiceErrorCode reports whether code describes a failure of the verifier or its dependencies,
+// such as a timeout or an unreachable store, rather than a rejected request
+func isServiceErrorCode(code ErrorCode) bool {
+	return code == ErrorCodeInternal || code == ErrorCodeVerificationTimeout || code == ErrorCodeConfigStoreTimeout
+}
+
+// ErrorResponse is the JSON body returned for every failed verification request
+type ErrorResponse struct {
+	Status  string    `json:"status"`
+	Result  bool      `json:"result"`
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+	// Errors lists field-level problems for ErrorCodeInvalidRequest responses
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// NewErrorResponse builds an ErrorResponse with the given code and message
+func NewErrorResponse(code ErrorCode, message string) ErrorResponse {
+	return ErrorResponse{
+		Status:  "error",
+		Result:  false,
+		Code:    code,
+		Message: message,
+	}
+}
+
+// VerifyResponse is the JSON body returned for a successful verification request
+type VerifyResponse struct {
+	Status              string                `json:"status"`
+	Result              bool                  `json:"result"`
+	Details             IsValidDetails        `json:"details"`
+	CredentialSubject   CredentialSubject     `json:"credentialSubject"`
+	VerificationOptions VerificationOptions   `json:"verificationOptions"`
+	UserData            UserData              `json:"userData"`
+	DiscloseOutput      GenericDiscloseOutput `json:"discloseOutput"`
+	// DisclosureStatus tells clients why a credential subject field is absent
+	DisclosureStatus map[DisclosureField]DisclosureStatus `json:"disclosureStatus,omitempty"`
+
+	// subjectNaming renames the credential subject keys when marshalling
+	subjectNaming FieldNaming
+}
+
+// newVerifyResponse builds the VerifyResponse for a valid result, naming credential subject keys with naming
+func newVerifyResponse(result *VerificationResult, naming FieldNaming) VerifyResponse {
+	return VerifyResponse{
+		Status:              "success",
+		Result:              true,
+		Details:             result.IsValidDetails,
+		CredentialSubject:   NewCredentialSubject(result),
+		VerificationOptions: NewVerificationOptions(result.Config),
+		UserData:            result.UserData,
+		DiscloseOutput:      result.DiscloseOutput,
+		DisclosureStatus:    result.DisclosureStatus,
+		subjectNaming:       naming,
+	}
+}
+
+// CredentialSubject holds the attributes a proof disclosed, with undisclosed fields omitted
+type CredentialSubject struct {
+	IssuingState string `json:"issuingState,omitempty"`
+	Name         string `json:"name,omitempty"`
+	IdNumber     string `json:"idNumber,omitempty"`
+	Nationality  string `json:"nationality,omitempty"`
+	DateOfBirth  string `json:"dateOfBirth,omitempty"`
+	Gender       string `json:"gender,omitempty"`
+	ExpiryDate   string `json:"expiryDate,omitempty"`
+	MinimumAge   string `json:"minimumAge,omitempty"`
+	// PersonalNumber is the holder's personal number, distinct from the document number in IdNumber
+	PersonalNumber string `json:"personalNumber,omitempty"`
+	// DocumentValid replaces ExpiryDate when the config uses ExpiryDisclosureValidity
+	DocumentValid *bool `json:"documentValid,omitempty"`
+}
+
+// NewCredentialSubject builds the CredentialSubject for a verification result
+func NewCredentialSubject(result *VerificationResult) CredentialSubject {
+	disclosed := result.reportedFieldSet()
+	value := func(field DisclosureField) string {
+		if !disclosed[field] {
+			return ""
+		}
+		return result.DiscloseOutput.fieldValue(field)
+	}
+	subject := CredentialSubject{
+		IssuingState:   value(DisclosureIssuingState),
+		Name:           value(DisclosureName),
+		IdNumber:       value(DisclosureDocumentNumber),
+		Nationality:    value(DisclosureNationality),
+		DateOfBirth:    value(DisclosureDateOfBirth),
+		Gender:         value(DisclosureGender),
+		ExpiryDate:     value(DisclosureExpiryDate),
+		PersonalNumber: value(DisclosurePersonalNumber),
+		DocumentValid:  result.DiscloseOutput.DocumentValid,
+	}
+	if minimumAge, err := strconv.Atoi(result.DiscloseOutput.MinimumAge); err == nil && minimumAge > 0 {
+		subject.MinimumAge = strconv.Itoa(minimumAge)
+	}
+	return subject
+}
+
+// VerificationOptions describes the requirements a proof was verified against.
+// Pointer fields are nil when the requirement was not configured.
+type VerificationOptions struct {
+	MinimumAge        *int     `json:"minimumAge,omitempty"`
+	Ofac              *bool    `json:"ofac,omitempty"`
+	ExcludedCountries []string `json:"excludedCountries,omitempty"`
+}
+
+// NewVerificationOptions builds the VerificationOptions describing config
+func NewVerificationOptions(config VerificationConfig) VerificationOptions {
+	var options VerificationOptions
+	if config.MinimumAge != 0 {
+		minimumAge := config.MinimumAge
+		options.MinimumAge = &minimumAge
+	}
+	if config.Ofac {
+		ofac := true
+		options.Ofac = &ofac
+	}
+	for _, country := range config.ExcludedCountries {
+		options.ExcludedCountries = append(options.ExcludedCountries, string(country))
+	}
+	return options
+}
+
+// HTTPStatusFor returns the HTTP status code for the error returned by Verify, so every
+// handler reports outcomes the same way:
+//   - nil: 200 OK
+//   - ErrorCodeInvalidRequest: 400 Bad Request, the request itself is malformed
+//   - ErrorCodeVerificationTimeout: 504 Gateway Timeout
+//   - ErrorCodeConfigStoreTimeout: 503 Service Unavailable
+//   - ErrorCodeInternal: 500 Internal Server Error, a server-side failure worth alerting on
+//   - any other code: 422 Unprocessable Entity, the request was well-formed but verification failed
+func HTTPStatusFor(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	switch ErrorCodeFor(err) {
+	case ErrorCodeInvalidRequest:
+		return http.StatusBadRequest
+	case ErrorCodeVerificationTimeout:
+		return http.StatusGatewayTimeout
+	case ErrorCodeConfigStoreTimeout:
+		return http.StatusServiceUnavailable
+	case ErrorCodeInternal:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusUnprocessableEntity
+	}
+}
+
+// NewVerifyHTTPResponse builds the HTTP status code and JSON body for the outcome of Verify:
+// a VerifyResponse for a valid result, or an ErrorResponse with result false otherwise.
+// A nil or invalid result without an error is a failed verification (ErrInvalidProof), never a
+// server error.
+func NewVerifyHTTPResponse(result *VerificationResult, err error, naming FieldNaming) (int, interface{}) {
+	if err = verificationOutcome(result, err); err != nil {
+		return HTTPStatusFor(err), errorResponseFor(err)
+	}
+	return http.StatusOK, newVerifyResponse(result, naming)
+}
+
+// WriteVerifyResponse writes the outcome of Verify to w as JSON, with the status code and body of
+// NewVerifyHTTPResponse, so custom handlers respond exactly like VerifyHandler:
+//
+//	result, err := verifier.Verify(r.Context(), attestationId, proof, signals, contextData)
+//	self.WriteVerifyResponse(w, result, err)
+//
+// Use NewVerifyHTTPResponse to rename the credential subject keys or localize error messages.
+func WriteVerifyResponse(w http.ResponseWriter, result *VerificationResult, err error) {
+	status, body := NewVerifyHTTPResponse(result, err, nil)
+	writeJSON(w, status, body)
+}
+
+// verificationOutcome returns the error describing the outcome of Verify, ErrInvalidProof when the
+// proof verified without error but is not valid
+func verificationOutcome(result *VerificationResult, err error) error {
+	if err == nil && (result == nil || !result.IsValidDetails.IsValid) {
+		return ErrInvalidProof
+	}
+	return err
+}
+
+// writeJSON writes body as JSON with the given status code
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeError writes an ErrorResponse for err, hiding the details of internal errors
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponseFor(err))
+}
+
+// errorResponseFor builds the ErrorResponse describing err, hiding the details of internal errors
+func errorResponseFor(err error) ErrorResponse {
+	code := ErrorCodeFor(err)
+	message := err.Error()
+	if code == ErrorCodeInternal {
+		message = "Internal verification error"
+	}
+	response := NewErrorResponse(code, message)
+
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		response.Message = "Invalid request"
+		response.Errors = validationErr.Errors
+	}
+	return response
+}
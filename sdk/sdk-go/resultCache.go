@@ -0,0 +1,245 @@
+package self
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+	"math/big"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ResultCache keeps valid verification results for a while, so a retried request (e.g. after the
+// client timed out waiting for the response) is answered with the same result without verifying the
+// proof again. Only valid results are cached. Entries can be purged early with InvalidateResult or
+// InvalidateByNullifier, e.g. when a user is later sanctioned.
+type ResultCache struct {
+	mu          sync.Mutex
+	ttl         time.Duration
+	entries     map[string]cachedResult
+	byNullifier map[string]map[string]bool
+	nextSweep   time.Time
+	now         func() time.Time
+}
+
+// cachedResult is a cached result and when it expires
+type cachedResult struct {
+	result    VerificationResult
+	expiresAt time.Time
+}
+
+// NewResultCache creates a ResultCache keeping results for ttl
+func NewResultCache(ttl time.Duration) *ResultCache {
+	return &ResultCache{
+		ttl:         ttl,
+		entries:     make(map[string]cachedResult),
+		byNullifier: make(map[string]map[string]bool),
+		now:         time.Now,
+	}
+}
+
+// WithResultCache answers repeated Verify calls for the same request from cache while their result
+// is cached, setting VerificationResult.Cached. The config is still resolved on every call, and a
+// result checked against another config than the current one is verified again. Cache hits are
+// audited and recorded as outcomes with Cached set.
+func WithResultCache(cache *ResultCache) VerifierOption {
+	return func(s *BackendVerifier) {
+		s.resultCache = cache
+	}
+}
+
+// ResultCacheKey returns the key a request's result is cached under: a hash of the attestation ID,
+// proof, public signals and userContextData
+func ResultCacheKey(input VerifyInput) string {
+	encoded, _ := json.Marshal(VerifyInput{
+		AttestationId:   input.AttestationId,
+		Proof:           input.Proof,
+		PublicSignals:   CanonicalizePublicSignals(input.PublicSignals),
+		UserContextData: input.UserContextData,
+	})
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:])
+}
+
+// cachedResultState reports whether the cached result still answers a request: it was checked
+// against the expected scope and, for built-in attestation types, against the config the store
+// currently resolves the request to. It returns the state to report the cached verification with.
+func (s *BackendVerifier) cachedResultState(
+	ctx context.Context,
+	attestationId AttestationId,
+	cached *VerificationResult,
+	userContextData string,
+) (*verificationState, bool) {
+	// A result checked against another expected scope does not answer this call
+	scope, err := s.expectedScope(ctx)
+	if err != nil || cached.Scope != scope.name {
+		return nil, false
+	}
+	state := &verificationState{
+		configId:     cached.ConfigId,
+		scope:        scope,
+		issuingState: string(documentIssuingState(attestationId, cached.DiscloseOutput)),
+		nationality:  string(documentNationality(attestationId, cached.DiscloseOutput)),
+	}
+	// Custom attestation types are not checked against a config
+	if s.attestationVerifier(attestationId) != nil {
+		return state, true
+	}
+
+	var configIds []string
+	if userContextData == "" && s.anonymousConfigId != "" {
+		configIds = []string{s.anonymousConfigId}
+	} else {
+		if len(userContextData) < 128 {
+			return nil, false
+		}
+		userIdentifier := new(big.Int)
+		userIdentifier.SetString(userContextData[64:128], 16)
+		state.userIdentifier = CastToUserIdentifier(userIdentifier, s.userIdentifierType)
+		configIds, err = callConfigStore(s, ctx, func(ctx context.Context) ([]string, error) {
+			return resolveActionIds(ctx, s.configStorage, ActionIdRequest{
+				UserIdentifier:  state.userIdentifier,
+				UserDefinedData: userContextData[128:],
+				Scope:           scope.name,
+				AttestationId:   attestationId,
+			})
+		})
+		if err != nil {
+			return nil, false
+		}
+	}
+	if !slices.Contains(configIds, cached.ConfigId) {
+		return nil, false
+	}
+	config, err := callConfigStore(s, ctx, func(ctx context.Context) (VerificationConfig, error) {
+		return ResolveConfig(ctx, s.configStorage, cached.ConfigId)
+	})
+	if err != nil || config.Hash() != cached.ConfigHash {
+		return nil, false
+	}
+	state.config = &config
+	return state, true
+}
+
+// cloneVerificationResult returns a copy of result sharing no slices, maps or pointers with it, so
+// cached results are not changed by callers modifying the results they were given
+func cloneVerificationResult(result VerificationResult) VerificationResult {
+	result.OFACOverride = clonePointer(result.OFACOverride)
+	result.ForbiddenCountriesList = slices.Clone(result.ForbiddenCountriesList)
+	result.DiscloseOutput = cloneDiscloseOutput(result.DiscloseOutput)
+	if result.Score != nil {
+		score := *result.Score
+		score.Reasons = slices.Clone(score.Reasons)
+		result.Score = &score
+	}
+	result.AdvisoryIssues = slices.Clone(result.AdvisoryIssues)
+	result.Config = cloneVerificationConfig(result.Config)
+	result.IssuedAsMinor = clonePointer(result.IssuedAsMinor)
+	result.DisclosureStatus = maps.Clone(result.DisclosureStatus)
+	result.disclosedFields = maps.Clone(result.disclosedFields)
+	if result.RawDiscloseOutput != nil {
+		raw := cloneDiscloseOutput(*result.RawDiscloseOutput)
+		result.RawDiscloseOutput = &raw
+	}
+	result.MRZLines = slices.Clone(result.MRZLines)
+	return result
+}
+
+// cloneDiscloseOutput returns a copy of output sharing no slices or pointers with it
+func cloneDiscloseOutput(output GenericDiscloseOutput) GenericDiscloseOutput {
+	output.ForbiddenCountriesListPacked = slices.Clone(output.ForbiddenCountriesListPacked)
+	output.Ofac = slices.Clone(output.Ofac)
+	output.DocumentValid = clonePointer(output.DocumentValid)
+	output.MRZ = clonePointer(output.MRZ)
+	return output
+}
+
+// clonePointer returns a pointer to a copy of *p, or nil
+func clonePointer[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	value := *p
+	return &value
+}
+
+// Get returns a deep copy of the result cached under key, if it has not expired
+func (c *ResultCache) Get(key string) (*VerificationResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(entry.expiresAt) {
+		c.remove(key)
+		return nil, false
+	}
+	result := cloneVerificationResult(entry.result)
+	return &result, true
+}
+
+// Put caches a deep copy of result under key
+func (c *ResultCache) Put(key string, result *VerificationResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if !now.Before(c.nextSweep) {
+		// Expired entries are otherwise only dropped when read, so purge them once per TTL
+		for cachedKey, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				c.remove(cachedKey)
+			}
+		}
+		c.nextSweep = now.Add(c.ttl)
+	}
+	c.remove(key)
+	c.entries[key] = cachedResult{result: cloneVerificationResult(*result), expiresAt: now.Add(c.ttl)}
+	if nullifier := result.DiscloseOutput.Nullifier; nullifier != "" {
+		if c.byNullifier[nullifier] == nil {
+			c.byNullifier[nullifier] = make(map[string]bool)
+		}
+		c.byNullifier[nullifier][key] = true
+	}
+}
+
+// InvalidateResult removes the result cached under key and reports whether there was one
+func (c *ResultCache) InvalidateResult(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.entries[key]
+	c.remove(key)
+	return ok
+}
+
+// InvalidateByNullifier removes every result cached for proofs with the given nullifier, the same
+// document in the same scope, and returns how many were removed
+func (c *ResultCache) InvalidateByNullifier(nullifier string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keys := c.byNullifier[nullifier]
+	removed := len(keys)
+	for key := range keys {
+		c.remove(key)
+	}
+	return removed
+}
+
+// remove deletes the entry under key and its nullifier index; the caller holds c.mu
+func (c *ResultCache) remove(key string) {
+	entry, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	delete(c.entries, key)
+	nullifier := entry.result.DiscloseOutput.Nullifier
+	if keys := c.byNullifier[nullifier]; keys != nil {
+		delete(keys, key)
+		if len(keys) == 0 {
+			delete(c.byNullifier, nullifier)
+		}
+	}
+}
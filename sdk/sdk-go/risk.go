@@ -0,0 +1,44 @@
+package self
+
+import (
+	"strings"
+
+	"github.com/selfxyz/self/sdk/sdk-go/common"
+)
+
+// RiskTier is an application-defined risk classification of a country
+type RiskTier string
+
+// Common risk tiers. Any other value may be registered with WithCountryRiskTiers.
+const (
+	RiskTierUnknown RiskTier = "unknown" // The country is not disclosed or not mapped
+	RiskTierLow     RiskTier = "low"
+	RiskTierMedium  RiskTier = "medium"
+	RiskTierHigh    RiskTier = "high"
+)
+
+// WithCountryRiskTiers reports the risk tier of the document's issuing state and the holder's
+// nationality on every result. Countries missing from tiers are reported as RiskTierUnknown.
+func WithCountryRiskTiers(tiers map[common.Country3LetterCode]RiskTier) VerifierOption {
+	return func(s *BackendVerifier) {
+		s.countryRiskTiers = make(map[common.Country3LetterCode]RiskTier, len(tiers))
+		for country, tier := range tiers {
+			s.countryRiskTiers[country] = tier
+		}
+	}
+}
+
+// riskTierFor returns the registered tier of a disclosed country code
+func (s *BackendVerifier) riskTierFor(country string) RiskTier {
+	country = strings.TrimSpace(removeNullBytes(country))
+	if tier, ok := s.countryRiskTiers[common.Country3LetterCode(country)]; ok {
+		return tier
+	}
+	return RiskTierUnknown
+}
+
+// applyRiskTiers sets the issuing state and nationality risk tiers on result
+func (s *BackendVerifier) applyRiskTiers(result *VerificationResult) {
+	result.IssuingStateRiskTier = s.riskTierFor(result.DiscloseOutput.IssuingState)
+	result.NationalityRiskTier = s.riskTierFor(result.DiscloseOutput.Nationality)
+}
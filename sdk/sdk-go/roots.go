@@ -0,0 +1,49 @@
+package self
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// PinnedRoot is an identity registry root accepted without querying the registry contract
+type PinnedRoot struct {
+	Root *big.Int
+	// ExpiresAt, when set, stops the root from being accepted after that time, e.g. at the end of a rotation window
+	ExpiresAt time.Time
+}
+
+// checkPinnedRoots checks merkleRoot against the roots pinned for attestationId, logging the
+// matching root. It reports false if no root is pinned for attestationId.
+func (s *BackendVerifier) checkPinnedRoots(
+	attestationId AttestationId,
+	merkleRoot *big.Int,
+	now time.Time,
+	issues *[]ConfigIssue,
+) bool {
+	roots, pinned := s.pinnedRoots[attestationId]
+	if !pinned {
+		return false
+	}
+
+	for i, root := range roots {
+		if root.Root == nil || root.Root.Cmp(merkleRoot) != 0 {
+			continue
+		}
+		if !root.ExpiresAt.IsZero() && !now.Before(root.ExpiresAt) {
+			*issues = append(*issues, ConfigIssue{
+				Type:    InvalidRoot,
+				Message: fmt.Sprintf("Pinned root %s expired at %s", merkleRoot, root.ExpiresAt.UTC().Format(time.RFC3339)),
+			})
+			return true
+		}
+		s.logger.Printf("self: attestation %d proof matched pinned root %d of %d: %s", attestationId, i+1, len(roots), merkleRoot)
+		return true
+	}
+
+	*issues = append(*issues, ConfigIssue{
+		Type:    InvalidRoot,
+		Message: fmt.Sprintf("Root is not one of the pinned roots, received: %s", merkleRoot),
+	})
+	return true
+}
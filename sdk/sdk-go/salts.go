@@ -0,0 +1,68 @@
+package self
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrSaltMismatch is returned when the disclosure salts sent with a proof do not open its field
+// commitments, or are sent for an attestation type without salted fields
+var ErrSaltMismatch = errors.New("disclosure salts do not match the proof's field commitments")
+
+// disclosureSaltsKey is the context key under which ContextWithDisclosureSalts stores the salts
+type disclosureSaltsKey struct{}
+
+// ContextWithDisclosureSalts returns a copy of ctx under which Verify checks the proof's field
+// commitments against salts, keyed by the field they open. VerifyInput.DisclosureSalts is passed
+// this way by VerifyHandler and the Verify* methods taking a VerifyInput.
+//
+// None of the built-in attestation types commit salted fields: their circuits disclose fields in
+// clear, so salts sent for them are rejected with ErrSaltMismatch rather than ignored. Salted
+// disclosure is supported by custom attestation types whose AttestationVerifier implements
+// SaltedDisclosureVerifier, for the fields it commits.
+func ContextWithDisclosureSalts(ctx context.Context, salts map[DisclosureField]string) context.Context {
+	return context.WithValue(ctx, disclosureSaltsKey{}, salts)
+}
+
+// DisclosureSaltsFromContext returns the salts stored by ContextWithDisclosureSalts, if any
+func DisclosureSaltsFromContext(ctx context.Context) map[DisclosureField]string {
+	salts, _ := ctx.Value(disclosureSaltsKey{}).(map[DisclosureField]string)
+	return salts
+}
+
+// SaltedDisclosureVerifier is implemented by AttestationVerifiers whose proofs commit to salted field
+// hashes, to check the salts sent with a proof
+type SaltedDisclosureVerifier interface {
+	// VerifyDisclosureSalts checks that each salt, with the disclosed value of its field in result,
+	// opens the field's commitment in publicSignals. It returns an error matching ErrSaltMismatch
+	// when one does not or the field is not committed.
+	VerifyDisclosureSalts(ctx context.Context, result *VerificationResult, publicSignals []string, salts map[DisclosureField]string) error
+}
+
+// verifyDisclosureSalts checks the salts of ctx against a result of the custom verifier v
+func verifyDisclosureSalts(ctx context.Context, v AttestationVerifier, attestationId AttestationId, result *VerificationResult, publicSignals []string) error {
+	salts := DisclosureSaltsFromContext(ctx)
+	if len(salts) == 0 {
+		return nil
+	}
+	salted, ok := v.(SaltedDisclosureVerifier)
+	if !ok {
+		return noSaltedFieldsError(attestationId)
+	}
+	return salted.VerifyDisclosureSalts(ctx, result, publicSignals, salts)
+}
+
+// noSaltedFieldsError returns the ErrSaltMismatch reported for salts sent with a proof of an
+// attestation type without salted fields
+func noSaltedFieldsError(attestationId AttestationId) error {
+	return fmt.Errorf("%w: attestation type %d commits no salted fields", ErrSaltMismatch, attestationId)
+}
+
+// contextWithInputSalts returns ctx carrying the disclosure salts of input, if it has any
+func contextWithInputSalts(ctx context.Context, input VerifyInput) context.Context {
+	if len(input.DisclosureSalts) == 0 {
+		return ctx
+	}
+	return ContextWithDisclosureSalts(ctx, input.DisclosureSalts)
+}
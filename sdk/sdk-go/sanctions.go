@@ -0,0 +1,47 @@
+package self
+
+import (
+	"fmt"
+	"strings"
+)
+
+// validateSanctionedNationalities checks that the proof enforces the config's sanctioned nationalities
+// in its forbidden countries list, and that a disclosed nationality is not sanctioned
+func validateSanctionedNationalities(
+	config VerificationConfig,
+	forbiddenCountriesList []string,
+	discloseOutput GenericDiscloseOutput,
+	issues *[]ConfigIssue,
+) {
+	if len(config.SanctionedNationalities) == 0 {
+		return
+	}
+
+	forbidden := make(map[string]bool, len(forbiddenCountriesList))
+	for _, country := range forbiddenCountriesList {
+		forbidden[country] = true
+	}
+
+	var unenforced []string
+	nationality := strings.TrimSpace(removeNullBytes(discloseOutput.Nationality))
+	for _, country := range config.SanctionedNationalities {
+		if string(country) == nationality {
+			*issues = append(*issues, ConfigIssue{
+				Type:    InvalidSanctionedNationality,
+				Message: fmt.Sprintf("Nationality %s is sanctioned", nationality),
+			})
+			return
+		}
+		if !forbidden[string(country)] {
+			unenforced = append(unenforced, string(country))
+		}
+	}
+
+	if len(unenforced) > 0 {
+		*issues = append(*issues, ConfigIssue{
+			Type: InvalidSanctionedNationality,
+			Message: fmt.Sprintf("Sanctioned nationalities are not in the circuit's forbidden countries list\nCircuit: %s\nMissing: %s",
+				strings.Join(forbiddenCountriesList, ", "), strings.Join(unenforced, ", ")),
+		})
+	}
+}
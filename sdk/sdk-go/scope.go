@@ -0,0 +1,77 @@
+package self
+
+import (
+	"context"
+	"fmt"
+
+	commonUtils "github.com/selfxyz/self/sdk/sdk-go/common"
+)
+
+// ErrScopeNotAllowed is returned when a call expects a scope the verifier was not configured to
+// accept with WithAllowedScopes. It matches ErrScopeMismatch.
+var ErrScopeNotAllowed = fmt.Errorf("%w: expected scope is not allowed by the verifier", ErrScopeMismatch)
+
+// verifierScope is a scope name together with its hash under the verifier's endpoint
+type verifierScope struct {
+	name string
+	hash string
+}
+
+// WithAllowedScopes lets calls expect one of scopes instead of the verifier's own scope, selected
+// per call with ContextWithExpectedScope. Each scope is hashed with the verifier's endpoint, so one
+// verifier serves several apps sharing an endpoint while every proof is still checked against a
+// single expected scope.
+func WithAllowedScopes(scopes ...string) VerifierOption {
+	return func(s *BackendVerifier) {
+		s.allowedScopeNames = append(s.allowedScopeNames, scopes...)
+	}
+}
+
+// expectedScopeKey is the context key under which ContextWithExpectedScope stores the scope
+type expectedScopeKey struct{}
+
+// ContextWithExpectedScope returns a copy of ctx under which Verify expects proofs for scope rather
+// than the verifier's own. The scope must be the verifier's or one of its WithAllowedScopes, else
+// Verify fails with ErrScopeNotAllowed.
+func ContextWithExpectedScope(ctx context.Context, scope string) context.Context {
+	return context.WithValue(ctx, expectedScopeKey{}, scope)
+}
+
+// hashAllowedScopes hashes the scopes added with WithAllowedScopes
+func (s *BackendVerifier) hashAllowedScopes() error {
+	if len(s.allowedScopeNames) == 0 {
+		return nil
+	}
+	s.allowedScopes = make(map[string]string, len(s.allowedScopeNames))
+	for _, name := range s.allowedScopeNames {
+		hash, err := commonUtils.HashEndpointWithScope(s.endpoint, name)
+		if err != nil {
+			return fmt.Errorf("failed to hash endpoint with allowed scope %q: %v", name, err)
+		}
+		s.allowedScopes[name] = hash
+	}
+	return nil
+}
+
+// defaultScope returns the scope the verifier was constructed with
+func (s *BackendVerifier) defaultScope() verifierScope {
+	return verifierScope{name: s.scopeName, hash: s.scope}
+}
+
+// lookupScope returns the allowed scope named name, the verifier's own scope when name is empty
+func (s *BackendVerifier) lookupScope(name string) (verifierScope, error) {
+	if name == "" || name == s.scopeName {
+		return s.defaultScope(), nil
+	}
+	hash, ok := s.allowedScopes[name]
+	if !ok {
+		return verifierScope{}, fmt.Errorf("%w: %q", ErrScopeNotAllowed, name)
+	}
+	return verifierScope{name: name, hash: hash}, nil
+}
+
+// expectedScope returns the scope a call expects, from ContextWithExpectedScope or else the verifier's own
+func (s *BackendVerifier) expectedScope(ctx context.Context) (verifierScope, error) {
+	name, _ := ctx.Value(expectedScopeKey{}).(string)
+	return s.lookupScope(name)
+}
@@ -0,0 +1,13 @@
+package self
+
+import "context"
+
+// VerificationScore is advisory risk metadata attached to a verification result.
+// It never affects IsValidDetails, which remains the hard pass/fail outcome.
+type VerificationScore struct {
+	Score   float64  `json:"score"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// ScoringFunc computes an advisory score for a valid proof from its disclosed data and check breakdown
+type ScoringFunc func(ctx context.Context, discloseOutput GenericDiscloseOutput, checks IsValidDetails) VerificationScore
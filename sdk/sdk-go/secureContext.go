@@ -0,0 +1,77 @@
+package self
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// secureTransportKey is the context key under which ContextWithSecureTransport marks the transport secure
+type secureTransportKey struct{}
+
+// ContextWithSecureTransport returns a copy of ctx asserting that the result of Verify is returned
+// over a secure transport, for verifiers created with WithRequireSecureContextForDisclosure. Set it
+// where TLS is known to protect the response, e.g. with SecureTransportMiddleware.
+func ContextWithSecureTransport(ctx context.Context) context.Context {
+	return context.WithValue(ctx, secureTransportKey{}, true)
+}
+
+// IsSecureTransport reports whether ctx was marked by ContextWithSecureTransport
+func IsSecureTransport(ctx context.Context) bool {
+	secure, _ := ctx.Value(secureTransportKey{}).(bool)
+	return secure
+}
+
+// WithRequireSecureContextForDisclosure withholds disclosed data from results unless the context
+// passed to Verify is marked by ContextWithSecureTransport, guarding against PII being returned over
+// plaintext by mistake. Without the marker every disclosed attribute is blanked out, as are
+// RawDiscloseOutput and MRZLines, DisclosureWithheld is set and DisclosureStatus reports the fields
+// withheld; validity, predicate results (minimum age, OFAC, forbidden countries) and the nullifier
+// are still returned. Off by default.
+func WithRequireSecureContextForDisclosure() VerifierOption {
+	return func(s *BackendVerifier) {
+		s.requireSecureDisclosure = true
+	}
+}
+
+// SecureTransportMiddleware marks the context of requests received over TLS with
+// ContextWithSecureTransport. Behind a TLS-terminating proxy, set trustForwardedProto to also mark
+// requests the proxy forwards with "X-Forwarded-Proto: https"; only do so when the proxy overwrites
+// that header, since clients can send it too.
+func SecureTransportMiddleware(trustForwardedProto bool) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.TLS != nil || (trustForwardedProto && strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")) {
+				r = r.WithContext(ContextWithSecureTransport(r.Context()))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// withheldDisclosure returns a copy of result without its disclosed data, unless the verifier does
+// not require a secure context or ctx is marked secure
+func (s *BackendVerifier) withheldDisclosure(ctx context.Context, result *VerificationResult) *VerificationResult {
+	if !s.requireSecureDisclosure || result == nil || IsSecureTransport(ctx) {
+		return result
+	}
+	s.logger.Printf("self: disclosed data withheld from the verification result, the context is not marked secure")
+
+	withheld := *result
+	withheld.DiscloseOutput = maskDisclosureFields(result.DiscloseOutput, AllDisclosureFields)
+	withheld.DiscloseOutput.DateOfBirthISO = ""
+	withheld.DiscloseOutput.ExpiryDateISO = ""
+	withheld.RawDiscloseOutput = nil
+	withheld.MRZLines = nil
+	withheld.DisclosureWithheld = true
+	if result.DisclosureStatus != nil {
+		withheld.DisclosureStatus = make(map[DisclosureField]DisclosureStatus, len(result.DisclosureStatus))
+		for field, status := range result.DisclosureStatus {
+			if status == DisclosureStatusDisclosed {
+				status = DisclosureStatusWithheld
+			}
+			withheld.DisclosureStatus[field] = status
+		}
+	}
+	return &withheld
+}
@@ -0,0 +1,192 @@
+// Package selflambda serves a self.VerifyHandler from AWS Lambda behind an API Gateway proxy
+// integration (REST APIs, or HTTP APIs with payload format 1.0).
+//
+// The event types mirror the JSON of aws-lambda-go's events.APIGatewayProxyRequest and
+// events.APIGatewayProxyResponse, so Handler.Invoke can be passed to lambda.Start without this
+// package depending on aws-lambda-go:
+//
+//	func main() {
+//		verifier, err := self.NewBackendVerifierFromEnv(configStore)
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//		handler := selflambda.NewHandler(self.NewVerifyHandler(verifier))
+//		handler.Warmup = func(ctx context.Context) error {
+//			return verifier.Warmup(ctx, "adults")
+//		}
+//		lambda.Start(handler.Invoke)
+//	}
+package selflambda
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+// APIGatewayProxyRequest is the subset of an API Gateway proxy event used to build the HTTP request
+type APIGatewayProxyRequest struct {
+	HTTPMethod        string              `json:"httpMethod"`
+	Path              string              `json:"path"`
+	Headers           map[string]string   `json:"headers"`
+	MultiValueHeaders map[string][]string `json:"multiValueHeaders"`
+	Body              string              `json:"body"`
+	IsBase64Encoded   bool                `json:"isBase64Encoded"`
+}
+
+// APIGatewayProxyResponse is the response returned to API Gateway
+type APIGatewayProxyResponse struct {
+	StatusCode        int                 `json:"statusCode"`
+	Headers           map[string]string   `json:"headers"`
+	MultiValueHeaders map[string][]string `json:"multiValueHeaders"`
+	Body              string              `json:"body"`
+	IsBase64Encoded   bool                `json:"isBase64Encoded,omitempty"`
+}
+
+// Handler adapts an http.Handler, typically a self.VerifyHandler wrapped in any middleware, to API
+// Gateway proxy events, so requests are parsed and verified exactly as by the HTTP server
+type Handler struct {
+	handler http.Handler
+
+	// Warmup, when set, runs before the first invocation is served, e.g. BackendVerifier.Warmup to
+	// load configs during the cold start. A failure fails that invocation and is retried on the next.
+	Warmup func(ctx context.Context) error
+
+	warmupMu sync.Mutex
+	warmedUp bool
+}
+
+// NewHandler creates a Handler serving events with handler
+func NewHandler(handler http.Handler) *Handler {
+	return &Handler{handler: handler}
+}
+
+// Invoke serves one API Gateway proxy event. Only a failed warmup returns an error; failures of the
+// request itself are reported in the response, with the status and ErrorResponse of the HTTP handler.
+func (h *Handler) Invoke(ctx context.Context, event APIGatewayProxyRequest) (APIGatewayProxyResponse, error) {
+	if err := h.warmup(ctx); err != nil {
+		return APIGatewayProxyResponse{}, err
+	}
+
+	request, err := newHTTPRequest(ctx, event)
+	if err != nil {
+		return errorResponse(http.StatusBadRequest, err.Error()), nil
+	}
+	recorder := &responseRecorder{header: make(http.Header)}
+	h.handler.ServeHTTP(recorder, request)
+	return recorder.response(), nil
+}
+
+// warmup runs Warmup until it succeeds once
+func (h *Handler) warmup(ctx context.Context) error {
+	if h.Warmup == nil {
+		return nil
+	}
+	h.warmupMu.Lock()
+	defer h.warmupMu.Unlock()
+	if h.warmedUp {
+		return nil
+	}
+	if err := h.Warmup(ctx); err != nil {
+		return fmt.Errorf("selflambda: warmup failed: %w", err)
+	}
+	h.warmedUp = true
+	return nil
+}
+
+// newHTTPRequest builds the HTTP request described by event
+func newHTTPRequest(ctx context.Context, event APIGatewayProxyRequest) (*http.Request, error) {
+	body := []byte(event.Body)
+	if event.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(event.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 body: %v", err)
+		}
+		body = decoded
+	}
+	path := event.Path
+	if path == "" {
+		path = "/"
+	}
+
+	request, err := http.NewRequestWithContext(ctx, event.HTTPMethod, path, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %v", err)
+	}
+	// Multi-value headers hold every value when API Gateway sends both
+	for name, value := range event.Headers {
+		request.Header.Set(name, value)
+	}
+	for name, values := range event.MultiValueHeaders {
+		request.Header.Del(name)
+		for _, value := range values {
+			request.Header.Add(name, value)
+		}
+	}
+	return request, nil
+}
+
+// errorResponse returns an INVALID_REQUEST ErrorResponse for events that cannot be served
+func errorResponse(status int, message string) APIGatewayProxyResponse {
+	body, _ := json.Marshal(self.NewErrorResponse(self.ErrorCodeInvalidRequest, message))
+	return APIGatewayProxyResponse{
+		StatusCode:        status,
+		Headers:           map[string]string{"Content-Type": "application/json"},
+		MultiValueHeaders: map[string][]string{"Content-Type": {"application/json"}},
+		Body:              string(body),
+	}
+}
+
+// responseRecorder is an http.ResponseWriter buffering the response for API Gateway
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return r.body.Write(p)
+}
+
+// response returns the recorded response. Bodies that are not text are base64 encoded.
+func (r *responseRecorder) response() APIGatewayProxyResponse {
+	response := APIGatewayProxyResponse{
+		StatusCode:        r.status,
+		Headers:           make(map[string]string, len(r.header)),
+		MultiValueHeaders: make(map[string][]string, len(r.header)),
+	}
+	if response.StatusCode == 0 {
+		response.StatusCode = http.StatusOK
+	}
+	for name, values := range r.header {
+		response.Headers[name] = strings.Join(values, ",")
+		response.MultiValueHeaders[name] = values
+	}
+
+	contentType := r.header.Get("Content-Type")
+	if strings.HasPrefix(contentType, "application/json") || strings.HasPrefix(contentType, "text/") {
+		response.Body = r.body.String()
+	} else {
+		response.Body = base64.StdEncoding.EncodeToString(r.body.Bytes())
+		response.IsBase64Encoded = r.body.Len() > 0
+	}
+	return response
+}
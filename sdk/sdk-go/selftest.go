@@ -0,0 +1,131 @@
+package self
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrSelfTestFailed is returned by SelfTest when the verifier is misconfigured or its network is unreachable
+var ErrSelfTestFailed = errors.New("verifier self-test failed")
+
+// selfTestProof is a known-good passport proof generated by the Self app, checked by SelfTest
+var selfTestProof = VcAndDiscloseProof{
+	A: [2]string{
+		"19978035591559142190701827820645990013414633793180672686938226685776304489564",
+		"5729195691952204724157922378821526527130089592215448275678040621795037604051",
+	},
+	B: [2][2]string{
+		{
+			"11751985993692270888240656856501733091634778410910150825443605743432104365496",
+			"4452136363546266459130979435587765558483594623092208966946297079596510893605",
+		},
+		{
+			"3810657409440735818003229201852551662656469950107499750244154014975554267923",
+			"10470222606272472527954481346783037896628046865041659088202192358643101806862",
+		},
+	},
+	C: [2]string{
+		"15884364794774631813944040023461646992309624876334078534233455116862274883339",
+		"20393368791665166818799823852194418481289576790771157544865526424140268474306",
+	},
+}
+
+// selfTestPublicSignals are the public signals of selfTestProof
+var selfTestPublicSignals = []string{
+	"0",
+	"88695642300982331844063832786964092168707990538423248083901435067469135872",
+	"5917645764266387229099807922771871753544163856784761583567435202615",
+	"4936272",
+	"0",
+	"0",
+	"0",
+	"13444167391765850209653844241387268774183214285042803350347364004811481522835",
+	"1",
+	"3128220823265944096261447595696332812503333375431456287926106302900687520341",
+	"2",
+	"5",
+	"0",
+	"8",
+	"1",
+	"2",
+	"17359956125106148146828355805271472653597249114301196742546733002427978706344",
+	"7420120618403967585712321281997181302561301414016003514649937965499789236588",
+	"16836358042995742879630198413873414945978677264752036026400967422611478610995",
+	"13934606664243914063643606771911468856671016933765586820821710153612586828695",
+	"333950092602874832043713879344132078365835356296",
+}
+
+// SelfTest checks that the verifier is correctly configured, e.g. on startup to fail fast on a bad
+// deploy. It checks that every allowed built-in attestation type has a registry and a verifier
+// contract on the configured network and, when passports are allowed, that a bundled known-good
+// passport proof verifies. Only the proof itself is checked, not its timestamp, scope or config.
+//
+// Returns an error matching ErrSelfTestFailed that describes the first problem found.
+func (s *BackendVerifier) SelfTest(ctx context.Context) error {
+	callOpts := &bind.CallOpts{Context: ctx}
+
+	var attestationIds []AttestationId
+	for id, allowed := range s.allowedIDs {
+		if allowed && AllIds[id] {
+			attestationIds = append(attestationIds, id)
+		}
+	}
+	sort.Slice(attestationIds, func(i, j int) bool { return attestationIds[i] < attestationIds[j] })
+
+	for _, id := range attestationIds {
+		idBytes32 := attestationIdToBytes32(id)
+		registryAddress, err := s.identityVerificationHubContract.Registry(callOpts, idBytes32)
+		if err != nil {
+			return fmt.Errorf("%w: registry for attestation ID %d is unreachable: %v", ErrSelfTestFailed, id, err)
+		}
+		if registryAddress == (common.Address{}) {
+			return fmt.Errorf("%w: no registry is deployed for attestation ID %d", ErrSelfTestFailed, id)
+		}
+
+		verifierAddress, err := s.identityVerificationHubContract.DiscloseVerifier(callOpts, idBytes32)
+		if err != nil {
+			return fmt.Errorf("%w: verifier for attestation ID %d is unreachable: %v", ErrSelfTestFailed, id, err)
+		}
+		if verifierAddress == (common.Address{}) {
+			return fmt.Errorf("%w: no verifier is deployed for attestation ID %d", ErrSelfTestFailed, id)
+		}
+	}
+
+	if s.allowedIDs[Passport] {
+		layout, err := s.circuitLayouts.detect(Passport, selfTestPublicSignals)
+		if err != nil {
+			return fmt.Errorf("%w: bundled passport proof has an unsupported layout: %v", ErrSelfTestFailed, err)
+		}
+		valid, err := s.checkProof(callOpts, Passport, layout, attestationIdToBytes32(Passport), selfTestProof, selfTestPublicSignals)
+		if err != nil {
+			return fmt.Errorf("%w: bundled passport proof could not be checked: %v", ErrSelfTestFailed, err)
+		}
+		if !valid {
+			return fmt.Errorf("%w: bundled passport proof does not verify", ErrSelfTestFailed)
+		}
+	}
+	return nil
+}
+
+// attestationIdToBytes32 encodes an attestation ID as the bytes32 key used by the hub contract
+func attestationIdToBytes32(id AttestationId) [32]byte {
+	var idBytes32 [32]byte
+	copy(idBytes32[:], common.FromHex(fmt.Sprintf("0x%064x", id)))
+	return idBytes32
+}
+
+// Warmup prepares a standby instance for traffic by loading the configs with the given IDs ahead of
+// use, through PrefetchConfigs when the config store is a ConfigPrefetcher such as
+// ChainedConfigStore. Together with SelfTest it lets a deploy assert an instance is fully ready
+// before cutover. The returned error matches ErrConfigNotFound for IDs that could not be loaded.
+func (s *BackendVerifier) Warmup(ctx context.Context, configIds ...string) error {
+	if err := prefetchConfigs(ctx, s.configStorage, configIds); err != nil {
+		return fmt.Errorf("warmup failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package self
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// SessionTimestampSize is the size in bytes of the session timestamp starting the user defined data
+const SessionTimestampSize = 8
+
+// SessionTimestampFunc extracts the session start bound into a request's user defined data (hex)
+type SessionTimestampFunc func(userDefinedData string) (time.Time, error)
+
+// WithSessionTimestamp rejects proofs generated before the session they are submitted for started,
+// such as proofs generated ahead of time and stockpiled, with ErrProofPredatesSession. The session
+// start is extracted from the user defined data with extract, or ParseSessionTimestamp when nil.
+//
+// Proofs are dated to the day, so a proof is accepted when it was generated on or after the UTC day
+// the session started. Proofs carrying no date and requests without a valid timestamp are rejected.
+// Anonymous proofs carry no context data and are not checked.
+//
+// The user context hash committed in the proof binds the timestamp to it, but clients can still
+// choose an early timestamp: sign the context data (WithContextDataVerifier) so only timestamps
+// issued by the backend are accepted.
+func WithSessionTimestamp(extract SessionTimestampFunc) VerifierOption {
+	return func(s *BackendVerifier) {
+		if extract == nil {
+			extract = ParseSessionTimestamp
+		}
+		s.sessionTimestamp = extract
+	}
+}
+
+// EncodeSessionTimestamp returns the hex encoding of t that ParseSessionTimestamp reads: the Unix
+// time in seconds as SessionTimestampSize big-endian bytes. Start the user defined data with it.
+func EncodeSessionTimestamp(t time.Time) string {
+	var encoded [SessionTimestampSize]byte
+	binary.BigEndian.PutUint64(encoded[:], uint64(t.Unix()))
+	return hex.EncodeToString(encoded[:])
+}
+
+// ParseSessionTimestamp reads the session timestamp written by EncodeSessionTimestamp from the start
+// of the user defined data
+func ParseSessionTimestamp(userDefinedData string) (time.Time, error) {
+	if len(userDefinedData) < 2*SessionTimestampSize {
+		return time.Time{}, fmt.Errorf("user defined data is too short to carry a session timestamp")
+	}
+	encoded, err := hex.DecodeString(userDefinedData[:2*SessionTimestampSize])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid session timestamp: %v", err)
+	}
+	return time.Unix(int64(binary.BigEndian.Uint64(encoded)), 0).UTC(), nil
+}
+
+// validateSessionTimestamp checks that the proof was generated no earlier than the day the session
+// bound into userDefinedData started
+func (s *BackendVerifier) validateSessionTimestamp(
+	attestationId AttestationId,
+	userDefinedData string,
+	publicSignals []string,
+	discloseIndices DiscloseIndicesEntry,
+	issues *[]ConfigIssue,
+) {
+	sessionStart, err := s.sessionTimestamp(userDefinedData)
+	if err != nil {
+		*issues = append(*issues, ConfigIssue{
+			Type:    InvalidTimestamp,
+			Message: fmt.Sprintf("userContextData carries no valid session timestamp: %v", err),
+			Err:     ErrProofPredatesSession,
+		})
+		return
+	}
+	proofDate, ok := circuitDate(attestationId, publicSignals, discloseIndices)
+	if !ok {
+		*issues = append(*issues, ConfigIssue{
+			Type:    InvalidTimestamp,
+			Message: "Proof carries no date to compare with the session start",
+			Err:     ErrProofPredatesSession,
+		})
+		return
+	}
+
+	sessionDay := sessionStart.UTC().Truncate(24 * time.Hour)
+	if proofDate.Before(sessionDay) {
+		*issues = append(*issues, ConfigIssue{
+			Type: InvalidTimestamp,
+			Message: fmt.Sprintf("Proof generated on %s, before the session started on %s",
+				proofDate.Format(isoDateLayout), sessionDay.Format(isoDateLayout)),
+			Err: ErrProofPredatesSession,
+		})
+	}
+}
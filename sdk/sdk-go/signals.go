@@ -0,0 +1,99 @@
+package self
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ErrMisorderedPublicSignals is returned when public signals are not in the order produced by the circuit
+var ErrMisorderedPublicSignals = errors.New("public signals are not in circuit order")
+
+// ErrTooManyPublicSignals is returned when more public signals are decoded or verified than the limit allows
+var ErrTooManyPublicSignals = errors.New("too many public signals")
+
+// DefaultMaxPublicSignals is the default limit on the public signals decoded from JSON and accepted by
+// Verify, comfortably above the largest circuit layout. See WithMaxPublicSignals and DecodeLimits.
+const DefaultMaxPublicSignals = 64
+
+// CanonicalizePublicSignals returns the public signals in canonical form: base-10 strings without
+// leading zeros. Hex signals (with or without a 0x prefix) are converted to base 10.
+//
+// Public signals are positional, so their order is preserved and never sorted: two inputs describe
+// the same verification only if they are equal signal by signal after canonicalization.
+// Signals that are not valid integers are returned unchanged so verification can report them.
+func CanonicalizePublicSignals(publicSignals []string) []string {
+	canonical := make([]string, len(publicSignals))
+	for i, signal := range publicSignals {
+		value, ok := parsePublicSignal(signal)
+		if !ok {
+			canonical[i] = signal
+			continue
+		}
+		canonical[i] = value.String()
+	}
+	return canonical
+}
+
+// parsePublicSignal parses a signal as base 10, or as hex when prefixed with 0x or containing hex digits
+func parsePublicSignal(signal string) (*big.Int, bool) {
+	signal = strings.TrimSpace(signal)
+	if signal == "" {
+		return nil, false
+	}
+
+	base := 10
+	if strings.HasPrefix(signal, "0x") || strings.HasPrefix(signal, "0X") {
+		signal = signal[2:]
+		base = 16
+	} else if containsHexChars(signal) {
+		base = 16
+	}
+
+	value, ok := new(big.Int).SetString(signal, base)
+	if !ok || value.Sign() < 0 {
+		return nil, false
+	}
+	return value, true
+}
+
+// ValidatePublicSignalsOrder checks the positional structure of canonical public signals against
+// the circuit layout, catching signals that were reordered (e.g. sorted) by the client.
+//
+// The current date digits and the attestation ID slot have a small, known value range, so a
+// misplaced signal is detected with high probability.
+func ValidatePublicSignalsOrder(layout CircuitLayout, attestationId AttestationId, publicSignals []string) error {
+	if len(publicSignals) < layout.PublicSignalsCount {
+		return fmt.Errorf("%w: expected %d signals, got %d", ErrMisorderedPublicSignals, layout.PublicSignalsCount, len(publicSignals))
+	}
+
+	attestationIdIndex := layout.Indices.AttestationIdIndex
+	if !isKnownAttestationSignal(publicSignals[attestationIdIndex]) {
+		return fmt.Errorf("%w: signal %d is not an attestation ID: %s",
+			ErrMisorderedPublicSignals, attestationIdIndex, publicSignals[attestationIdIndex])
+	}
+
+	// Passport and EU ID card circuits expose the current date as six single-digit signals (YYMMDD)
+	if attestationId == Passport || attestationId == EUCard {
+		for i := 0; i < 6; i++ {
+			index := layout.Indices.CurrentDateIndex + i
+			if len(publicSignals[index]) != 1 || publicSignals[index][0] < '0' || publicSignals[index][0] > '9' {
+				return fmt.Errorf("%w: signal %d is not a date digit: %s",
+					ErrMisorderedPublicSignals, index, publicSignals[index])
+			}
+		}
+	}
+
+	return nil
+}
+
+// isKnownAttestationSignal reports whether a canonical signal holds a known attestation ID
+func isKnownAttestationSignal(signal string) bool {
+	for id := range AllIds {
+		if signal == fmt.Sprintf("%d", id) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package self
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// ErrContextDataSignatureInvalid is reported when userContextData does not carry a valid signature
+// from the key of the verifier's ContextDataVerifier
+var ErrContextDataSignatureInvalid = errors.New("userContextData signature is invalid")
+
+// ContextDataSignatureSize is the size in bytes of the signature ending userContextData
+const ContextDataSignatureSize = 64
+
+// SignatureAlgorithm identifies the algorithm of a ContextDataVerifier
+type SignatureAlgorithm string
+
+const (
+	// SignatureAlgorithmEd25519 verifies Ed25519 signatures with an ed25519.PublicKey
+	SignatureAlgorithmEd25519 SignatureAlgorithm = "ed25519"
+	// SignatureAlgorithmECDSAP256 verifies ECDSA P-256 signatures over the SHA-256 digest, encoded as
+	// 32-byte big-endian r followed by 32-byte s, with an *ecdsa.PublicKey
+	SignatureAlgorithmECDSAP256 SignatureAlgorithm = "ecdsa-p256"
+)
+
+// ContextDataVerifier checks that userContextData was issued by the backend, so clients cannot swap
+// in arbitrary context data. The backend appends a ContextDataSignatureSize-byte signature over the
+// preceding bytes to the user defined data; the signature is part of the user defined data passed to
+// the ConfigStore.
+type ContextDataVerifier struct {
+	Algorithm SignatureAlgorithm
+	// PublicKey is an ed25519.PublicKey or an *ecdsa.PublicKey, depending on Algorithm
+	PublicKey interface{}
+}
+
+// Verify checks the signature ending the decoded userContextData
+func (v ContextDataVerifier) Verify(userContextData []byte) error {
+	// The signature must follow the config ID and user identifier
+	if len(userContextData) < 64+ContextDataSignatureSize {
+		return fmt.Errorf("%w: userContextData is too short to carry a signature", ErrContextDataSignatureInvalid)
+	}
+	split := len(userContextData) - ContextDataSignatureSize
+	message, signature := userContextData[:split], userContextData[split:]
+
+	switch v.Algorithm {
+	case SignatureAlgorithmEd25519:
+		publicKey, ok := v.PublicKey.(ed25519.PublicKey)
+		if !ok || len(publicKey) != ed25519.PublicKeySize {
+			return fmt.Errorf("%w: %s requires an ed25519.PublicKey", ErrContextDataSignatureInvalid, v.Algorithm)
+		}
+		if !ed25519.Verify(publicKey, message, signature) {
+			return ErrContextDataSignatureInvalid
+		}
+	case SignatureAlgorithmECDSAP256:
+		publicKey, ok := v.PublicKey.(*ecdsa.PublicKey)
+		if !ok || publicKey == nil {
+			return fmt.Errorf("%w: %s requires an *ecdsa.PublicKey", ErrContextDataSignatureInvalid, v.Algorithm)
+		}
+		digest := sha256.Sum256(message)
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(publicKey, digest[:], r, s) {
+			return ErrContextDataSignatureInvalid
+		}
+	default:
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrContextDataSignatureInvalid, v.Algorithm)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package self
+
+import (
+	"context"
+	"fmt"
+)
+
+// configSnapshotKey is the context key under which VerifyWithConfig passes its config to verify
+type configSnapshotKey struct{}
+
+// VerifyWithConfig verifies input against config instead of the config resolved from the store, e.g.
+// to re-evaluate a past verification under the policy in effect at the time. The store is not
+// called, the result's ConfigId is empty and its ConfigHash identifies the snapshot. Results are
+// never answered from or added to the result cache.
+//
+// The snapshot must be a resolved config without a Parent (ErrConfigInheritance), such as the
+// Config of an earlier VerificationResult. Time-dependent checks still apply at the current time,
+// so older proofs need a verifier with a longer WithMaxProofAge, or CheckTimestamp in the
+// snapshot's AdvisoryChecks.
+func (s *BackendVerifier) VerifyWithConfig(ctx context.Context, input VerifyInput, config VerificationConfig) (*VerificationResult, error) {
+	if config.Parent != "" {
+		return nil, fmt.Errorf("%w: config snapshot inherits from %s, pass the resolved config", ErrConfigInheritance, config.Parent)
+	}
+	ctx = context.WithValue(ctx, configSnapshotKey{}, cloneVerificationConfig(config))
+	return s.verifyInput(ctx, input)
+}
+
+// configSnapshotFromContext returns the config passed to VerifyWithConfig, if any
+func configSnapshotFromContext(ctx context.Context) (VerificationConfig, bool) {
+	config, ok := ctx.Value(configSnapshotKey{}).(VerificationConfig)
+	return config, ok
+}
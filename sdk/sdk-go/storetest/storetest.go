@@ -0,0 +1,193 @@
+// Package storetest checks ConfigStore implementations against the concurrency contract documented
+// on self.ConfigStore, by hammering a store with concurrent reads and writes.
+//
+// Call TestConfigStore from a test of the implementation and run it with the race detector:
+//
+//	func TestDatabaseConfigStore(t *testing.T) {
+//		storetest.TestConfigStore(t, func(t *testing.T) self.ConfigStore {
+//			return newDatabaseConfigStore(t)
+//		})
+//	}
+package storetest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+	"github.com/selfxyz/self/sdk/sdk-go/common"
+)
+
+const (
+	// goroutines is how many goroutines read and write concurrently in each check
+	goroutines = 8
+	// iterations is how many calls each goroutine makes
+	iterations = 50
+)
+
+// countries are the excluded countries of the configs written by the checks, a prefix of which
+// each config excludes
+var countries = []common.Country3LetterCode{common.AFG, common.ALB, common.DZA, common.AND, common.AGO}
+
+// TestConfigStore runs the contract checks as subtests. newStore is called once per subtest and
+// must return an empty store; it should register any cleanup with t.Cleanup.
+func TestConfigStore(t *testing.T, newStore func(t *testing.T) self.ConfigStore) {
+	t.Run("ConcurrentReadWrite", func(t *testing.T) { testConcurrentReadWrite(t, newStore(t)) })
+	t.Run("ReadYourWrites", func(t *testing.T) { testReadYourWrites(t, newStore(t)) })
+	t.Run("CreatedOnce", func(t *testing.T) { testCreatedOnce(t, newStore(t)) })
+	t.Run("ReturnsCopies", func(t *testing.T) { testReturnsCopies(t, newStore(t)) })
+}
+
+// numberedConfig returns the config written as number n (n > 0). Its fields are derived from n, so
+// a config mixing several writes is detected by checkNumberedConfig.
+func numberedConfig(n int) self.VerificationConfig {
+	return self.VerificationConfig{
+		MinimumAge:           10 + n,
+		MinimumAgeMarginDays: n,
+		ExcludedCountries:    countries[:n%len(countries)+1],
+		Disclosures:          &self.SelfAppDisclosureConfig{Name: n%2 == 0, Nationality: n%2 == 1},
+	}
+}
+
+// checkNumberedConfig returns the number of the write config comes from, 0 for the empty config,
+// or an error if config is not exactly one numberedConfig
+func checkNumberedConfig(config self.VerificationConfig) (int, error) {
+	if reflect.DeepEqual(config, self.VerificationConfig{}) {
+		return 0, nil
+	}
+	n := config.MinimumAgeMarginDays
+	if n <= 0 || !reflect.DeepEqual(config, numberedConfig(n)) {
+		return 0, fmt.Errorf("config %+v is not one that was written", config)
+	}
+	return n, nil
+}
+
+// testConcurrentReadWrite checks that reads racing writes to one ID return whole configs
+func testConcurrentReadWrite(t *testing.T, store self.ConfigStore) {
+	ctx := context.Background()
+	const id = "concurrent"
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 1; i <= iterations; i++ {
+				if _, err := store.SetConfig(ctx, id, numberedConfig(g*iterations+i)); err != nil {
+					t.Errorf("SetConfig failed: %v", err)
+					return
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				config, err := store.GetConfig(ctx, id)
+				if err != nil {
+					t.Errorf("GetConfig failed: %v", err)
+					return
+				}
+				if _, err := checkNumberedConfig(config); err != nil {
+					t.Errorf("GetConfig returned a torn config: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// testReadYourWrites checks that a read following a write returns it, while other goroutines write
+// to their own IDs
+func testReadYourWrites(t *testing.T, store self.ConfigStore) {
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id := fmt.Sprintf("read-your-writes-%d", g)
+			for i := 1; i <= iterations; i++ {
+				if _, err := store.SetConfig(ctx, id, numberedConfig(i)); err != nil {
+					t.Errorf("SetConfig failed: %v", err)
+					return
+				}
+				config, err := store.GetConfig(ctx, id)
+				if err != nil {
+					t.Errorf("GetConfig failed: %v", err)
+					return
+				}
+				if n, err := checkNumberedConfig(config); err != nil || n != i {
+					t.Errorf("GetConfig after writing config %d of %s returned %+v", i, id, config)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// testCreatedOnce checks that exactly one of the concurrent writes creating an ID reports it
+func testCreatedOnce(t *testing.T, store self.ConfigStore) {
+	ctx := context.Background()
+	for i := 1; i <= iterations; i++ {
+		id := fmt.Sprintf("created-once-%d", i)
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		created := 0
+		for g := 0; g < goroutines; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ok, err := store.SetConfig(ctx, id, numberedConfig(g+1))
+				if err != nil {
+					t.Errorf("SetConfig failed: %v", err)
+					return
+				}
+				if ok {
+					mu.Lock()
+					created++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		if created != 1 {
+			t.Fatalf("Expected one of %d concurrent writes to create %s, %d did", goroutines, id, created)
+		}
+	}
+}
+
+// testReturnsCopies checks that modifying a config passed to SetConfig or returned by GetConfig does
+// not change the stored config
+func testReturnsCopies(t *testing.T, store self.ConfigStore) {
+	ctx := context.Background()
+	const id = "copies"
+	written := numberedConfig(4)
+	written.ExcludedCountries = append([]common.Country3LetterCode(nil), written.ExcludedCountries...)
+	if _, err := store.SetConfig(ctx, id, written); err != nil {
+		t.Fatalf("SetConfig failed: %v", err)
+	}
+	written.ExcludedCountries[0] = common.USA
+	written.Disclosures.Name = false
+
+	config, err := store.GetConfig(ctx, id)
+	if err != nil {
+		t.Fatalf("GetConfig failed: %v", err)
+	}
+	if n, err := checkNumberedConfig(config); err != nil || n != 4 {
+		t.Fatalf("Modifying the written config changed the stored one: %+v", config)
+	}
+	config.ExcludedCountries[0] = common.USA
+	config.Disclosures.Name = false
+
+	config, err = store.GetConfig(ctx, id)
+	if err != nil {
+		t.Fatalf("GetConfig failed: %v", err)
+	}
+	if n, err := checkNumberedConfig(config); err != nil || n != 4 {
+		t.Fatalf("Modifying a returned config changed the stored one: %+v", config)
+	}
+}
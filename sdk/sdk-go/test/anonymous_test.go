@@ -0,0 +1,32 @@
+package selfBackendVerifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func TestVerify_AnonymousMode(t *testing.T) {
+	verifyAnonymous := func(config self.VerificationConfig, opts ...self.VerifierOption) (*self.VerificationResult, error) {
+		return newOfflineTestVerifier(t, config, opts...).Verify(context.Background(), 1, testProof, testPublicSignals, "")
+	}
+
+	_, err := verifyAnonymous(createTestVerificationConfig())
+	if !errors.Is(err, self.ErrUserContextMismatch) || !errors.Is(err, self.ErrConfigNotFound) {
+		t.Errorf("Expected a missing userContextData to be rejected without anonymous mode, got %v", err)
+	}
+
+	result, err := verifyAnonymous(createTestVerificationConfig(), self.WithAnonymousMode("test-config-id"))
+	if err != nil || !result.IsValidDetails.IsValid {
+		t.Errorf("Expected the anonymous config to apply, got %+v, %v", result, err)
+	}
+
+	// Policy checks are still enforced
+	config := createTestVerificationConfig()
+	config.MinimumAge = 21
+	if _, err := verifyAnonymous(config, self.WithAnonymousMode("test-config-id")); !errors.Is(err, self.ErrMinimumAgeNotMet) {
+		t.Errorf("Expected ErrMinimumAgeNotMet, got %v", err)
+	}
+}
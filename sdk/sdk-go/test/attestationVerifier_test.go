@@ -0,0 +1,221 @@
+package selfBackendVerifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+// staticAttestationVerifier is an AttestationVerifier accepting every proof, disclosing a date of birth
+type staticAttestationVerifier struct{}
+
+func (staticAttestationVerifier) Verify(ctx context.Context, proof self.VcAndDiscloseProof, publicSignals []string, userContextData string) (*self.VerificationResult, error) {
+	return &self.VerificationResult{
+		IsValidDetails: self.IsValidDetails{IsValid: true, IsMinimumAgeValid: true, IsOfacValid: true},
+		DiscloseOutput: self.GenericDiscloseOutput{DateOfBirth: "980327"},
+	}, nil
+}
+
+// panickingAttestationVerifier is an AttestationVerifier that panics on every proof
+type panickingAttestationVerifier struct{}
+
+func (panickingAttestationVerifier) Verify(ctx context.Context, proof self.VcAndDiscloseProof, publicSignals []string, userContextData string) (*self.VerificationResult, error) {
+	panic("native verifier crashed")
+}
+
+func TestRegisterAttestationVerifier(t *testing.T) {
+	const pilotId self.AttestationId = 99
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{self.Passport: true, pilotId: true},
+		createTestMockConfigStore(createTestVerificationConfig()),
+		self.UserIDTypeUUID,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	verify := func(id self.AttestationId) (*self.VerificationResult, error) {
+		return verifier.Verify(context.Background(), int(id), testProof, testPublicSignals, createTestUserContextData())
+	}
+
+	if _, err := verify(pilotId); err == nil {
+		t.Error("Expected an unregistered attestation type to fail")
+	}
+
+	if err := verifier.RegisterAttestationVerifier(self.Passport, staticAttestationVerifier{}); err == nil {
+		t.Error("Expected registering a built-in attestation type to fail")
+	}
+	if err := verifier.RegisterAttestationVerifier(pilotId, staticAttestationVerifier{}); err != nil {
+		t.Fatalf("Failed to register attestation verifier: %v", err)
+	}
+
+	result, err := verify(pilotId)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.IsValidDetails.IsValid || result.AttestationId != pilotId {
+		t.Errorf("Expected a valid result for attestation %d, got %+v", pilotId, result)
+	}
+
+	if err := verifier.RegisterAttestationVerifier(100, staticAttestationVerifier{}); err != nil {
+		t.Fatalf("Failed to register attestation verifier: %v", err)
+	}
+	if _, err := verify(100); !errors.Is(err, self.ErrAttestationNotAllowed) {
+		t.Errorf("Expected ErrAttestationNotAllowed for a registered but disallowed type, got %v", err)
+	}
+}
+
+func TestVerify_RecoversVerifierPanic(t *testing.T) {
+	const pilotId self.AttestationId = 99
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{pilotId: true},
+		createTestMockConfigStore(createTestVerificationConfig()),
+		self.UserIDTypeUUID,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	if err := verifier.RegisterAttestationVerifier(pilotId, panickingAttestationVerifier{}); err != nil {
+		t.Fatalf("Failed to register attestation verifier: %v", err)
+	}
+
+	_, err = verifier.Verify(context.Background(), int(pilotId), testProof, testPublicSignals, createTestUserContextData())
+	if !errors.Is(err, self.ErrInternalVerification) {
+		t.Errorf("Expected ErrInternalVerification, got %v", err)
+	}
+	if code := self.ErrorCodeFor(err); code != self.ErrorCodeInternal {
+		t.Errorf("Expected error code %s, got %s", self.ErrorCodeInternal, code)
+	}
+}
+
+func TestVerifyBool(t *testing.T) {
+	const pilotId self.AttestationId = 99
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{self.Passport: true, pilotId: true},
+		createTestMockConfigStore(createTestVerificationConfig()),
+		self.UserIDTypeUUID,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	if err := verifier.RegisterAttestationVerifier(pilotId, staticAttestationVerifier{}); err != nil {
+		t.Fatalf("Failed to register attestation verifier: %v", err)
+	}
+
+	input := self.VerifyInput{
+		AttestationId:   int(pilotId),
+		Proof:           testProof,
+		PublicSignals:   testPublicSignals,
+		UserContextData: createTestUserContextData(),
+	}
+	if valid, err := verifier.VerifyBool(context.Background(), input); !valid || err != nil {
+		t.Errorf("Expected a valid proof, got %v (err=%v)", valid, err)
+	}
+
+	// The test proof cannot be checked offline
+	input.AttestationId = int(self.Passport)
+	if valid, err := verifier.VerifyBool(context.Background(), input); valid || err == nil {
+		t.Errorf("Expected an invalid proof with an error, got %v (err=%v)", valid, err)
+	}
+}
+
+// slowAttestationVerifier is an AttestationVerifier blocking until its context is done
+type slowAttestationVerifier struct{}
+
+func (slowAttestationVerifier) Verify(ctx context.Context, proof self.VcAndDiscloseProof, publicSignals []string, userContextData string) (*self.VerificationResult, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestVerify_VerificationBudget(t *testing.T) {
+	const fastId, slowId self.AttestationId = 99, 100
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{fastId: true, slowId: true},
+		createTestMockConfigStore(createTestVerificationConfig()),
+		self.UserIDTypeUUID,
+		self.WithVerificationBudget(20*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	verifier.RegisterAttestationVerifier(fastId, staticAttestationVerifier{})
+	verifier.RegisterAttestationVerifier(slowId, slowAttestationVerifier{})
+
+	result, err := verifier.Verify(context.Background(), int(fastId), testProof, testPublicSignals, createTestUserContextData())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.ProofVerificationTime <= 0 || result.ProofVerificationTime > 20*time.Millisecond {
+		t.Errorf("Expected the proof verification time to be reported, got %s", result.ProofVerificationTime)
+	}
+
+	_, err = verifier.Verify(context.Background(), int(slowId), testProof, testPublicSignals, createTestUserContextData())
+	if !errors.Is(err, self.ErrVerificationBudgetExceeded) || self.ErrorCodeFor(err) != self.ErrorCodeVerificationBudget {
+		t.Errorf("Expected ErrVerificationBudgetExceeded, got %v", err)
+	}
+}
+
+// issuanceAttestationVerifier is an AttestationVerifier disclosing a date of issue, with a config
+// rejecting documents issued to minors
+type issuanceAttestationVerifier struct{ dateOfIssue string }
+
+func (v issuanceAttestationVerifier) Verify(ctx context.Context, proof self.VcAndDiscloseProof, publicSignals []string, userContextData string) (*self.VerificationResult, error) {
+	return &self.VerificationResult{
+		IsValidDetails: self.IsValidDetails{IsValid: true, IsMinimumAgeValid: true, IsOfacValid: true},
+		DiscloseOutput: self.GenericDiscloseOutput{DateOfBirthISO: "1998-03-27", DateOfIssueISO: v.dateOfIssue},
+		Config:         self.VerificationConfig{RejectIssuedAsMinor: true},
+	}, nil
+}
+
+func TestVerify_IssuedAsMinor(t *testing.T) {
+	const adultId, minorId, unknownId self.AttestationId = 96, 97, 98
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{adultId: true, minorId: true, unknownId: true},
+		createTestMockConfigStore(createTestVerificationConfig()),
+		self.UserIDTypeUUID,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	verifier.RegisterAttestationVerifier(adultId, issuanceAttestationVerifier{dateOfIssue: "2020-01-15"})
+	verifier.RegisterAttestationVerifier(minorId, issuanceAttestationVerifier{dateOfIssue: "2015-06-01"})
+	verifier.RegisterAttestationVerifier(unknownId, issuanceAttestationVerifier{})
+
+	result, err := verifier.Verify(context.Background(), int(adultId), testProof, testPublicSignals, createTestUserContextData())
+	if err != nil {
+		t.Fatalf("Expected a document issued to an adult to pass, got %v", err)
+	}
+	if result.IssuedAsMinor == nil || *result.IssuedAsMinor {
+		t.Errorf("Expected IssuedAsMinor false, got %v", result.IssuedAsMinor)
+	}
+
+	if _, err := verifier.Verify(context.Background(), int(minorId), testProof, testPublicSignals, createTestUserContextData()); !errors.Is(err, self.ErrIssuedAsMinor) {
+		t.Errorf("Expected ErrIssuedAsMinor, got %v", err)
+	}
+
+	// Without a date of issue the check does not apply
+	result, err = verifier.Verify(context.Background(), int(unknownId), testProof, testPublicSignals, createTestUserContextData())
+	if err != nil {
+		t.Fatalf("Expected a document without a date of issue to pass, got %v", err)
+	}
+	if result.IssuedAsMinor != nil {
+		t.Errorf("Expected IssuedAsMinor nil, got %v", *result.IssuedAsMinor)
+	}
+}
@@ -0,0 +1,65 @@
+package selfBackendVerifier
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func TestVerify_RecordsAudit(t *testing.T) {
+	userContextData := createTestUserContextData()
+
+	userIdentifierBigInt := new(big.Int)
+	userIdentifierBigInt.SetString(userContextData[64:128], 16)
+	userIdentifierUUID := castToUUID(userIdentifierBigInt)
+	mockConfigStore := createTestMockConfigStore(createTestVerificationConfig())
+
+	sink := self.NewInMemoryAuditSink()
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{self.Passport: true},
+		mockConfigStore,
+		self.UserIDTypeUUID,
+		self.WithAuditSink(sink),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+
+	// The test proof's root is not pinned and cannot be confirmed on chain
+	_, verifyErr := verifier.Verify(context.Background(), 1, testProof, testPublicSignals, userContextData)
+	if !errors.Is(verifyErr, self.ErrInvalidRoot) {
+		t.Fatalf("Expected ErrInvalidRoot, got %v", verifyErr)
+	}
+
+	records := sink.Records()
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 audit record, got %d", len(records))
+	}
+	audit := records[0]
+
+	if audit.ConfigId != "test-config-id" {
+		t.Errorf("Expected config id test-config-id, got %q", audit.ConfigId)
+	}
+	if expected := createTestVerificationConfig().Hash(); audit.ConfigHash != expected {
+		t.Errorf("Expected config hash %s, got %q", expected, audit.ConfigHash)
+	}
+	if audit.UserIdentifier != userIdentifierUUID {
+		t.Errorf("Expected user identifier %s, got %s", userIdentifierUUID, audit.UserIdentifier)
+	}
+	if audit.UserContextData == userContextData {
+		t.Errorf("Expected userContextData to be redacted")
+	}
+	if audit.IsValid || audit.ErrorCode != self.ErrorCodeInvalidRoot {
+		t.Errorf("Expected audit to record error code %s, got %+v", self.ErrorCodeInvalidRoot, audit)
+	}
+	// The issue messages of the error are not recorded, only the failed check
+	if audit.Error != self.ErrInvalidRoot.Error() {
+		t.Errorf("Expected the audit error %q, got %q", self.ErrInvalidRoot.Error(), audit.Error)
+	}
+}
@@ -0,0 +1,42 @@
+package selfBackendVerifier
+
+import (
+	"context"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func newBenchmarkVerifier(b *testing.B) *self.BackendVerifier {
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{self.Passport: true},
+		createTestMockConfigStore(createTestVerificationConfig()),
+		self.UserIDTypeUUID,
+	)
+	if err != nil {
+		b.Fatalf("Failed to create verifier: %v", err)
+	}
+	return verifier
+}
+
+func BenchmarkNewBackendVerifier(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		newBenchmarkVerifier(b)
+	}
+}
+
+// BenchmarkVerify measures the local work of a verification. Without network access the
+// contract calls fail fast, so network latency is excluded.
+func BenchmarkVerify(b *testing.B) {
+	verifier := newBenchmarkVerifier(b)
+	userContextData := createTestUserContextData()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		verifier.Verify(context.Background(), 1, testProof, testPublicSignals, userContextData)
+	}
+}
@@ -0,0 +1,22 @@
+package selfBackendVerifier
+
+import (
+	"errors"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func TestVerify_RequireBiometricCommitment(t *testing.T) {
+	config := createTestVerificationConfig()
+	result, err := verifyOffline(t, config, testPublicSignals)
+	if err != nil || !result.IsValidDetails.IsValid {
+		t.Errorf("Expected no biometric check without RequireBiometricCommitment, got %+v, %v", result, err)
+	}
+
+	// The built-in passport circuit does not commit to a biometric hash
+	config.RequireBiometricCommitment = true
+	if _, err := verifyOffline(t, config, testPublicSignals); !errors.Is(err, self.ErrMissingBiometric) {
+		t.Errorf("Expected ErrMissingBiometric, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package selfBackendVerifier
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+// passportLayout returns the default passport layout as the given version with count public signals
+func passportLayout(version self.CircuitVersion, count int) self.CircuitLayout {
+	return self.CircuitLayout{Version: version, PublicSignalsCount: count, Indices: self.DiscloseIndices[self.Passport]}
+}
+
+func TestDetectCircuitVersion_V2(t *testing.T) {
+	layout, err := self.DetectCircuitVersion(self.Passport, testPublicSignals)
+	if err != nil {
+		t.Fatalf("Failed to detect circuit version: %v", err)
+	}
+	if layout.Version != self.CircuitVersionV2 {
+		t.Errorf("Expected version 2, got %d", layout.Version)
+	}
+
+	// Trailing signals are not covered by the proof and are read with the newest layout
+	longer := append(append([]string{}, testPublicSignals...), "0")
+	layout, err = self.DetectCircuitVersion(self.Passport, longer)
+	if err != nil || layout.Version != self.CircuitVersionV2 {
+		t.Errorf("Expected version 2 for extra trailing signals, got %d, %v", layout.Version, err)
+	}
+}
+
+func TestWithCircuitLayouts(t *testing.T) {
+	// A proof matching an older supported layout is verified with it
+	layouts := self.WithCircuitLayouts(self.Passport,
+		[]self.CircuitLayout{passportLayout(self.CircuitVersionV2, len(testPublicSignals)+1), passportLayout(self.CircuitVersionV1, len(testPublicSignals))},
+		nil)
+	result, err := verifyOffline(t, createTestVerificationConfig(), testPublicSignals, layouts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.CircuitVersion != self.CircuitVersionV1 {
+		t.Errorf("Expected version 1, got %d", result.CircuitVersion)
+	}
+
+	// The defaults are left unchanged
+	if versions := self.SupportedCircuitVersions(self.Passport); !reflect.DeepEqual(versions, []self.CircuitVersion{self.CircuitVersionV2}) {
+		t.Errorf("Expected the default passport versions [2], got %v", versions)
+	}
+	result, err = verifyOffline(t, createTestVerificationConfig(), testPublicSignals)
+	if err != nil || result.CircuitVersion != self.CircuitVersionV2 {
+		t.Errorf("Expected version 2 by default, got %+v, %v", result, err)
+	}
+}
+
+func TestDetectCircuitVersion_TooOld(t *testing.T) {
+	// Signals matching no known layout are reported as an unknown version
+	_, err := self.DetectCircuitVersion(self.Passport, testPublicSignals[:5])
+	var versionErr *self.UnsupportedCircuitVersionError
+	if !errors.As(err, &versionErr) || !errors.Is(err, self.ErrUnsupportedCircuitVersion) || versionErr.Version != self.CircuitVersionUnknown {
+		t.Errorf("Expected an unknown circuit version, got %v", err)
+	}
+
+	// Verify rejects a retired layout with its version before checking the proof
+	retired := passportLayout(self.CircuitVersionV1, len(testPublicSignals)-1)
+	layouts := self.WithCircuitLayouts(self.Passport,
+		[]self.CircuitLayout{passportLayout(self.CircuitVersionV2, len(testPublicSignals))},
+		[]self.CircuitLayout{retired})
+	_, err = verifyOffline(t, createTestVerificationConfig(), testPublicSignals[:retired.PublicSignalsCount], layouts)
+	if !errors.As(err, &versionErr) || !errors.Is(err, self.ErrUnsupportedCircuitVersion) {
+		t.Fatalf("Expected UnsupportedCircuitVersionError, got %v", err)
+	}
+	if versionErr.Version != self.CircuitVersionV1 {
+		t.Errorf("Expected detected version 1, got %d", versionErr.Version)
+	}
+	if !reflect.DeepEqual(versionErr.Supported, []self.CircuitVersion{self.CircuitVersionV2}) {
+		t.Errorf("Expected supported versions [2], got %v", versionErr.Supported)
+	}
+}
@@ -0,0 +1,66 @@
+package selfBackendVerifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func TestConfigHandler(t *testing.T) {
+	store := self.NewInMemoryConfigStore(nil)
+	mux := http.NewServeMux()
+	mux.Handle("POST /api/config/{id}", self.NewConfigHandler(store, self.BearerTokenAuthorizer("secret")))
+
+	post := func(token string, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/api/config/adults", strings.NewReader(body))
+		if token != "" {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	if recorder := post("wrong", `{"minimumAge":18}`); recorder.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d for a wrong token, got %d", http.StatusUnauthorized, recorder.Code)
+	}
+
+	recorder := post("secret", `{"minimumAge":150,"excludedCountries":["usa"],"unknown":1}`)
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status %d for an unknown field, got %d", http.StatusBadRequest, recorder.Code)
+	}
+	recorder = post("secret", `{"minimumAge":150,"excludedCountries":["usa"]}`)
+	if body := decodeErrorResponse(t, recorder); body.Code != self.ErrorCodeInvalidRequest || len(body.Errors) != 2 {
+		t.Errorf("Expected both invalid fields to be reported, got %+v", body)
+	}
+
+	for _, expected := range []struct {
+		status  int
+		created bool
+	}{{http.StatusCreated, true}, {http.StatusOK, false}} {
+		recorder := post("secret", `{"minimumAge":18,"excludedCountries":["USA"]}`)
+		var response self.ConfigUpdateResponse
+		json.NewDecoder(recorder.Body).Decode(&response)
+		if recorder.Code != expected.status || response.Created != expected.created || response.Id != "adults" {
+			t.Errorf("Expected status %d with created %v, got %d %+v", expected.status, expected.created, recorder.Code, response)
+		}
+	}
+
+	if config, _ := store.GetConfig(context.Background(), "adults"); config.MinimumAge != 18 {
+		t.Errorf("Expected the config to be stored, got %+v", config)
+	}
+}
+
+func TestConfigHandler_NilAuthorizeDeniesAll(t *testing.T) {
+	handler := self.NewConfigHandler(self.NewInMemoryConfigStore(nil), nil)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/config/adults", strings.NewReader(`{"minimumAge":18}`)))
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d without an authorizer, got %d", http.StatusUnauthorized, recorder.Code)
+	}
+}
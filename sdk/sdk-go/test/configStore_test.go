@@ -0,0 +1,651 @@
+package selfBackendVerifier
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+	common "github.com/selfxyz/self/sdk/sdk-go/common"
+	"github.com/selfxyz/self/sdk/sdk-go/storetest"
+)
+
+// failingConfigStore is a ConfigStore whose every call fails
+type failingConfigStore struct{}
+
+func (failingConfigStore) GetConfig(ctx context.Context, id string) (self.VerificationConfig, error) {
+	return self.VerificationConfig{}, errors.New("store unavailable")
+}
+
+func (failingConfigStore) SetConfig(ctx context.Context, id string, config self.VerificationConfig) (bool, error) {
+	return false, errors.New("store unavailable")
+}
+
+func (failingConfigStore) GetActionId(ctx context.Context, userIdentifier string, userDefinedData string) (string, error) {
+	return "", errors.New("store unavailable")
+}
+
+func TestChainedConfigStore_FallsBackOnMiss(t *testing.T) {
+	ctx := context.Background()
+	cache := self.NewInMemoryConfigStore(func(ctx context.Context, userIdentifier string, userDefinedData string) (string, error) {
+		return "", nil
+	})
+	remote := createTestMockConfigStore(createTestVerificationConfig())
+
+	store := self.NewChainedConfigStore(cache, failingConfigStore{}, remote)
+
+	config, err := store.GetConfig(ctx, "test-config-id")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.MinimumAge != 18 {
+		t.Errorf("Expected config from remote store, got %+v", config)
+	}
+
+	if _, err := store.GetConfig(ctx, "missing"); err == nil {
+		t.Errorf("Expected the failing store's error when no store has the config")
+	}
+}
+
+func TestChainedConfigStore_ReturnsConfigsSettingAnyField(t *testing.T) {
+	ctx := context.Background()
+	fallback := createTestMockConfigStore(createTestVerificationConfig())
+
+	// A config setting any single field is found, not mistaken for a missing one
+	fields := reflect.TypeOf(self.VerificationConfig{})
+	for i := 0; i < fields.NumField(); i++ {
+		var config self.VerificationConfig
+		field := reflect.ValueOf(&config).Elem().Field(i)
+		switch field.Kind() {
+		case reflect.Bool:
+			field.SetBool(true)
+		case reflect.Int:
+			field.SetInt(1)
+		case reflect.String:
+			field.SetString("x")
+		case reflect.Slice:
+			field.Set(reflect.MakeSlice(field.Type(), 1, 1))
+		case reflect.Pointer:
+			field.Set(reflect.New(field.Type().Elem()))
+		default:
+			t.Fatalf("Unhandled kind %s of field %s", field.Kind(), fields.Field(i).Name)
+		}
+
+		got, err := self.NewChainedConfigStore(createTestMockConfigStore(config), fallback).GetConfig(ctx, "test-config-id")
+		if err != nil || got.MinimumAge == 18 {
+			t.Errorf("%s: expected the config setting it to be found, got %+v, %v", fields.Field(i).Name, got, err)
+		}
+	}
+}
+
+func TestChainedConfigStore_PrefetchConfigs(t *testing.T) {
+	ctx := context.Background()
+	cache := self.NewInMemoryConfigStore(func(ctx context.Context, userIdentifier string, userDefinedData string) (string, error) {
+		return "", nil
+	})
+	remote := createTestMockConfigStore(createTestVerificationConfig())
+	store := self.NewChainedConfigStore(cache, remote)
+
+	if err := store.PrefetchConfigs(ctx, "test-config-id"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cached, _ := cache.GetConfig(ctx, "test-config-id"); cached.MinimumAge != 18 {
+		t.Errorf("Expected the cache to be primed, got %+v", cached)
+	}
+
+	err := store.PrefetchConfigs(ctx, "test-config-id", "missing")
+	if !errors.Is(err, self.ErrConfigNotFound) || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("Expected ErrConfigNotFound naming the missing config, got %v", err)
+	}
+
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{self.Passport: true},
+		self.NewMetricsConfigStore(store, nil),
+		self.UserIDTypeUUID,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	if err := verifier.Warmup(ctx, "test-config-id"); err != nil {
+		t.Errorf("Expected warmup to succeed, got %v", err)
+	}
+	if err := verifier.Warmup(ctx, "missing"); !errors.Is(err, self.ErrConfigNotFound) {
+		t.Errorf("Expected warmup to fail with ErrConfigNotFound, got %v", err)
+	}
+}
+
+func TestChainedConfigStore_SetConfigWriter(t *testing.T) {
+	ctx := context.Background()
+	noActionId := func(ctx context.Context, userIdentifier string, userDefinedData string) (string, error) {
+		return "", nil
+	}
+	cache := self.NewInMemoryConfigStore(noActionId)
+	remote := self.NewInMemoryConfigStore(noActionId)
+	config := self.VerificationConfig{MinimumAge: 21, ExcludedCountries: []common.Country3LetterCode{common.PRK}}
+
+	if _, err := self.NewChainedConfigStoreWithWriter(remote, cache, remote).SetConfig(ctx, "1", config); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cached, _ := cache.GetConfig(ctx, "1"); cached.MinimumAge != 0 {
+		t.Errorf("Expected only the writer to be updated")
+	}
+
+	created, err := self.NewChainedConfigStore(cache, remote).SetConfig(ctx, "1", config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if created {
+		t.Errorf("Expected the last store to report an update")
+	}
+	if cached, _ := cache.GetConfig(ctx, "1"); cached.MinimumAge != 21 {
+		t.Errorf("Expected every store to be updated")
+	}
+}
+
+func TestInMemoryConfigStore_ConfigChangeAppliesToNextVerify(t *testing.T) {
+	ctx := context.Background()
+	store := self.NewInMemoryConfigStore(func(ctx context.Context, userIdentifier string, userDefinedData string) (string, error) {
+		return "test-config-id", nil
+	})
+	store.SetConfig(ctx, "test-config-id", createTestVerificationConfig())
+
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{self.Passport: true},
+		store,
+		self.UserIDTypeUUID,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	verify := func() error {
+		_, err := verifier.Verify(ctx, 1, testProof, testPublicSignals, createTestUserContextData())
+		return err
+	}
+
+	// The test proof discloses a minimum age of 18
+	if err := verify(); errors.Is(err, self.ErrMinimumAgeNotMet) {
+		t.Fatalf("Expected minimum age 18 to be met, got %v", err)
+	}
+
+	config := createTestVerificationConfig()
+	config.MinimumAge = 21
+	if created, _ := store.SetConfig(ctx, "test-config-id", config); created {
+		t.Error("Expected SetConfig to report an update")
+	}
+
+	if err := verify(); !errors.Is(err, self.ErrMinimumAgeNotMet) {
+		t.Errorf("Expected updated minimum age to apply without a new verifier, got %v", err)
+	}
+}
+
+func TestVerificationConfig_Hash(t *testing.T) {
+	config := self.VerificationConfig{
+		MinimumAge:        18,
+		ExcludedCountries: []common.Country3LetterCode{common.IRN, common.PRK, common.CUB},
+		Ofac:              true,
+	}
+	reordered := self.VerificationConfig{
+		MinimumAge:        18,
+		ExcludedCountries: []common.Country3LetterCode{common.CUB, common.IRN, common.PRK, common.IRN},
+		Ofac:              true,
+		OfacMode:          self.OFACModeProof,
+	}
+	if config.Hash() != reordered.Hash() {
+		t.Errorf("Expected reordered country lists to hash identically")
+	}
+
+	empty := self.VerificationConfig{ExcludedCountries: []common.Country3LetterCode{}, OfacMode: self.OFACModeBoth, TrustProofOfac: true}
+	if empty.Hash() != (self.VerificationConfig{}).Hash() {
+		t.Errorf("Expected empty and nil country lists to hash identically")
+	}
+
+	changed := config
+	changed.MinimumAge = 21
+	if config.Hash() == changed.Hash() {
+		t.Errorf("Expected configs with different minimum ages to hash differently")
+	}
+
+	trusting := config
+	trusting.OfacMode = self.OFACModeBoth
+	trusting.TrustProofOfac = true
+	screening := trusting
+	screening.TrustProofOfac = false
+	if trusting.Hash() == screening.Hash() {
+		t.Errorf("Expected configs trusting the in-proof OFAC result to hash differently")
+	}
+}
+
+func TestMetricsConfigStore_ReportsHitsAndMisses(t *testing.T) {
+	ctx := context.Background()
+	var events []self.ConfigStoreEvent
+	store := self.NewMetricsConfigStore(createTestMockConfigStore(createTestVerificationConfig()), func(ctx context.Context, event self.ConfigStoreEvent) {
+		events = append(events, event)
+	})
+
+	store.GetConfig(ctx, "test-config-id")
+	store.GetConfig(ctx, "missing")
+	store.SetConfig(ctx, "other", createTestVerificationConfig())
+
+	expected := []struct {
+		operation self.ConfigStoreOperation
+		hit       bool
+	}{
+		{self.ConfigStoreGetConfig, true},
+		{self.ConfigStoreGetConfig, false},
+		{self.ConfigStoreSetConfig, false},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events, got %d", len(expected), len(events))
+	}
+	for i, event := range events {
+		if event.Operation != expected[i].operation || event.Hit != expected[i].hit {
+			t.Errorf("Event %d: expected %s hit=%v, got %+v", i, expected[i].operation, expected[i].hit, event)
+		}
+	}
+}
+
+func TestResolvedConfigContext(t *testing.T) {
+	ctx := context.Background()
+	if _, ok := self.ResolvedConfigFromContext(ctx); ok {
+		t.Fatal("Expected no resolved config in an empty context")
+	}
+
+	result := &self.VerificationResult{ConfigId: "test-config-id", Config: createTestVerificationConfig()}
+	resolved, ok := self.ResolvedConfigFromContext(self.ContextWithResolvedConfig(ctx, result))
+	if !ok || resolved.ConfigId != "test-config-id" || resolved.Config.Hash() != result.Config.Hash() {
+		t.Errorf("Expected resolved config for %s, got %+v (ok=%v)", result.ConfigId, resolved, ok)
+	}
+}
+
+func TestInMemoryConfigStore_List(t *testing.T) {
+	ctx := context.Background()
+	store := self.NewInMemoryConfigStore(nil)
+	for _, id := range []string{"c", "a", "b"} {
+		if _, err := store.SetConfig(ctx, id, createTestVerificationConfig()); err != nil {
+			t.Fatalf("Failed to set config: %v", err)
+		}
+	}
+	store.SetActionId("signup", "a")
+
+	var ids []string
+	request := self.ListRequest{Limit: 2}
+	for {
+		page, err := store.ListConfigs(ctx, request)
+		if err != nil {
+			t.Fatalf("Failed to list configs: %v", err)
+		}
+		for _, entry := range page.Configs {
+			ids = append(ids, entry.Id)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		request.Cursor = page.NextCursor
+	}
+	if strings.Join(ids, ",") != "a,b,c" {
+		t.Errorf("Expected configs a,b,c, got %v", ids)
+	}
+
+	mappings, err := store.ListActionIds(ctx, self.ListRequest{})
+	if err != nil || len(mappings.Mappings) != 1 || mappings.Mappings[0] != (self.ActionIdMapping{UserDefinedData: "signup", ActionId: "a"}) {
+		t.Errorf("Expected the signup mapping, got %+v (err=%v)", mappings, err)
+	}
+	if actionId, _ := store.GetActionId(ctx, "user", "signup"); actionId != "a" {
+		t.Errorf("Expected stored action ID a, got %q", actionId)
+	}
+}
+
+// hangingConfigStore is a ConfigStore whose reads ignore ctx and block until release is closed
+type hangingConfigStore struct {
+	release chan struct{}
+}
+
+func (store hangingConfigStore) GetConfig(ctx context.Context, id string) (self.VerificationConfig, error) {
+	<-store.release
+	return self.VerificationConfig{}, nil
+}
+
+func (store hangingConfigStore) SetConfig(ctx context.Context, id string, config self.VerificationConfig) (bool, error) {
+	return false, nil
+}
+
+func (store hangingConfigStore) GetActionId(ctx context.Context, userIdentifier string, userDefinedData string) (string, error) {
+	<-store.release
+	return "", nil
+}
+
+func TestVerify_ConfigStoreTimeout(t *testing.T) {
+	store := hangingConfigStore{release: make(chan struct{})}
+	defer close(store.release)
+
+	verify := func(opts ...self.VerifierOption) error {
+		verifier, err := self.NewBackendVerifier(
+			"self-playground",
+			"https://playground.self.xyz/api/verify",
+			false,
+			map[self.AttestationId]bool{self.Passport: true},
+			store,
+			self.UserIDTypeUUID,
+			append([]self.VerifierOption{self.WithConfigStoreTimeout(20 * time.Millisecond)}, opts...)...,
+		)
+		if err != nil {
+			t.Fatalf("Failed to create verifier: %v", err)
+		}
+		_, err = verifier.Verify(context.Background(), 1, testProof, testPublicSignals, createTestUserContextData())
+		return err
+	}
+
+	err := verify()
+	if !errors.Is(err, self.ErrConfigStoreTimeout) || self.ErrorCodeFor(err) != self.ErrorCodeConfigStoreTimeout {
+		t.Errorf("Expected ErrConfigStoreTimeout, got %v", err)
+	}
+
+	err = verify(self.WithConfigStoreFallback(createTestVerificationConfig()))
+	if errors.Is(err, self.ErrConfigStoreTimeout) || errors.Is(err, self.ErrConfigNotFound) {
+		t.Errorf("Expected the fallback config to be used, got %v", err)
+	}
+}
+
+// candidateConfigStore resolves every verification to a fixed list of candidate config IDs
+type candidateConfigStore struct {
+	*MockConfigStore
+	candidates []string
+}
+
+func (store *candidateConfigStore) ResolveCandidateActionIds(ctx context.Context, request self.ActionIdRequest) ([]string, error) {
+	return store.candidates, nil
+}
+
+func TestVerify_CandidateConfigs(t *testing.T) {
+	// The test proof proves a minimum age of 18, so only the pilot policy is satisfied
+	strict := createTestVerificationConfig()
+	strict.MinimumAge = 21
+	pilot := createTestVerificationConfig()
+
+	verifyCandidates := func(candidates ...string) (self.VerificationAudit, error) {
+		store := &candidateConfigStore{MockConfigStore: createTestMockConfigStore(strict), candidates: candidates}
+		store.configs = map[string]self.VerificationConfig{"strict": strict, "strict-v2": strict, "pilot": pilot}
+		sink := self.NewInMemoryAuditSink()
+		verifier, err := self.NewBackendVerifier(
+			"self-playground",
+			"https://playground.self.xyz/api/verify",
+			false,
+			map[self.AttestationId]bool{self.Passport: true},
+			self.NewMetricsConfigStore(store, nil),
+			self.UserIDTypeUUID,
+			append(offlineTestOptions(t), self.WithAuditSink(sink))...,
+		)
+		if err != nil {
+			t.Fatalf("Failed to create verifier: %v", err)
+		}
+		_, err = verifier.Verify(context.Background(), 1, testProof, testPublicSignals, createTestUserContextData())
+		return sink.Records()[0], err
+	}
+
+	audit, err := verifyCandidates("strict", "pilot")
+	if err != nil {
+		t.Errorf("Expected the pilot candidate to be satisfied, got %v", err)
+	}
+	if audit.ConfigId != "pilot" || audit.ConfigHash != pilot.Hash() {
+		t.Errorf("Expected the pilot config to be reported, got %q", audit.ConfigId)
+	}
+
+	audit, err = verifyCandidates("strict", "strict-v2")
+	if !errors.Is(err, self.ErrMinimumAgeNotMet) {
+		t.Errorf("Expected ErrMinimumAgeNotMet when no candidate is satisfied, got %v", err)
+	}
+	if audit.ConfigId != "strict" {
+		t.Errorf("Expected the first candidate to be reported, got %q", audit.ConfigId)
+	}
+
+	if _, err = verifyCandidates(); !errors.Is(err, self.ErrConfigNotFound) {
+		t.Errorf("Expected ErrConfigNotFound without candidates, got %v", err)
+	}
+}
+
+// resolvingConfigStore is a ConfigStore routing every verification to resolvedId, recording the requests
+type resolvingConfigStore struct {
+	*MockConfigStore
+	resolvedId string
+	requests   []self.ActionIdRequest
+}
+
+func (store *resolvingConfigStore) ResolveActionId(ctx context.Context, request self.ActionIdRequest) (string, error) {
+	store.requests = append(store.requests, request)
+	return store.resolvedId, nil
+}
+
+func TestVerify_ActionIdResolver(t *testing.T) {
+	ctx := context.Background()
+	userContextData := createTestUserContextData()
+	verify := func(store self.ConfigStore) (*self.VerificationResult, error) {
+		verifier, err := self.NewBackendVerifier(
+			"self-playground",
+			"https://playground.self.xyz/api/verify",
+			false,
+			map[self.AttestationId]bool{self.Passport: true},
+			store,
+			self.UserIDTypeUUID,
+			offlineTestOptions(t)...,
+		)
+		if err != nil {
+			t.Fatalf("Failed to create verifier: %v", err)
+		}
+		return verifier.Verify(ctx, 1, testProof, testPublicSignals, userContextData)
+	}
+
+	// A resolver is preferred over GetActionId, which would route to test-config-id
+	store := &resolvingConfigStore{MockConfigStore: createTestMockConfigStore(createTestVerificationConfig()), resolvedId: "tenant-config-id"}
+	store.configs["tenant-config-id"] = createTestVerificationConfig()
+	result, err := verify(store)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.ConfigId != "tenant-config-id" {
+		t.Errorf("Expected the resolved config, got %q", result.ConfigId)
+	}
+	userIdentifier, _ := new(big.Int).SetString(userContextData[64:128], 16)
+	expected := []self.ActionIdRequest{{
+		UserIdentifier:  castToUUID(userIdentifier),
+		UserDefinedData: userContextData[128:],
+		Scope:           "self-playground",
+		AttestationId:   self.Passport,
+	}}
+	if !reflect.DeepEqual(store.requests, expected) {
+		t.Errorf("Expected the resolver to receive %+v, got %+v", expected, store.requests)
+	}
+
+	// Other stores fall back to GetActionId
+	result, err = verify(createTestMockConfigStore(createTestVerificationConfig()))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.ConfigId != "test-config-id" {
+		t.Errorf("Expected the config from GetActionId, got %q", result.ConfigId)
+	}
+}
+
+func TestInMemoryConfigStore_Concurrency(t *testing.T) {
+	storetest.TestConfigStore(t, func(t *testing.T) self.ConfigStore {
+		return self.NewInMemoryConfigStore(nil)
+	})
+}
+
+func TestChainedConfigStore_Concurrency(t *testing.T) {
+	storetest.TestConfigStore(t, func(t *testing.T) self.ConfigStore {
+		return self.NewChainedConfigStore(self.NewInMemoryConfigStore(nil), self.NewInMemoryConfigStore(nil))
+	})
+}
+
+func TestInMemoryConfigStore_NormalizesCountryLists(t *testing.T) {
+	ctx := context.Background()
+	store := self.NewInMemoryConfigStore(nil)
+
+	config := self.VerificationConfig{
+		ExcludedCountries:    []common.Country3LetterCode{common.IRN, common.PRK, common.IRN},
+		AllowedIssuingStates: []common.Country3LetterCode{common.D, common.DEU},
+	}
+	if _, err := store.SetConfig(ctx, "deduped", config); err != nil {
+		t.Fatalf("Failed to set config: %v", err)
+	}
+	stored, _ := store.GetConfig(ctx, "deduped")
+	if len(stored.ExcludedCountries) != 2 || stored.ExcludedCountries[0] != common.IRN || stored.ExcludedCountries[1] != common.PRK {
+		t.Errorf("Expected duplicate excluded countries to be removed, got %v", stored.ExcludedCountries)
+	}
+	if len(config.ExcludedCountries) != 3 {
+		t.Errorf("Expected the caller's config to be left unchanged, got %v", config.ExcludedCountries)
+	}
+
+	config.SanctionedNationalities = []common.Country3LetterCode{common.CUB, "IRQ ", "XYZ"}
+	_, err := store.SetConfig(ctx, "typo", config)
+	var validationErr *self.ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Errors) != 2 || validationErr.Errors[0].Field != "sanctionedNationalities[1]" {
+		t.Fatalf("Expected both unknown codes to be rejected, got %v", err)
+	}
+	if typo, _ := store.GetConfig(ctx, "typo"); typo.Hash() != (self.VerificationConfig{}).Hash() {
+		t.Errorf("Expected the rejected config not to be stored, got %+v", typo)
+	}
+}
+
+func TestResolveConfig_Inheritance(t *testing.T) {
+	ctx := context.Background()
+	store := self.NewInMemoryConfigStore(nil)
+	set := func(id string, config self.VerificationConfig) {
+		if _, err := store.SetConfig(ctx, id, config); err != nil {
+			t.Fatalf("Failed to set config %s: %v", id, err)
+		}
+	}
+	set("base", self.VerificationConfig{
+		MinimumAge:           18,
+		ExcludedCountries:    []common.Country3LetterCode{common.PRK},
+		Ofac:                 true,
+		AllowedIssuingStates: []common.Country3LetterCode{common.DEU, common.FRA},
+	})
+	set("tenant", self.VerificationConfig{
+		Parent:               "base",
+		MinimumAge:           21,
+		ExcludedCountries:    []common.Country3LetterCode{common.IRN},
+		AllowedIssuingStates: []common.Country3LetterCode{common.ITA},
+	})
+	set("team", self.VerificationConfig{Parent: "tenant"})
+
+	resolved, err := self.ResolveConfig(ctx, store, "team")
+	if err != nil {
+		t.Fatalf("Failed to resolve config: %v", err)
+	}
+	if resolved.MinimumAge != 21 || !resolved.Ofac || resolved.Parent != "" {
+		t.Errorf("Expected the tenant's minimum age over the base's OFAC requirement, got %+v", resolved)
+	}
+	if len(resolved.ExcludedCountries) != 2 || resolved.ExcludedCountries[0] != common.PRK || resolved.ExcludedCountries[1] != common.IRN {
+		t.Errorf("Expected excluded countries to be merged, got %v", resolved.ExcludedCountries)
+	}
+	if len(resolved.AllowedIssuingStates) != 1 || resolved.AllowedIssuingStates[0] != common.ITA {
+		t.Errorf("Expected allowed issuing states to be replaced, got %v", resolved.AllowedIssuingStates)
+	}
+
+	// Trusting the proof's OFAC check relaxes screening, so a child cannot enable it alone
+	set("trusting", self.VerificationConfig{Parent: "base", TrustProofOfac: true})
+	if resolved, err := self.ResolveConfig(ctx, store, "trusting"); err != nil || resolved.TrustProofOfac {
+		t.Errorf("Expected a child not to trust the proof's OFAC check over its parent, got %+v, %v", resolved, err)
+	}
+	set("trusting-base", self.VerificationConfig{MinimumAge: 18, Ofac: true, TrustProofOfac: true})
+	set("trusting-child", self.VerificationConfig{Parent: "trusting-base", TrustProofOfac: true})
+	if resolved, err := self.ResolveConfig(ctx, store, "trusting-child"); err != nil || !resolved.TrustProofOfac {
+		t.Errorf("Expected the proof's OFAC check to be trusted when both configs set it, got %+v, %v", resolved, err)
+	}
+
+	set("orphan", self.VerificationConfig{Parent: "missing"})
+	if _, err := self.ResolveConfig(ctx, store, "orphan"); !errors.Is(err, self.ErrConfigNotFound) {
+		t.Errorf("Expected ErrConfigNotFound for a missing parent, got %v", err)
+	}
+
+	set("loop-a", self.VerificationConfig{Parent: "loop-b"})
+	set("loop-b", self.VerificationConfig{Parent: "loop-a", MinimumAge: 18})
+	if _, err := self.ResolveConfig(ctx, store, "loop-a"); !errors.Is(err, self.ErrConfigInheritance) {
+		t.Errorf("Expected ErrConfigInheritance for a loop, got %v", err)
+	}
+}
+
+func TestVerifyWithConfig(t *testing.T) {
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{self.Passport: true},
+		failingConfigStore{},
+		self.UserIDTypeUUID,
+		offlineTestOptions(t)...,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	ctx := context.Background()
+	input := self.VerifyInput{
+		AttestationId:   int(self.Passport),
+		Proof:           testProof,
+		PublicSignals:   testPublicSignals,
+		UserContextData: createTestUserContextData(),
+	}
+
+	if _, err := verifier.Verify(ctx, input.AttestationId, input.Proof, input.PublicSignals, input.UserContextData); !errors.Is(err, self.ErrConfigNotFound) {
+		t.Fatalf("Expected the failing store to fail Verify, got %v", err)
+	}
+
+	// The snapshot is checked without calling the store
+	if result, err := verifier.VerifyWithConfig(ctx, input, createTestVerificationConfig()); err != nil || !result.IsValidDetails.IsValid {
+		t.Errorf("Expected the snapshot to bypass the store, got %+v, %v", result, err)
+	}
+	snapshot := createTestVerificationConfig()
+	snapshot.SanctionedNationalities = []common.Country3LetterCode{common.IRN}
+	if _, err := verifier.VerifyWithConfig(ctx, input, snapshot); !errors.Is(err, self.ErrSanctionedNationality) {
+		t.Errorf("Expected the snapshot's policy to apply, got %v", err)
+	}
+
+	snapshot.Parent = "base"
+	if _, err := verifier.VerifyWithConfig(ctx, input, snapshot); !errors.Is(err, self.ErrConfigInheritance) {
+		t.Errorf("Expected an unresolved snapshot to fail with ErrConfigInheritance, got %v", err)
+	}
+}
+
+func TestVerificationConfig_ActiveChecks(t *testing.T) {
+	config := self.VerificationConfig{
+		MinimumAge:           18,
+		MinimumAgeMarginDays: 1,
+		ExcludedCountries:    []common.Country3LetterCode{common.PRK, common.IRN},
+		Ofac:                 true,
+		AdvisoryChecks:       []self.CheckName{self.CheckOfac},
+	}
+	checks := config.ActiveChecks()
+
+	expected := []self.CheckName{self.CheckMinimumAge, self.CheckExcludedCountries, self.CheckOfac, self.CheckTimestamp}
+	if len(checks) != len(expected) {
+		t.Fatalf("Expected checks %v, got %+v", expected, checks)
+	}
+	for i, check := range checks {
+		if check.Name != expected[i] || check.Description == "" {
+			t.Errorf("Check %d: expected %s with a description, got %+v", i, expected[i], check)
+		}
+		if check.Advisory != (check.Name == self.CheckOfac) {
+			t.Errorf("Check %s: unexpected advisory flag %v", check.Name, check.Advisory)
+		}
+	}
+	if checks[0].Parameters["minimumAge"] != 18 || checks[0].Parameters["minimumAgeMarginDays"] != 1 {
+		t.Errorf("Expected the age parameters, got %v", checks[0].Parameters)
+	}
+	if countries := checks[1].Parameters["excludedCountries"].([]common.Country3LetterCode); len(countries) != 2 || countries[0] != common.IRN {
+		t.Errorf("Expected sorted excluded countries, got %v", countries)
+	}
+	if checks[2].Parameters["ofacMode"] != self.OFACModeProof {
+		t.Errorf("Expected the default OFAC mode, got %v", checks[2].Parameters)
+	}
+}
@@ -0,0 +1,33 @@
+package selfBackendVerifier
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func TestVerify_InconsistentDisclosure(t *testing.T) {
+	config := createTestVerificationConfig()
+
+	// The test proof proves the holder is at least 18; a date of birth in 2015 contradicts it
+	signals := withRevealedData(self.RevealedDataIndices[self.Passport].DateOfBirthStart, "150101")
+	_, err := verifyOffline(t, config, signals)
+	var inconsistentErr *self.InconsistentDisclosureError
+	if !errors.As(err, &inconsistentErr) || !errors.Is(err, self.ErrInconsistentDisclosure) {
+		t.Fatalf("Expected an *InconsistentDisclosureError, got %v", err)
+	}
+	if strings.Join(inconsistentErr.Fields, ",") != "dateOfBirth,minimumAge" {
+		t.Errorf("Expected the conflicting fields to be named, got %v", inconsistentErr.Fields)
+	}
+	if code := self.ErrorCodeFor(err); code != self.ErrorCodeInconsistentDisclosure {
+		t.Errorf("Expected code %s, got %s", self.ErrorCodeInconsistentDisclosure, code)
+	}
+
+	// The unmodified disclosure is consistent
+	result, err := verifyOffline(t, config, testPublicSignals)
+	if err != nil || !result.IsValidDetails.IsValid {
+		t.Errorf("Expected the test proof to be consistent, got %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,78 @@
+package selfBackendVerifier
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+// encodedCredential returns the JSON document of the verifiable credential issued for result
+func encodedCredential(t *testing.T, result *self.VerificationResult) map[string]interface{} {
+	t.Helper()
+	credential, err := result.ToVerifiableCredential("did:web:example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	encoded, err := json.Marshal(credential)
+	if err != nil {
+		t.Fatalf("Failed to encode credential: %v", err)
+	}
+	var document map[string]interface{}
+	if err := json.Unmarshal(encoded, &document); err != nil {
+		t.Fatalf("Failed to decode credential: %v", err)
+	}
+	return document
+}
+
+func TestToVerifiableCredential(t *testing.T) {
+	result, err := verifyOffline(t, createTestVerificationConfig(), testPublicSignals)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	before := time.Now().UTC().Truncate(time.Second)
+	document := encodedCredential(t, result)
+
+	expected := map[string]interface{}{
+		"@context":          []interface{}{self.CredentialsContextV1},
+		"type":              []interface{}{self.VerifiableCredentialType, self.SelfIdentityCredential},
+		"issuer":            "did:web:example.com",
+		"credentialSubject": map[string]interface{}{"dateOfBirth": "980327", "minimumAge": float64(18)},
+	}
+	issuanceDate, _ := document["issuanceDate"].(string)
+	delete(document, "issuanceDate")
+	if !reflect.DeepEqual(document, expected) {
+		t.Errorf("Expected credential %v, got %v", expected, document)
+	}
+	issued, err := time.Parse(time.RFC3339, issuanceDate)
+	if err != nil || issued.Before(before) || issued.After(time.Now()) {
+		t.Errorf("Expected an RFC 3339 issuance date of now, got %q", issuanceDate)
+	}
+
+	// Fields masked from the result are left out of the subject
+	config := createTestVerificationConfig()
+	config.Disclosures = &self.SelfAppDisclosureConfig{Name: true}
+	result, err = verifyOffline(t, config, testPublicSignals)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	subject := encodedCredential(t, result)["credentialSubject"]
+	if expected := map[string]interface{}{"minimumAge": float64(18)}; !reflect.DeepEqual(subject, expected) {
+		t.Errorf("Expected the masked subject %v, got %v", expected, subject)
+	}
+}
+
+func TestToVerifiableCredential_InvalidResult(t *testing.T) {
+	result := &self.VerificationResult{IsValidDetails: self.IsValidDetails{IsValid: false}}
+	if _, err := result.ToVerifiableCredential("did:web:example.com"); !errors.Is(err, self.ErrInvalidProof) {
+		t.Errorf("Expected ErrInvalidProof for an invalid result, got %v", err)
+	}
+
+	result.IsValidDetails.IsValid = true
+	if _, err := result.ToVerifiableCredential(""); err == nil {
+		t.Error("Expected an error without an issuer")
+	}
+}
@@ -0,0 +1,74 @@
+package selfBackendVerifier
+
+import (
+	"testing"
+	"time"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func TestFormatDateOfBirthISO(t *testing.T) {
+	now := time.Date(2026, time.October, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		attestationId self.AttestationId
+		value         string
+		expected      string
+	}{
+		{self.Passport, "980327", "1998-03-27"},
+		{self.Passport, "000101", "2000-01-01"},
+		// Around the pivot: today resolves to this century, tomorrow to the previous one
+		{self.Passport, "261015", "2026-10-15"},
+		{self.Passport, "261016", "1926-10-16"},
+		{self.Passport, "270101", "1927-01-01"},
+		{self.EUCard, "991231", "1999-12-31"},
+		{self.Aadhaar, "19900215", "1990-02-15"},
+		{self.Passport, "991332", ""},
+		{self.Passport, "\x00\x00\x00\x00\x00\x00", ""},
+	}
+	for _, tt := range tests {
+		if got := self.FormatDateOfBirthISO(tt.attestationId, tt.value, now); got != tt.expected {
+			t.Errorf("FormatDateOfBirthISO(%d, %q) = %q, expected %q", tt.attestationId, tt.value, got, tt.expected)
+		}
+	}
+}
+
+func TestFormatExpiryDateISO(t *testing.T) {
+	tests := map[string]string{
+		"300101": "2030-01-01",
+		"991231": "2099-12-31",
+		"000229": "2000-02-29",
+		"010229": "",
+		"":       "",
+	}
+	for value, expected := range tests {
+		if got := self.FormatExpiryDateISO(value); got != expected {
+			t.Errorf("FormatExpiryDateISO(%q) = %q, expected %q", value, got, expected)
+		}
+	}
+}
+
+func TestFormatDateOfBirthISO_CenturyRollover(t *testing.T) {
+	tests := []struct {
+		now      time.Time
+		value    string
+		expected string
+	}{
+		// On the first day of a century, only its first day resolves to it
+		{time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC), "000101", "2000-01-01"},
+		{time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC), "000102", "1900-01-02"},
+		{time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC), "991231", "1999-12-31"},
+		// On the last day of a century, every two-digit year resolves to it
+		{time.Date(2099, time.December, 31, 0, 0, 0, 0, time.UTC), "991231", "2099-12-31"},
+		{time.Date(2099, time.December, 31, 0, 0, 0, 0, time.UTC), "000101", "2000-01-01"},
+		{time.Date(2100, time.January, 1, 0, 0, 0, 0, time.UTC), "991231", "2099-12-31"},
+		{time.Date(2100, time.January, 1, 0, 0, 0, 0, time.UTC), "000101", "2100-01-01"},
+		// 2000 was a leap year, 1900 and 2100 were not
+		{time.Date(2026, time.October, 15, 0, 0, 0, 0, time.UTC), "000229", "2000-02-29"},
+		{time.Date(2100, time.December, 31, 0, 0, 0, 0, time.UTC), "000229", ""},
+	}
+	for _, tt := range tests {
+		if got := self.FormatDateOfBirthISO(self.Passport, tt.value, tt.now); got != tt.expected {
+			t.Errorf("FormatDateOfBirthISO(%q) on %s = %q, expected %q", tt.value, tt.now.Format("2006-01-02"), got, tt.expected)
+		}
+	}
+}
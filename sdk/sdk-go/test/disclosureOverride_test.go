@@ -0,0 +1,34 @@
+package selfBackendVerifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func TestVerify_RequestDisclosures(t *testing.T) {
+	verifyWithDisclosures := func(config self.VerificationConfig, disclosures self.SelfAppDisclosureConfig) (*self.VerificationResult, error) {
+		ctx := self.ContextWithDisclosures(context.Background(), disclosures)
+		return newOfflineTestVerifier(t, config).Verify(ctx, 1, testProof, testPublicSignals, createTestUserContextData())
+	}
+
+	// The test proof discloses only the date of birth
+	result, err := verifyWithDisclosures(createTestVerificationConfig(), self.SelfAppDisclosureConfig{DateOfBirth: true})
+	if err != nil || !result.IsValidDetails.IsValid {
+		t.Errorf("Expected the requested date of birth to be accepted, got %+v, %v", result, err)
+	}
+
+	_, err = verifyWithDisclosures(createTestVerificationConfig(), self.SelfAppDisclosureConfig{DateOfBirth: true, Name: true})
+	if !errors.Is(err, self.ErrMissingDisclosure) || self.ErrorCodeFor(err) != self.ErrorCodeMissingDisclosure {
+		t.Errorf("Expected ErrMissingDisclosure, got %v", err)
+	}
+
+	config := createTestVerificationConfig()
+	config.Disclosures = &self.SelfAppDisclosureConfig{Name: true}
+	_, err = verifyWithDisclosures(config, self.SelfAppDisclosureConfig{DateOfBirth: true})
+	if !errors.Is(err, self.ErrDisclosureOverrideNotAllowed) {
+		t.Errorf("Expected ErrDisclosureOverrideNotAllowed, got %v", err)
+	}
+}
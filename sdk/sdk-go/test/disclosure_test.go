@@ -0,0 +1,268 @@
+package selfBackendVerifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+// Helper function to build a result from the revealed data of the test proof
+func createTestVerificationResult(t *testing.T) *self.VerificationResult {
+	discloseOutput, err := self.FormatRevealedDataPacked(self.Passport, testPublicSignals)
+	if err != nil {
+		t.Fatalf("Failed to format revealed data: %v", err)
+	}
+	return &self.VerificationResult{
+		AttestationId:  self.Passport,
+		DiscloseOutput: discloseOutput,
+	}
+}
+
+func TestDisclosedFieldSet(t *testing.T) {
+	result := createTestVerificationResult(t)
+	t.Logf("Disclose output: %+v", result.DiscloseOutput)
+
+	// The test proof only reveals the date of birth
+	disclosed := result.DisclosedFieldSet()
+	if len(disclosed) != 1 || !disclosed[self.DisclosureDateOfBirth] {
+		t.Errorf("Expected only %s to be disclosed, got %v", self.DisclosureDateOfBirth, disclosed)
+	}
+}
+
+func TestDisclosedFieldSet_Masked(t *testing.T) {
+	// The policy requests only the name, so the disclosed date of birth is masked from the result
+	config := createTestVerificationConfig()
+	config.Disclosures = &self.SelfAppDisclosureConfig{Name: true}
+	result, err := verifyOffline(t, config, testPublicSignals)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.DiscloseOutput.DateOfBirth != "" {
+		t.Fatalf("Expected the date of birth to be masked, got %q", result.DiscloseOutput.DateOfBirth)
+	}
+
+	disclosed := result.DisclosedFieldSet()
+	if len(disclosed) != 1 || !disclosed[self.DisclosureDateOfBirth] {
+		t.Errorf("Expected only %s to be disclosed by the proof, got %v", self.DisclosureDateOfBirth, disclosed)
+	}
+	extra := disclosed.Difference(config.Disclosures.Set()).Fields()
+	if len(extra) != 1 || extra[0] != self.DisclosureDateOfBirth {
+		t.Errorf("Expected the date of birth to be disclosed beyond the policy, got %v", extra)
+	}
+	if subject := self.NewCredentialSubject(result); subject != (self.CredentialSubject{MinimumAge: "18"}) {
+		t.Errorf("Expected the masked date of birth to stay out of the subject, got %+v", subject)
+	}
+}
+
+func TestNewCredentialSubject(t *testing.T) {
+	result := createTestVerificationResult(t)
+
+	subject := self.NewCredentialSubject(result)
+	expected := self.CredentialSubject{DateOfBirth: "980327", MinimumAge: "18"}
+	if subject != expected {
+		t.Errorf("Expected credential subject %+v, got %+v", expected, subject)
+	}
+}
+
+func TestWithIncludeRawDisclosure_LogsWarning(t *testing.T) {
+	var logs bytes.Buffer
+	_, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{self.Passport: true},
+		createTestMockConfigStore(createTestVerificationConfig()),
+		self.UserIDTypeUUID,
+		self.WithLogger(log.New(&logs, "", 0)),
+		self.WithIncludeRawDisclosure(true),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	if !strings.Contains(logs.String(), "raw disclosures") {
+		t.Errorf("Expected a warning about raw disclosures, got %q", logs.String())
+	}
+}
+
+func TestNormalizedName(t *testing.T) {
+	tests := []struct {
+		name           string
+		normalized     string
+		transliterated string
+	}{
+		{"JOHN  SMITH\x00\x00", "JOHN SMITH", "JOHN SMITH"},
+		// Decomposed "e" with a combining acute accent is composed by NFC
+		{"Jose\u0301 Núñez", "José Núñez", "Jose Nunez"},
+		{"Søren Ærø Straße", "Søren Ærø Straße", "Soren AEro Strasse"},
+		{"Łukasz 李", "Łukasz 李", "Lukasz"},
+	}
+	for _, tt := range tests {
+		output := self.GenericDiscloseOutput{Name: tt.name}
+		if got := output.NormalizedName(); got != tt.normalized {
+			t.Errorf("NormalizedName(%q) = %q, expected %q", tt.name, got, tt.normalized)
+		}
+		if got := output.TransliteratedName(); got != tt.transliterated {
+			t.Errorf("TransliteratedName(%q) = %q, expected %q", tt.name, got, tt.transliterated)
+		}
+	}
+}
+
+func TestMaskDisclosures_PredicateOnly(t *testing.T) {
+	// Predicates only: the policy proves age and excluded countries but requests no attributes
+	config := createTestVerificationConfig()
+	config.Disclosures = &self.SelfAppDisclosureConfig{}
+
+	result := createTestVerificationResult(t)
+	result.DiscloseOutput = config.MaskDisclosures(result.DiscloseOutput)
+
+	if disclosed := result.DisclosedFieldSet(); len(disclosed) != 0 {
+		t.Errorf("Expected no disclosed fields, got %v", disclosed)
+	}
+	if subject := self.NewCredentialSubject(result); subject != (self.CredentialSubject{MinimumAge: "18"}) {
+		t.Errorf("Expected only the minimum age predicate, got %+v", subject)
+	}
+	if result.DiscloseOutput.MRZ != nil {
+		t.Error("Expected the MRZ components to be dropped")
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("Failed to marshal result: %v", err)
+	}
+	for _, pii := range []string{"980327", `\u0000`} {
+		if strings.Contains(string(encoded), pii) {
+			t.Errorf("Expected no %q in the result, got %s", pii, encoded)
+		}
+	}
+}
+
+func TestMaskDisclosures_PersonalNumber(t *testing.T) {
+	result := &self.VerificationResult{
+		AttestationId:  self.Passport,
+		DiscloseOutput: self.GenericDiscloseOutput{IdNumber: "L898902C3", PersonalNumber: "ZE184226B"},
+	}
+	disclosed := result.DisclosedFieldSet()
+	if !disclosed[self.DisclosureDocumentNumber] || !disclosed[self.DisclosurePersonalNumber] {
+		t.Errorf("Expected document and personal numbers to be disclosed, got %v", disclosed)
+	}
+
+	// Requesting the document number does not release the personal number
+	config := self.VerificationConfig{Disclosures: &self.SelfAppDisclosureConfig{PassportNumber: true}}
+	result.DiscloseOutput = config.MaskDisclosures(result.DiscloseOutput)
+	subject := self.NewCredentialSubject(result)
+	if subject.IdNumber != "L898902C3" || subject.PersonalNumber != "" {
+		t.Errorf("Expected only the document number, got %+v", subject)
+	}
+}
+
+func TestDisclosureStatuses(t *testing.T) {
+	// The test proof only reveals the date of birth
+	output := createTestVerificationResult(t).DiscloseOutput
+
+	config := self.VerificationConfig{Disclosures: &self.SelfAppDisclosureConfig{Name: true, DateOfBirth: true}}
+	statuses := config.DisclosureStatuses(self.Passport, output)
+	expected := map[self.DisclosureField]self.DisclosureStatus{
+		self.DisclosureDateOfBirth: self.DisclosureStatusDisclosed,
+		self.DisclosureName:        self.DisclosureStatusNotProvided,
+		self.DisclosureGender:      self.DisclosureStatusHiddenByPolicy,
+	}
+	for field, status := range expected {
+		if statuses[field] != status {
+			t.Errorf("Expected %s to be %s, got %s", field, status, statuses[field])
+		}
+	}
+	if len(statuses) != len(self.AllDisclosureFields) {
+		t.Errorf("Expected a status for every field, got %v", statuses)
+	}
+
+	// Without restricted disclosures nothing is hidden by policy
+	statuses = self.VerificationConfig{}.DisclosureStatuses(self.Passport, output)
+	if statuses[self.DisclosureGender] != self.DisclosureStatusNotProvided || statuses[self.DisclosureDateOfBirth] != self.DisclosureStatusDisclosed {
+		t.Errorf("Expected statuses from the proof alone, got %v", statuses)
+	}
+
+	// The expiry date replaced by the document validity is hidden by policy
+	output.ExpiryDate = "300101"
+	statuses = self.VerificationConfig{ExpiryDisclosure: self.ExpiryDisclosureValidity}.DisclosureStatuses(self.Passport, output)
+	if statuses[self.DisclosureExpiryDate] != self.DisclosureStatusHiddenByPolicy {
+		t.Errorf("Expected the expiry date to be hidden by policy, got %s", statuses[self.DisclosureExpiryDate])
+	}
+}
+
+func TestDisclosureSet(t *testing.T) {
+	config := self.SelfAppDisclosureConfig{Name: true, PassportNumber: true, PersonalNumber: true}
+	set := config.Set()
+	expected := []self.DisclosureField{self.DisclosureName, self.DisclosureDocumentNumber, self.DisclosurePersonalNumber}
+	if fields := set.Fields(); !reflect.DeepEqual(fields, expected) {
+		t.Errorf("Expected fields %v, got %v", expected, fields)
+	}
+	if roundTrip := set.DisclosureConfig(); roundTrip != config {
+		t.Errorf("Expected %+v after the round trip, got %+v", config, roundTrip)
+	}
+
+	disclosed := self.NewDisclosureSet(self.DisclosureName, self.DisclosureGender)
+	if unrequested := disclosed.Difference(set).Fields(); !reflect.DeepEqual(unrequested, []self.DisclosureField{self.DisclosureGender}) {
+		t.Errorf("Expected only %s to be unrequested, got %v", self.DisclosureGender, unrequested)
+	}
+}
+
+func TestGenericDiscloseOutput_Matches(t *testing.T) {
+	stored := self.GenericDiscloseOutput{
+		Name:           "José  García",
+		IdNumber:       "L898902C3",
+		DateOfBirthISO: "1998-03-27",
+		Nationality:    "ESP",
+	}
+	disclosed := self.GenericDiscloseOutput{
+		Name:        "JOSÉ GARCÍA\x00\x00",
+		IdNumber:    "L898902C3",
+		DateOfBirth: "980327",
+		Nationality: "FRA",
+	}
+
+	matched, differing := disclosed.Matches(stored, self.DisclosureName, self.DisclosureDateOfBirth, self.DisclosureDocumentNumber)
+	if !matched || len(differing) != 0 {
+		t.Errorf("Expected the normalized name and date to match, got differing fields %v", differing)
+	}
+
+	matched, differing = disclosed.Matches(stored, self.DisclosureNationality, self.DisclosureGender, self.DisclosureName)
+	expected := []self.DisclosureField{self.DisclosureNationality, self.DisclosureGender}
+	if matched || !reflect.DeepEqual(differing, expected) {
+		t.Errorf("Expected %v to differ, got %v", expected, differing)
+	}
+
+	// Without fields, every field held by either output is compared
+	if _, differing := disclosed.Matches(stored); !reflect.DeepEqual(differing, []self.DisclosureField{self.DisclosureNationality}) {
+		t.Errorf("Expected only %s to differ, got %v", self.DisclosureNationality, differing)
+	}
+}
+
+func TestVerify_StrictDisclosureMode(t *testing.T) {
+	// The test proof discloses the date of birth, which this policy does not request
+	config := createTestVerificationConfig()
+	config.Disclosures = &self.SelfAppDisclosureConfig{Name: true}
+	result, err := verifyOffline(t, config, testPublicSignals)
+	if err != nil || !result.IsValidDetails.IsValid {
+		t.Fatalf("Expected lenient mode to accept over-disclosure, got %+v, %v", result, err)
+	}
+	if result.DiscloseOutput.DateOfBirth != "" {
+		t.Errorf("Expected lenient mode to mask the unrequested date of birth, got %q", result.DiscloseOutput.DateOfBirth)
+	}
+
+	_, err = verifyOffline(t, config, testPublicSignals, self.WithDisclosureMode(self.DisclosureModeStrict))
+	if !errors.Is(err, self.ErrOverDisclosure) {
+		t.Errorf("Expected ErrOverDisclosure in strict mode, got %v", err)
+	}
+
+	config.Disclosures.DateOfBirth = true
+	result, err = verifyOffline(t, config, testPublicSignals, self.WithDisclosureMode(self.DisclosureModeStrict))
+	if err != nil || result.DiscloseOutput.DateOfBirth == "" {
+		t.Errorf("Expected requested disclosures to pass in strict mode, got %+v, %v", result, err)
+	}
+}
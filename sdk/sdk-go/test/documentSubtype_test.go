@@ -0,0 +1,38 @@
+package selfBackendVerifier
+
+import (
+	"errors"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func TestVerify_AllowedDocumentSubtypes(t *testing.T) {
+	// The test proof does not disclose its document code
+	config := createTestVerificationConfig()
+	config.AllowedDocumentSubtypes = []self.DocumentSubtype{self.DocumentSubtypeRegular}
+	_, err := verifyOffline(t, config, testPublicSignals)
+	if !errors.Is(err, self.ErrDocumentSubtypeNotAllowed) || self.ErrorCodeFor(err) != self.ErrorCodeDocumentSubtypeNotAllowed {
+		t.Errorf("Expected ErrDocumentSubtypeNotAllowed, got %v", err)
+	}
+
+	if _, err := verifyOffline(t, config, withRevealedData(0, "PD")); !errors.Is(err, self.ErrDocumentSubtypeNotAllowed) {
+		t.Errorf("Expected ErrDocumentSubtypeNotAllowed for a diplomatic passport, got %v", err)
+	}
+
+	result, err := verifyOffline(t, config, withRevealedData(0, "P<"))
+	if err != nil || !result.IsValidDetails.IsValid {
+		t.Errorf("Expected a regular passport to pass, got %+v, %v", result, err)
+	}
+
+	config.AllowedDocumentSubtypes = nil
+	result, err = verifyOffline(t, config, testPublicSignals)
+	if err != nil || !result.IsValidDetails.IsValid {
+		t.Errorf("Expected every subtype to be accepted by default, got %+v, %v", result, err)
+	}
+
+	config.AllowedDocumentSubtypes = []self.DocumentSubtype{"consular"}
+	if err := self.ValidateVerificationConfig(config); err == nil {
+		t.Error("Expected an unknown document subtype to be rejected")
+	}
+}
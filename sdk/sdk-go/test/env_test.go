@@ -0,0 +1,98 @@
+package selfBackendVerifier
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+// unsetVerifierEnv clears the variables read by LoadVerifierEnvConfig for the duration of the test
+func unsetVerifierEnv(t *testing.T) {
+	for _, name := range []string{self.EnvScope, self.EnvEndpoint, self.EnvNetwork, self.EnvUserIDType, self.EnvAllowedAttestation} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+}
+
+func TestLoadVerifierEnvConfig_Defaults(t *testing.T) {
+	unsetVerifierEnv(t)
+	config, err := self.LoadVerifierEnvConfig()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(config, self.DefaultVerifierEnvConfig()) {
+		t.Errorf("Expected the defaults, got %+v", config)
+	}
+}
+
+func TestLoadVerifierEnvConfig(t *testing.T) {
+	unsetVerifierEnv(t)
+	t.Setenv(self.EnvScope, " my-app ")
+	t.Setenv(self.EnvEndpoint, "https://example.com/api/verify")
+	t.Setenv(self.EnvNetwork, "MAINNET")
+	t.Setenv(self.EnvUserIDType, "hex")
+	t.Setenv(self.EnvAllowedAttestation, "passport, 3")
+
+	config, err := self.LoadVerifierEnvConfig()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := self.VerifierEnvConfig{
+		Scope:      "my-app",
+		Endpoint:   "https://example.com/api/verify",
+		Mainnet:    true,
+		UserIDType: self.UserIDTypeHex,
+		AllowedIds: map[self.AttestationId]bool{self.Passport: true, self.Aadhaar: true},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, config)
+	}
+}
+
+func TestLoadVerifierEnvConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected []string
+	}{
+		{name: "network", env: map[string]string{self.EnvNetwork: "devnet"}, expected: []string{self.EnvNetwork, `"devnet"`}},
+		{name: "user ID type", env: map[string]string{self.EnvUserIDType: "email"}, expected: []string{self.EnvUserIDType, `"email"`}},
+		{name: "empty scope", env: map[string]string{self.EnvScope: " "}, expected: []string{self.EnvScope, "must not be empty"}},
+		{name: "long scope", env: map[string]string{self.EnvScope: strings.Repeat("a", 32)}, expected: []string{self.EnvScope, "at most 31"}},
+		{name: "non-ASCII scope", env: map[string]string{self.EnvScope: "sélf"}, expected: []string{self.EnvScope, "ASCII"}},
+		{
+			name: "every invalid variable",
+			env: map[string]string{
+				self.EnvScope:              "",
+				self.EnvEndpoint:           "",
+				self.EnvNetwork:            "devnet",
+				self.EnvUserIDType:         "email",
+				self.EnvAllowedAttestation: "passport,drivers_license",
+			},
+			expected: []string{self.EnvScope, self.EnvEndpoint, self.EnvNetwork, self.EnvUserIDType, self.EnvAllowedAttestation, `"drivers_license"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unsetVerifierEnv(t)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			config, err := self.LoadVerifierEnvConfig()
+			if err == nil {
+				t.Fatalf("Expected an error, got %+v", config)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("Expected the error to mention %s, got %v", expected, err)
+				}
+			}
+			if !reflect.DeepEqual(config, self.VerifierEnvConfig{}) {
+				t.Errorf("Expected an empty config on error, got %+v", config)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package selfBackendVerifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+	common "github.com/selfxyz/self/sdk/sdk-go/common"
+)
+
+func TestBuildFrontendConfig(t *testing.T) {
+	config := createTestVerificationConfig()
+	config.SanctionedNationalities = []common.Country3LetterCode{common.IRN}
+	config.RequireNationalityMatchesIssuer = true
+	config.Disclosures = &self.SelfAppDisclosureConfig{Name: true}
+
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		true,
+		map[self.AttestationId]bool{self.Passport: true},
+		createTestMockConfigStore(config),
+		self.UserIDTypeUUID,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+
+	frontend, err := verifier.BuildFrontendConfig(context.Background(), "test-config-id")
+	if err != nil {
+		t.Fatalf("Failed to build frontend config: %v", err)
+	}
+	if frontend.Scope != "self-playground" || frontend.EndpointType != self.FrontendEndpointTypeStagingHTTPS || frontend.UserIdType != self.UserIDTypeUUID {
+		t.Errorf("Unexpected frontend config: %+v", frontend)
+	}
+	if got := strings.Join(frontend.Disclosures.ExcludedCountries, ","); got != "IRN,PRK" {
+		t.Errorf("Expected sanctioned nationalities to be excluded in the circuit, got %s", got)
+	}
+
+	encoded, _ := json.Marshal(frontend)
+	for _, expected := range []string{`"minimumAge":18`, `"name":true`, `"nationality":true`, `"issuing_state":true`} {
+		if !strings.Contains(string(encoded), expected) {
+			t.Errorf("Expected %s in %s", expected, encoded)
+		}
+	}
+
+	if _, err := verifier.BuildFrontendConfig(context.Background(), "missing"); !errors.Is(err, self.ErrConfigNotFound) {
+		t.Errorf("Expected ErrConfigNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package selfBackendVerifier
+
+import (
+	"errors"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+	common "github.com/selfxyz/self/sdk/sdk-go/common"
+)
+
+func TestVerify_AllowedIssuingStates(t *testing.T) {
+	indices := self.RevealedDataIndices[self.Passport]
+	config := createTestVerificationConfig()
+	result, err := verifyOffline(t, config, testPublicSignals)
+	if err != nil || !result.IsValidDetails.IsValid {
+		t.Errorf("Expected no issuing state check without AllowedIssuingStates, got %+v, %v", result, err)
+	}
+
+	// The test proof does not disclose its issuing state, so provenance cannot be established
+	config.AllowedIssuingStates = []common.Country3LetterCode{common.DEU}
+	if _, err := verifyOffline(t, config, testPublicSignals); !errors.Is(err, self.ErrIssuingStateNotAllowed) {
+		t.Errorf("Expected ErrIssuingStateNotAllowed, got %v", err)
+	}
+
+	if _, err := verifyOffline(t, config, withRevealedData(indices.IssuingStateStart, "FRA")); !errors.Is(err, self.ErrIssuingStateNotAllowed) {
+		t.Errorf("Expected ErrIssuingStateNotAllowed for another issuing state, got %v", err)
+	}
+
+	result, err = verifyOffline(t, config, withRevealedData(indices.IssuingStateStart, "DEU"))
+	if err != nil || !result.IsValidDetails.IsValid {
+		t.Errorf("Expected an allowed issuing state to pass, got %+v, %v", result, err)
+	}
+}
+
+func TestVerify_RequireNationalityMatchesIssuer(t *testing.T) {
+	config := createTestVerificationConfig()
+	config.RequireNationalityMatchesIssuer = true
+	indices := self.RevealedDataIndices[self.Passport]
+	disclosing := func(issuingState, nationality string) []string {
+		return revealData(withRevealedData(indices.IssuingStateStart, issuingState), indices.NationalityStart, nationality)
+	}
+
+	// The test proof discloses neither its nationality nor its issuing state, so nothing is compared
+	result, err := verifyOffline(t, config, testPublicSignals)
+	if err != nil || !result.IsValidDetails.IsValid {
+		t.Errorf("Expected a valid result when the fields are not disclosed, got %+v, %v", result, err)
+	}
+
+	result, err = verifyOffline(t, config, disclosing("FRA", "FRA"))
+	if err != nil || !result.IsValidDetails.IsValid {
+		t.Errorf("Expected a valid result for a matching nationality, got %+v, %v", result, err)
+	}
+
+	_, err = verifyOffline(t, config, disclosing("FRA", "DEU"))
+	if !errors.Is(err, self.ErrNationalityIssuerMismatch) {
+		t.Errorf("Expected ErrNationalityIssuerMismatch, got %v", err)
+	}
+	if self.ErrorCodeFor(err) != self.ErrorCodeNationalityIssuerMismatch {
+		t.Errorf("Expected code %s, got %s", self.ErrorCodeNationalityIssuerMismatch, self.ErrorCodeFor(err))
+	}
+}
@@ -0,0 +1,107 @@
+package selfBackendVerifier
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func TestVerificationResult_ToJWT(t *testing.T) {
+	result := createTestVerificationResult(t)
+	if _, err := result.ToJWT(self.JWTSigner{Algorithm: self.JWTAlgorithmHS256, Key: []byte("secret")}); !errors.Is(err, self.ErrInvalidProof) {
+		t.Errorf("Expected ErrInvalidProof for an invalid result, got %v", err)
+	}
+
+	result.IsValidDetails.IsValid = true
+	result.Scope = "self-playground"
+	result.ConfigId = "test-config-id"
+	result.UserData.UserIdentifier = "user-1"
+
+	ecdsaKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ed25519Public, ed25519Private, _ := ed25519.GenerateKey(rand.Reader)
+	signers := map[self.JWTAlgorithm]struct {
+		signer self.JWTSigner
+		verify func(signingInput, signature []byte) bool
+	}{
+		self.JWTAlgorithmHS256: {
+			self.JWTSigner{Algorithm: self.JWTAlgorithmHS256, Key: []byte("secret"), KeyID: "k1", Issuer: "backend"},
+			func(signingInput, signature []byte) bool {
+				mac := hmac.New(sha256.New, []byte("secret"))
+				mac.Write(signingInput)
+				return hmac.Equal(mac.Sum(nil), signature)
+			},
+		},
+		self.JWTAlgorithmES256: {
+			self.JWTSigner{Algorithm: self.JWTAlgorithmES256, Key: ecdsaKey, KeyID: "k1", Issuer: "backend"},
+			func(signingInput, signature []byte) bool {
+				digest := sha256.Sum256(signingInput)
+				r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+				return len(signature) == 64 && ecdsa.Verify(&ecdsaKey.PublicKey, digest[:], r, s)
+			},
+		},
+		self.JWTAlgorithmEdDSA: {
+			self.JWTSigner{Algorithm: self.JWTAlgorithmEdDSA, Key: ed25519Private, KeyID: "k1", Issuer: "backend"},
+			func(signingInput, signature []byte) bool {
+				return ed25519.Verify(ed25519Public, signingInput, signature)
+			},
+		},
+	}
+
+	for algorithm, test := range signers {
+		token, err := result.ToJWT(test.signer)
+		if err != nil {
+			t.Fatalf("%s: failed to build JWT: %v", algorithm, err)
+		}
+		parts := strings.Split(token, ".")
+		if len(parts) != 3 {
+			t.Fatalf("%s: expected a compact JWS, got %q", algorithm, token)
+		}
+		signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		if !test.verify([]byte(parts[0]+"."+parts[1]), signature) {
+			t.Errorf("%s: signature does not verify", algorithm)
+		}
+
+		var header map[string]string
+		headerJSON, _ := base64.RawURLEncoding.DecodeString(parts[0])
+		if err := json.Unmarshal(headerJSON, &header); err != nil || header["alg"] != string(algorithm) || header["kid"] != "k1" {
+			t.Errorf("%s: unexpected header %s", algorithm, headerJSON)
+		}
+
+		var claims self.VerificationClaims
+		payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		if err := json.Unmarshal(payload, &claims); err != nil {
+			t.Fatalf("%s: failed to decode claims: %v", algorithm, err)
+		}
+		if claims.Issuer != "backend" || claims.Subject != "user-1" || claims.Scope != "self-playground" || claims.ConfigId != "test-config-id" {
+			t.Errorf("%s: unexpected claims %+v", algorithm, claims)
+		}
+		if claims.ExpiresAt-claims.IssuedAt != int64(self.DefaultJWTTTL/time.Second) {
+			t.Errorf("%s: expected the default lifetime, got iat %d exp %d", algorithm, claims.IssuedAt, claims.ExpiresAt)
+		}
+		if claims.CredentialSubject != self.NewCredentialSubject(result) {
+			t.Errorf("%s: expected the credential subject of the result, got %+v", algorithm, claims.CredentialSubject)
+		}
+	}
+
+	if _, err := result.ToJWT(self.JWTSigner{Algorithm: self.JWTAlgorithmES256, Key: []byte("secret")}); err == nil {
+		t.Error("Expected an error for a key not matching the algorithm")
+	}
+
+	// ES256 signs with P-256 only; a key on another curve would produce a signature verifiers reject
+	p384Key, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if _, err := result.ToJWT(self.JWTSigner{Algorithm: self.JWTAlgorithmES256, Key: p384Key}); err == nil {
+		t.Error("Expected an error for an ES256 key not on P-256")
+	}
+}
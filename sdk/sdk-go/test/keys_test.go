@@ -0,0 +1,295 @@
+package selfBackendVerifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+// keyedAttestationVerifier is a KeyedAttestationVerifier accepting proofs only under its current key
+type keyedAttestationVerifier struct {
+	staticAttestationVerifier
+	mu         sync.Mutex
+	currentKey string
+	keysSeen   []string
+}
+
+func (v *keyedAttestationVerifier) VerifyWithKey(ctx context.Context, key json.RawMessage, proof self.VcAndDiscloseProof, publicSignals []string, userContextData string) (*self.VerificationResult, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.keysSeen = append(v.keysSeen, string(key))
+	if string(key) != v.currentKey {
+		return nil, self.ErrKeyMismatch
+	}
+	return v.staticAttestationVerifier.Verify(ctx, proof, publicSignals, userContextData)
+}
+
+// countingKeySource is a KeySource serving the key set for keyedAttestationId, counting loads
+type countingKeySource struct {
+	mu    sync.Mutex
+	key   string
+	loads int
+}
+
+const keyedAttestationId self.AttestationId = 97
+
+func (s *countingKeySource) LoadKeys(ctx context.Context) (self.VerificationKeys, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loads++
+	return self.VerificationKeys{keyedAttestationId: json.RawMessage(s.key)}, nil
+}
+
+// newKeyedTestVerifier creates a verifier checking keyedAttestationId proofs with v and keys from source
+func newKeyedTestVerifier(t *testing.T, v self.AttestationVerifier, source self.KeySource, refreshInterval time.Duration) *self.BackendVerifier {
+	t.Helper()
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{keyedAttestationId: true},
+		createTestMockConfigStore(createTestVerificationConfig()),
+		self.UserIDTypeUUID,
+		self.WithKeySource(source, refreshInterval),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	if err := verifier.RegisterAttestationVerifier(keyedAttestationId, v); err != nil {
+		t.Fatalf("Failed to register attestation verifier: %v", err)
+	}
+	return verifier
+}
+
+func verifyKeyed(verifier *self.BackendVerifier) (*self.VerificationResult, error) {
+	return verifier.Verify(context.Background(), int(keyedAttestationId), testProof, testPublicSignals, createTestUserContextData())
+}
+
+func TestKeySource_CachesKeys(t *testing.T) {
+	source := &countingKeySource{key: `"k1"`}
+	verifier := newKeyedTestVerifier(t, &keyedAttestationVerifier{currentKey: `"k1"`}, source, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		result, err := verifyKeyed(verifier)
+		if err != nil || !result.IsValidDetails.IsValid {
+			t.Fatalf("Expected a valid result, got %+v, %v", result, err)
+		}
+	}
+	if source.loads != 1 {
+		t.Errorf("Expected the keys to be loaded once, got %d loads", source.loads)
+	}
+}
+
+func TestKeySource_RefreshInterval(t *testing.T) {
+	source := &countingKeySource{key: `"k1"`}
+	verifier := newKeyedTestVerifier(t, &keyedAttestationVerifier{currentKey: `"k1"`}, source, time.Millisecond)
+
+	if _, err := verifyKeyed(verifier); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, err := verifyKeyed(verifier); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if source.loads != 2 {
+		t.Errorf("Expected the keys to be reloaded after the refresh interval, got %d loads", source.loads)
+	}
+}
+
+func TestKeySource_RefreshesOnKeyMismatch(t *testing.T) {
+	source := &countingKeySource{key: `"k1"`}
+	keyed := &keyedAttestationVerifier{currentKey: `"k1"`}
+	verifier := newKeyedTestVerifier(t, keyed, source, 0)
+	if _, err := verifyKeyed(verifier); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The key rotates: the cached key no longer matches, so the keys are refreshed and the proof retried
+	source.key, keyed.currentKey = `"k2"`, `"k2"`
+	keyed.keysSeen = nil
+	result, err := verifyKeyed(verifier)
+	if err != nil || !result.IsValidDetails.IsValid {
+		t.Fatalf("Expected a valid result after the refresh, got %+v, %v", result, err)
+	}
+	if source.loads != 2 {
+		t.Errorf("Expected one refresh, got %d loads", source.loads)
+	}
+	if len(keyed.keysSeen) != 2 || keyed.keysSeen[0] != `"k1"` || keyed.keysSeen[1] != `"k2"` {
+		t.Errorf("Expected a retry with the refreshed key, got %v", keyed.keysSeen)
+	}
+
+	// Shortly after a refresh, a mismatching proof does not reload the keys and is not retried
+	// with the same key
+	keyed.currentKey = `"k3"`
+	keyed.keysSeen = nil
+	if _, err := verifyKeyed(verifier); !errors.Is(err, self.ErrKeyMismatch) {
+		t.Errorf("Expected ErrKeyMismatch, got %v", err)
+	}
+	if source.loads != 2 || len(keyed.keysSeen) != 1 {
+		t.Errorf("Expected no refresh and no retry, got %d loads and %d attempts", source.loads, len(keyed.keysSeen))
+	}
+}
+
+// blockingKeySource is a KeySource serving key once release is closed, counting loads
+type blockingKeySource struct {
+	key     string
+	release chan struct{}
+	loads   atomic.Int32
+}
+
+func (s *blockingKeySource) LoadKeys(ctx context.Context) (self.VerificationKeys, error) {
+	s.loads.Add(1)
+	<-s.release
+	return self.VerificationKeys{keyedAttestationId: json.RawMessage(s.key)}, nil
+}
+
+func TestKeySource_SharesConcurrentLoads(t *testing.T) {
+	source := &blockingKeySource{key: `"k1"`, release: make(chan struct{})}
+	verifier := newKeyedTestVerifier(t, &keyedAttestationVerifier{currentKey: `"k1"`}, source, time.Hour)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := verifyKeyed(verifier)
+			errs <- err
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(source.release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+	if loads := source.loads.Load(); loads != 1 {
+		t.Errorf("Expected the verifications to share one load, got %d loads", loads)
+	}
+}
+
+func TestKeySource_ServesStaleKeysDuringReload(t *testing.T) {
+	source := &blockingKeySource{key: `"k1"`, release: make(chan struct{})}
+	close(source.release)
+	verifier := newKeyedTestVerifier(t, &keyedAttestationVerifier{currentKey: `"k1"`}, source, 20*time.Millisecond)
+	if _, err := verifyKeyed(verifier); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The keys are stale and the source hangs: one verification reloads them, the others are not held up
+	time.Sleep(30 * time.Millisecond)
+	source.release = make(chan struct{})
+	defer close(source.release)
+	go verifyKeyed(verifier)
+	for source.loads.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := verifyKeyed(verifier)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected the cached keys to be used, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected verification not to wait for the hanging reload")
+	}
+}
+
+func TestKeySource_FailedReloadWaitsForInterval(t *testing.T) {
+	var loads atomic.Int32
+	source := self.KeySourceFunc(func(ctx context.Context) (self.VerificationKeys, error) {
+		if loads.Add(1) > 1 {
+			return nil, errors.New("key source unavailable")
+		}
+		return self.VerificationKeys{keyedAttestationId: json.RawMessage(`"k1"`)}, nil
+	})
+	verifier := newKeyedTestVerifier(t, &keyedAttestationVerifier{currentKey: `"k1"`}, source, 50*time.Millisecond)
+	if _, err := verifyKeyed(verifier); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The source fails once the keys are stale: the cached keys stay in use and the source is not
+	// retried until the refresh interval passes again
+	time.Sleep(60 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		if result, err := verifyKeyed(verifier); err != nil || !result.IsValidDetails.IsValid {
+			t.Fatalf("Expected the cached keys to be used, got %+v, %v", result, err)
+		}
+	}
+	if got := loads.Load(); got != 2 {
+		t.Errorf("Expected a single failed reload, got %d loads", got)
+	}
+}
+
+func TestKeySource_MissingKey(t *testing.T) {
+	source := self.KeySourceFunc(func(ctx context.Context) (self.VerificationKeys, error) {
+		return self.VerificationKeys{}, nil
+	})
+	verifier := newKeyedTestVerifier(t, &keyedAttestationVerifier{currentKey: `"k1"`}, source, 0)
+	if _, err := verifyKeyed(verifier); !errors.Is(err, self.ErrKeyNotFound) {
+		t.Errorf("Expected ErrKeyNotFound, got %v", err)
+	}
+}
+
+func TestKeySource_Loaders(t *testing.T) {
+	encoded := []byte(`{"97": {"protocol": "groth16"}}`)
+	expected := `{"protocol": "groth16"}`
+
+	path := filepath.Join(t.TempDir(), "keys.json")
+	if err := os.WriteFile(path, encoded, 0o600); err != nil {
+		t.Fatalf("Failed to write keys: %v", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(encoded)
+	}))
+	defer server.Close()
+
+	sources := map[string]self.KeySource{
+		"file":     self.FileKeySource(path),
+		"embedded": self.EmbeddedKeySource(encoded),
+		"url":      self.URLKeySource(server.URL, nil),
+	}
+	for name, source := range sources {
+		keys, err := source.LoadKeys(context.Background())
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		if string(keys[keyedAttestationId]) != expected {
+			t.Errorf("%s: expected key %s, got %s", name, expected, keys[keyedAttestationId])
+		}
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	if _, err := self.URLKeySource(failing.URL, nil).LoadKeys(context.Background()); err == nil {
+		t.Error("Expected an error for a failing URL")
+	}
+	if _, err := self.FileKeySource(filepath.Join(t.TempDir(), "missing.json")).LoadKeys(context.Background()); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+	if _, err := self.EmbeddedKeySource([]byte("not json")).LoadKeys(context.Background()); err == nil {
+		t.Error("Expected an error for invalid embedded keys")
+	}
+}
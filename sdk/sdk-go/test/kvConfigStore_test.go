@@ -0,0 +1,155 @@
+package selfBackendVerifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+	"github.com/selfxyz/self/sdk/sdk-go/storetest"
+)
+
+// fakeEtcdGateway serves the subset of the etcd v3 JSON gateway used by EtcdKVClient
+type fakeEtcdGateway struct {
+	mu       sync.Mutex
+	values   map[string][]byte
+	watchers []chan []byte
+}
+
+type fakeEtcdKeyValue struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value,omitempty"`
+}
+
+func (g *fakeEtcdGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Key           []byte `json:"key"`
+		Value         []byte `json:"value"`
+		CreateRequest *struct {
+			Key []byte `json:"key"`
+		} `json:"create_request"`
+	}
+	json.NewDecoder(r.Body).Decode(&request)
+
+	switch r.URL.Path {
+	case "/v3/kv/range":
+		g.mu.Lock()
+		value, ok := g.values[string(request.Key)]
+		g.mu.Unlock()
+		response := map[string]interface{}{}
+		if ok {
+			response["kvs"] = []fakeEtcdKeyValue{{Key: request.Key, Value: value}}
+		}
+		json.NewEncoder(w).Encode(response)
+	case "/v3/kv/put":
+		response := map[string]interface{}{}
+		if prev, ok := g.put(string(request.Key), request.Value); ok {
+			response["prev_kv"] = fakeEtcdKeyValue{Key: request.Key, Value: prev}
+		}
+		json.NewEncoder(w).Encode(response)
+	case "/v3/watch":
+		events := make(chan []byte, 16)
+		g.mu.Lock()
+		g.watchers = append(g.watchers, events)
+		g.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": map[string]bool{"created": true}})
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case event := <-events:
+				w.Write(event)
+				w.(http.Flusher).Flush()
+			}
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// put stores value and notifies watchers, as a write by another process would
+func (g *fakeEtcdGateway) put(key string, value []byte) ([]byte, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	prev, ok := g.values[key]
+	g.values[key] = value
+	event, _ := json.Marshal(map[string]interface{}{
+		"result": map[string]interface{}{
+			"events": []map[string]interface{}{{"kv": fakeEtcdKeyValue{Key: []byte(key), Value: value}}},
+		},
+	})
+	for _, watcher := range g.watchers {
+		watcher <- event
+	}
+	return prev, ok
+}
+
+func TestKVConfigStore_Etcd(t *testing.T) {
+	gateway := &fakeEtcdGateway{values: make(map[string][]byte)}
+	server := httptest.NewServer(gateway)
+	defer server.Close()
+
+	ctx := context.Background()
+	store, err := self.NewKVConfigStore(self.NewEtcdKVClient(server.URL, nil), "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	if config, err := store.GetConfig(ctx, "missing"); err != nil || config.MinimumAge != 0 {
+		t.Fatalf("Expected an empty config for a missing key, got %+v, %v", config, err)
+	}
+
+	created, err := store.SetConfig(ctx, "adults", self.VerificationConfig{MinimumAge: 18})
+	if err != nil || !created {
+		t.Fatalf("Expected the config to be created, got %v, %v", created, err)
+	}
+	if created, err := store.SetConfig(ctx, "adults", self.VerificationConfig{MinimumAge: 18}); err != nil || created {
+		t.Fatalf("Expected the config to be updated, got %v, %v", created, err)
+	}
+	gateway.mu.Lock()
+	stored := string(gateway.values[self.DefaultKVConfigPrefix+"adults"])
+	gateway.mu.Unlock()
+	if !strings.Contains(stored, `"minimumAge":18`) {
+		t.Errorf("Expected the config to be stored as JSON, got %s", stored)
+	}
+
+	// An update made outside the store reaches the cache through the watch
+	gateway.put(self.DefaultKVConfigPrefix+"adults", []byte(`{"minimumAge":21}`))
+	deadline := time.Now().Add(time.Second)
+	for {
+		config, err := store.GetConfig(ctx, "adults")
+		if err != nil {
+			t.Fatalf("Failed to get config: %v", err)
+		}
+		if config.MinimumAge == 21 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the watched update to apply, got minimum age %d", config.MinimumAge)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestKVConfigStore_Concurrency(t *testing.T) {
+	storetest.TestConfigStore(t, func(t *testing.T) self.ConfigStore {
+		// Each store gets its own gateway, whose watchers are not drained once the store is closed
+		server := httptest.NewServer(&fakeEtcdGateway{values: make(map[string][]byte)})
+		store, err := self.NewKVConfigStore(self.NewEtcdKVClient(server.URL, nil), "", nil)
+		if err != nil {
+			t.Fatalf("Failed to create store: %v", err)
+		}
+		t.Cleanup(func() {
+			store.Close()
+			server.Close()
+		})
+		return store
+	})
+}
@@ -0,0 +1,95 @@
+package selfBackendVerifier
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+	"github.com/selfxyz/self/sdk/sdk-go/selflambda"
+)
+
+func decodeLambdaErrorResponse(t *testing.T, response selflambda.APIGatewayProxyResponse) self.ErrorResponse {
+	var body self.ErrorResponse
+	if err := json.Unmarshal([]byte(response.Body), &body); err != nil {
+		t.Fatalf("Failed to decode error response %q: %v", response.Body, err)
+	}
+	return body
+}
+
+func TestLambdaHandler_MapsRequests(t *testing.T) {
+	handler := selflambda.NewHandler(newTestVerifyHandler(t, map[self.AttestationId]bool{self.EUCard: true}))
+	ctx := context.Background()
+
+	response, err := handler.Invoke(ctx, selflambda.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Path: "/api/verify"})
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if response.StatusCode != http.StatusMethodNotAllowed || response.Headers["Allow"] != http.MethodPost {
+		t.Errorf("Expected a 405 allowing POST, got %d %v", response.StatusCode, response.Headers)
+	}
+
+	// The request is decoded and verified as by VerifyHandler, here from a base64 body
+	input, _ := json.Marshal(self.VerifyInput{
+		AttestationId:   int(self.Passport),
+		Proof:           testProof,
+		PublicSignals:   testPublicSignals,
+		UserContextData: createTestUserContextData(),
+	})
+	response, err = handler.Invoke(ctx, selflambda.APIGatewayProxyRequest{
+		HTTPMethod:      http.MethodPost,
+		Path:            "/api/verify",
+		Headers:         map[string]string{"Content-Type": "application/json"},
+		Body:            base64.StdEncoding.EncodeToString(input),
+		IsBase64Encoded: true,
+	})
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if response.StatusCode != self.HTTPStatusFor(self.ErrAttestationNotAllowed) || response.IsBase64Encoded {
+		t.Errorf("Expected a JSON error with the handler's status, got %d, base64 %v", response.StatusCode, response.IsBase64Encoded)
+	}
+	if body := decodeLambdaErrorResponse(t, response); body.Code != self.ErrorCodeAttestationNotAllowed {
+		t.Errorf("Expected code %s, got %s", self.ErrorCodeAttestationNotAllowed, body.Code)
+	}
+
+	response, err = handler.Invoke(ctx, selflambda.APIGatewayProxyRequest{
+		HTTPMethod:      http.MethodPost,
+		Body:            "not base64!",
+		IsBase64Encoded: true,
+	})
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if body := decodeLambdaErrorResponse(t, response); response.StatusCode != http.StatusBadRequest || body.Code != self.ErrorCodeInvalidRequest {
+		t.Errorf("Expected a 400 %s for a malformed event, got %d %s", self.ErrorCodeInvalidRequest, response.StatusCode, body.Code)
+	}
+}
+
+func TestLambdaHandler_Warmup(t *testing.T) {
+	handler := selflambda.NewHandler(newTestVerifyHandler(t, map[self.AttestationId]bool{self.Passport: true}))
+	calls := 0
+	handler.Warmup = func(ctx context.Context) error {
+		calls++
+		if calls == 1 {
+			return self.ErrConfigNotFound
+		}
+		return nil
+	}
+
+	event := selflambda.APIGatewayProxyRequest{HTTPMethod: http.MethodGet}
+	if _, err := handler.Invoke(context.Background(), event); !errors.Is(err, self.ErrConfigNotFound) {
+		t.Fatalf("Expected the failed warmup to fail the invocation, got %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := handler.Invoke(context.Background(), event); err != nil {
+			t.Fatalf("Invoke failed: %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Expected warmup to be retried once and then skipped, got %d calls", calls)
+	}
+}
@@ -0,0 +1,202 @@
+package selfBackendVerifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+// holderAttestationVerifier is an AttestationVerifier accepting every proof as one of holder's documents
+type holderAttestationVerifier struct {
+	output         self.GenericDiscloseOutput
+	userIdentifier string
+}
+
+func (v holderAttestationVerifier) Verify(ctx context.Context, proof self.VcAndDiscloseProof, publicSignals []string, userContextData string) (*self.VerificationResult, error) {
+	return &self.VerificationResult{
+		IsValidDetails: self.IsValidDetails{IsValid: true, IsMinimumAgeValid: true, IsOfacValid: true},
+		DiscloseOutput: v.output,
+		UserData:       self.UserData{UserIdentifier: v.userIdentifier},
+	}, nil
+}
+
+func TestVerifySamePerson(t *testing.T) {
+	passport := holderAttestationVerifier{
+		output:         self.GenericDiscloseOutput{Name: "DUPONT  ALPHONSE HUGHUES", DateOfBirth: "310101", Nullifier: "1"},
+		userIdentifier: "user-1",
+	}
+	verifySamePerson := func(second holderAttestationVerifier, ids ...int) (*self.LinkedResult, error) {
+		verifier, err := self.NewBackendVerifier(
+			"self-playground",
+			"https://playground.self.xyz/api/verify",
+			false,
+			map[self.AttestationId]bool{98: true, 99: true},
+			createTestMockConfigStore(createTestVerificationConfig()),
+			self.UserIDTypeUUID,
+		)
+		if err != nil {
+			t.Fatalf("Failed to create verifier: %v", err)
+		}
+		if err := verifier.RegisterAttestationVerifier(98, passport); err != nil {
+			t.Fatalf("Failed to register attestation verifier: %v", err)
+		}
+		if err := verifier.RegisterAttestationVerifier(99, second); err != nil {
+			t.Fatalf("Failed to register attestation verifier: %v", err)
+		}
+		input := func(id int) self.VerifyInput {
+			return self.VerifyInput{AttestationId: id, Proof: testProof, PublicSignals: testPublicSignals, UserContextData: createTestUserContextData()}
+		}
+		if len(ids) == 0 {
+			ids = []int{98, 99}
+		}
+		inputs := make([]self.VerifyInput, 0, len(ids))
+		for _, id := range ids {
+			inputs = append(inputs, input(id))
+		}
+		return verifier.VerifySamePerson(context.Background(), inputs)
+	}
+
+	// Another document of the holder has its own nullifier and may format the same values differently
+	linked, err := verifySamePerson(holderAttestationVerifier{
+		output:         self.GenericDiscloseOutput{Name: "Dupont Alphonse Hughues", DateOfBirth: "19310101", Nullifier: "2"},
+		userIdentifier: "user-1",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !linked.IsValid || len(linked.Results) != 2 || linked.Results[1].AttestationId != 99 {
+		t.Errorf("Expected two valid results in input order, got %+v", linked)
+	}
+
+	mismatches := map[string]holderAttestationVerifier{
+		"another name": {
+			output:         self.GenericDiscloseOutput{Name: "MARTIN CLAIRE", DateOfBirth: "310101"},
+			userIdentifier: "user-1",
+		},
+		"another session": {
+			output:         passport.output,
+			userIdentifier: "user-2",
+		},
+		"an undisclosed date of birth": {
+			output:         self.GenericDiscloseOutput{Name: passport.output.Name},
+			userIdentifier: "user-1",
+		},
+		"the same nullifier": {
+			output:         passport.output,
+			userIdentifier: "user-1",
+		},
+	}
+	for name, second := range mismatches {
+		_, err := verifySamePerson(second)
+		if !errors.Is(err, self.ErrIdentityMismatch) || self.ErrorCodeFor(err) != self.ErrorCodeIdentityMismatch {
+			t.Errorf("Expected ErrIdentityMismatch for %s, got %v", name, err)
+		}
+	}
+
+	// The same request twice is a single proof
+	if _, err := verifySamePerson(passport, 98, 99, 98); !errors.Is(err, self.ErrIdentityMismatch) {
+		t.Errorf("Expected ErrIdentityMismatch for a repeated proof, got %v", err)
+	}
+
+	verifier, _ := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{self.Passport: true},
+		createTestMockConfigStore(createTestVerificationConfig()),
+		self.UserIDTypeUUID,
+	)
+	if _, err := verifier.VerifySamePerson(context.Background(), []self.VerifyInput{{}}); !errors.Is(err, self.ErrInvalidRequest) {
+		t.Errorf("Expected ErrInvalidRequest for a single proof, got %v", err)
+	}
+}
+
+func TestVerifyLinked(t *testing.T) {
+	now := time.Now().UTC()
+	// bornYearsAgo returns the MRZ date of birth of someone turning years old days from today
+	bornYearsAgo := func(years, days int) string {
+		return now.AddDate(-years, 0, days).Format("060102")
+	}
+	child := holderAttestationVerifier{
+		output:         self.GenericDiscloseOutput{DateOfBirth: bornYearsAgo(10, 0), Nullifier: "1"},
+		userIdentifier: "user-1",
+	}
+	parent := holderAttestationVerifier{
+		output:         self.GenericDiscloseOutput{DateOfBirth: bornYearsAgo(40, 0), Nullifier: "2"},
+		userIdentifier: "user-1",
+	}
+	verifyLinked := func(subject, guardian holderAttestationVerifier, rules self.LinkedRules) (*self.LinkedResult, error) {
+		verifier, err := self.NewBackendVerifier(
+			"self-playground",
+			"https://playground.self.xyz/api/verify",
+			false,
+			map[self.AttestationId]bool{98: true, 99: true},
+			createTestMockConfigStore(createTestVerificationConfig()),
+			self.UserIDTypeUUID,
+		)
+		if err != nil {
+			t.Fatalf("Failed to create verifier: %v", err)
+		}
+		if err := verifier.RegisterAttestationVerifier(98, subject); err != nil {
+			t.Fatalf("Failed to register attestation verifier: %v", err)
+		}
+		if err := verifier.RegisterAttestationVerifier(99, guardian); err != nil {
+			t.Fatalf("Failed to register attestation verifier: %v", err)
+		}
+		input := func(id int) self.VerifyInput {
+			return self.VerifyInput{AttestationId: id, Proof: testProof, PublicSignals: testPublicSignals, UserContextData: createTestUserContextData()}
+		}
+		return verifier.VerifyLinked(context.Background(), input(98), input(99), rules)
+	}
+
+	linked, err := verifyLinked(child, parent, self.DefaultGuardianRules())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !linked.IsValid || len(linked.Results) != 2 || linked.Results[0].AttestationId != 98 || linked.Results[1].AttestationId != 99 {
+		t.Errorf("Expected two valid results in the order subject, guardian, got %+v", linked)
+	}
+
+	withOutput := func(v holderAttestationVerifier, output self.GenericDiscloseOutput) holderAttestationVerifier {
+		v.output = output
+		return v
+	}
+	otherSession := parent
+	otherSession.userIdentifier = "user-2"
+	provenAdult := withOutput(parent, self.GenericDiscloseOutput{MinimumAge: "18", Nullifier: "2"})
+
+	tests := []struct {
+		name     string
+		subject  holderAttestationVerifier
+		guardian holderAttestationVerifier
+		rules    self.LinkedRules
+		valid    bool
+	}{
+		{"the same person", child, withOutput(parent, self.GenericDiscloseOutput{DateOfBirth: bornYearsAgo(40, 0), Nullifier: "1"}), self.DefaultGuardianRules(), false},
+		{"different user identifiers", child, otherSession, self.DefaultGuardianRules(), false},
+		{"different user identifiers when allowed", child, otherSession, self.LinkedRules{SubjectMaximumAge: 18, GuardianMinimumAge: 18, AllowDifferentUserIdentifiers: true}, true},
+		{"a subject turning 18 tomorrow", withOutput(child, self.GenericDiscloseOutput{DateOfBirth: bornYearsAgo(18, 1), Nullifier: "1"}), parent, self.DefaultGuardianRules(), true},
+		{"a subject turning 18 today", withOutput(child, self.GenericDiscloseOutput{DateOfBirth: bornYearsAgo(18, 0), Nullifier: "1"}), parent, self.DefaultGuardianRules(), false},
+		{"a subject without a date of birth", withOutput(child, self.GenericDiscloseOutput{Nullifier: "1"}), parent, self.DefaultGuardianRules(), false},
+		{"no subject maximum age", withOutput(child, self.GenericDiscloseOutput{Nullifier: "1"}), parent, self.LinkedRules{GuardianMinimumAge: 18}, true},
+		{"a guardian turning 18 today", child, withOutput(parent, self.GenericDiscloseOutput{DateOfBirth: bornYearsAgo(18, 0), Nullifier: "2"}), self.DefaultGuardianRules(), true},
+		{"a guardian turning 18 tomorrow", child, withOutput(parent, self.GenericDiscloseOutput{DateOfBirth: bornYearsAgo(18, 1), Nullifier: "2"}), self.DefaultGuardianRules(), false},
+		{"a guardian proving the minimum age", child, provenAdult, self.DefaultGuardianRules(), true},
+		{"a guardian proving a lower minimum age", child, provenAdult, self.LinkedRules{SubjectMaximumAge: 18, GuardianMinimumAge: 21}, false},
+	}
+	for _, tt := range tests {
+		linked, err := verifyLinked(tt.subject, tt.guardian, tt.rules)
+		if tt.valid {
+			if err != nil || !linked.IsValid {
+				t.Errorf("%s: expected a valid linked result, got %+v, %v", tt.name, linked, err)
+			}
+			continue
+		}
+		if !errors.Is(err, self.ErrLinkedRuleViolation) || self.ErrorCodeFor(err) != self.ErrorCodeLinkedRuleViolation {
+			t.Errorf("%s: expected ErrLinkedRuleViolation, got %v", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package selfBackendVerifier
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func TestDefaultMessages_CoverEveryErrorCode(t *testing.T) {
+	messages := self.DefaultMessages()
+	for _, code := range self.AllErrorCodes {
+		if messages[self.DefaultLocale][code] == "" {
+			t.Errorf("Expected an English message for %s", code)
+		}
+	}
+}
+
+func TestMessageCatalog_Fallback(t *testing.T) {
+	messages := self.DefaultMessages()
+	messages["pt"] = map[self.ErrorCode]string{self.ErrorCodeAgeNotMet: "Você não atinge a idade mínima."}
+
+	if message := messages.Message(self.ErrorCodeAgeNotMet, "pt-BR"); message != "Você não atinge a idade mínima." {
+		t.Errorf("Expected the base language message, got %q", message)
+	}
+	if message := messages.Message(self.ErrorCodeOfacHit, "pt-BR"); message != messages[self.DefaultLocale][self.ErrorCodeOfacHit] {
+		t.Errorf("Expected the English message for an untranslated code, got %q", message)
+	}
+	if message := (self.MessageCatalog{}).Message(self.ErrorCodeAgeNotMet, "fr"); message != string(self.ErrorCodeAgeNotMet) {
+		t.Errorf("Expected the code when no message exists, got %q", message)
+	}
+}
+
+func TestVerifyHandler_LocalizesMessages(t *testing.T) {
+	handler := newTestVerifyHandler(t, map[self.AttestationId]bool{self.Passport: true})
+	handler.Messages = self.MessageCatalog{"de": {self.ErrorCodeInvalidRequest: "Ungültige Anfrage."}}
+
+	request := httptest.NewRequest(http.MethodPost, "/api/verify", strings.NewReader(`{}`))
+	request.Header.Set("Accept-Language", "de-DE,de;q=0.9,en;q=0.8")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	var response self.ErrorResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Code != self.ErrorCodeInvalidRequest || response.Message != "Ungültige Anfrage." || len(response.Errors) == 0 {
+		t.Errorf("Expected a localized message with field errors, got %+v", response)
+	}
+}
@@ -0,0 +1,170 @@
+package selfBackendVerifier
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func TestLoggingMiddleware(t *testing.T) {
+	var logs bytes.Buffer
+	handler := self.Chain(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		}),
+		self.LoggingMiddleware(log.New(&logs, "", 0)),
+		self.CORSMiddleware(self.DefaultCORSConfig()),
+	)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/verify?userId=secret", strings.NewReader(`{"proof":"secret"}`))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	requestId := recorder.Header().Get(self.RequestIDHeader)
+	if requestId == "" {
+		t.Fatal("Expected a request id header")
+	}
+	line := logs.String()
+	for _, expected := range []string{requestId, "POST", "/api/verify", "418"} {
+		if !strings.Contains(line, expected) {
+			t.Errorf("Expected the log line to contain %q, got %q", expected, line)
+		}
+	}
+	if strings.Contains(line, "secret") {
+		t.Errorf("Expected the body and query string not to be logged, got %q", line)
+	}
+}
+
+func TestSecurityHeadersMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	serve := func(config self.SecurityHeadersConfig) http.Header {
+		recorder := httptest.NewRecorder()
+		self.SecurityHeadersMiddleware(config)(next).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		if recorder.Code != http.StatusNoContent {
+			t.Errorf("Expected the wrapped handler's status, got %d", recorder.Code)
+		}
+		return recorder.Header()
+	}
+
+	header := serve(self.DefaultSecurityHeadersConfig())
+	expected := map[string]string{
+		"X-Content-Type-Options":    "nosniff",
+		"Referrer-Policy":           "no-referrer",
+		"X-Frame-Options":           "DENY",
+		"Strict-Transport-Security": "",
+	}
+	for name, value := range expected {
+		if got := header.Get(name); got != value {
+			t.Errorf("Expected %s %q by default, got %q", name, value, got)
+		}
+	}
+
+	// HSTS is opt-in
+	config := self.DefaultSecurityHeadersConfig()
+	config.HSTSMaxAge = 24 * time.Hour
+	if got := serve(config).Get("Strict-Transport-Security"); got != "max-age=86400" {
+		t.Errorf("Expected HSTS once enabled, got %q", got)
+	}
+	config.HSTSIncludeSubdomains = true
+	if got := serve(config).Get("Strict-Transport-Security"); got != "max-age=86400; includeSubDomains" {
+		t.Errorf("Expected HSTS to include subdomains, got %q", got)
+	}
+
+	config.Disabled = true
+	if header := serve(config); len(header) != 0 {
+		t.Errorf("Expected no headers when disabled, got %v", header)
+	}
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	config := self.CORSConfig{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{http.MethodPost, http.MethodOptions},
+		AllowedHeaders: []string{"Content-Type"},
+		MaxAge:         time.Hour,
+	}
+	var reached bool
+	handler := self.CORSMiddleware(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+	}))
+	serve := func(method, origin string, preflight bool) *httptest.ResponseRecorder {
+		reached = false
+		request := httptest.NewRequest(method, "/api/verify", nil)
+		if origin != "" {
+			request.Header.Set("Origin", origin)
+		}
+		if preflight {
+			request.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := serve(http.MethodPost, "https://APP.example.com", false)
+	if got := recorder.Header().Get("Access-Control-Allow-Origin"); got != "https://APP.example.com" || !reached {
+		t.Errorf("Expected an allowed origin to be echoed and passed through, got %q", got)
+	}
+	if got := recorder.Header().Get("Vary"); got != "Origin" {
+		t.Errorf("Expected Vary: Origin for a specific origin, got %q", got)
+	}
+
+	recorder = serve(http.MethodOptions, "https://app.example.com", true)
+	if recorder.Code != http.StatusNoContent || reached {
+		t.Errorf("Expected the preflight to be answered, got %d, passed through %v", recorder.Code, reached)
+	}
+	preflight := map[string]string{
+		"Access-Control-Allow-Methods": "POST, OPTIONS",
+		"Access-Control-Allow-Headers": "Content-Type",
+		"Access-Control-Max-Age":       "3600",
+	}
+	for name, value := range preflight {
+		if got := recorder.Header().Get(name); got != value {
+			t.Errorf("Expected %s %q, got %q", name, value, got)
+		}
+	}
+
+	for _, origin := range []string{"https://evil.example.com", ""} {
+		recorder = serve(http.MethodPost, origin, false)
+		if got := recorder.Header().Get("Access-Control-Allow-Origin"); got != "" || !reached {
+			t.Errorf("Expected origin %q to pass through without CORS headers, got %q", origin, got)
+		}
+	}
+
+	// The default config allows any origin without varying on it
+	recorder = httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/api/verify", nil)
+	request.Header.Set("Origin", "https://any.example.com")
+	self.CORSMiddleware(self.DefaultCORSConfig())(http.NotFoundHandler()).ServeHTTP(recorder, request)
+	if recorder.Header().Get("Access-Control-Allow-Origin") != "*" || recorder.Header().Get("Vary") != "" {
+		t.Errorf("Expected a wildcard origin, got %v", recorder.Header())
+	}
+}
+
+func TestChain(t *testing.T) {
+	var order []string
+	tag := func(name string) self.Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	handler := self.Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "handler")
+	}), tag("outer"), tag("inner"))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if strings.Join(order, ",") != "outer,inner,handler" {
+		t.Errorf("Expected the first middleware to be outermost, got %v", order)
+	}
+}
@@ -0,0 +1,24 @@
+package selfBackendVerifier
+
+import (
+	"errors"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func TestVerify_MinimumAgeMargin(t *testing.T) {
+	// The test proof discloses a 1998-03-27 date of birth and was generated on 2025-08-12
+	config := createTestVerificationConfig()
+	config.MinimumAgeMarginDays = 1
+	result, err := verifyOffline(t, config, testPublicSignals)
+	if err != nil || !result.IsValidDetails.IsMinimumAgeValid {
+		t.Errorf("Expected a holder well past the margin to pass, got %+v, %v", result, err)
+	}
+
+	config.MinimumAgeMarginDays = 3650
+	_, err = verifyOffline(t, config, testPublicSignals)
+	if !errors.Is(err, self.ErrMinimumAgeMarginNotMet) || !errors.Is(err, self.ErrMinimumAgeNotMet) {
+		t.Errorf("Expected ErrMinimumAgeMarginNotMet, got %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package selfBackendVerifier
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func TestValidateMRZ(t *testing.T) {
+	// Specimen passport from ICAO 9303 part 4
+	output := self.GenericDiscloseOutput{
+		IdNumber:    "L898902C3",
+		DateOfBirth: "740812",
+		ExpiryDate:  "120415",
+		MRZ: &self.MRZComponents{
+			DocumentNumberCheckDigit: "6",
+			DateOfBirthCheckDigit:    "2",
+			ExpiryDateCheckDigit:     "9",
+			OptionalData:             "ZE184226B<<<<<",
+			CompositeCheckDigit:      "0",
+			CompositeData:            "L898902C36" + "7408122" + "1204159ZE184226B<<<<<1",
+		},
+	}
+	if err := output.ValidateMRZ(); err != nil {
+		t.Errorf("Expected specimen MRZ to be consistent, got %v", err)
+	}
+
+	output.DateOfBirth = "740813"
+	if err := output.ValidateMRZ(); !errors.Is(err, self.ErrMRZChecksumMismatch) {
+		t.Errorf("Expected ErrMRZChecksumMismatch for an altered date of birth, got %v", err)
+	}
+
+	// Undisclosed fields are skipped
+	output.DateOfBirth = "\x00\x00\x00\x00\x00\x00"
+	output.MRZ.CompositeData = "\x00" + output.MRZ.CompositeData[1:]
+	if err := output.ValidateMRZ(); err != nil {
+		t.Errorf("Expected undisclosed fields to be skipped, got %v", err)
+	}
+}
+
+func TestValidateMRZ_TestProof(t *testing.T) {
+	result := createTestVerificationResult(t)
+	if result.DiscloseOutput.MRZ == nil {
+		t.Fatal("Expected MRZ components for a passport proof")
+	}
+	if err := result.DiscloseOutput.ValidateMRZ(); err != nil {
+		t.Errorf("Expected test proof MRZ to be consistent, got %v", err)
+	}
+}
+
+func TestReconstructMRZLines(t *testing.T) {
+	lines, err := self.ReconstructMRZLines(self.Passport, testPublicSignals)
+	if err != nil {
+		t.Fatalf("Failed to reconstruct MRZ lines: %v", err)
+	}
+	if len(lines) != 2 || len(lines[0]) != 44 || len(lines[1]) != 44 {
+		t.Fatalf("Expected two 44 character lines, got %q", lines)
+	}
+	// The test proof only discloses the date of birth, at positions 14-19 of the second line
+	if lines[0] != strings.Repeat("?", 44) || lines[1][13:19] != "980327" {
+		t.Errorf("Unexpected MRZ lines %q", lines)
+	}
+
+	if _, err := self.ReconstructMRZLines(self.Aadhaar, testPublicSignals); err == nil {
+		t.Error("Expected an error for a document without an MRZ")
+	}
+}
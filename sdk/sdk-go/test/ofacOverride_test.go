@@ -0,0 +1,96 @@
+package selfBackendVerifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func TestInMemoryOFACOverrideStore(t *testing.T) {
+	ctx := context.Background()
+	store := self.NewInMemoryOFACOverrideStore(self.OFACOverride{Nullifier: "123", ClearedBy: "compliance", Reason: "name collision"})
+
+	override, err := store.LookupOverride(ctx, "123")
+	if err != nil || override == nil || override.ClearedBy != "compliance" {
+		t.Fatalf("Expected the cleared nullifier to be found, got %+v (err=%v)", override, err)
+	}
+	if override, _ := store.LookupOverride(ctx, "456"); override != nil {
+		t.Errorf("Expected no override for an uncleared nullifier, got %+v", override)
+	}
+
+	store.Revoke("123")
+	if override, _ := store.LookupOverride(ctx, "123"); override != nil {
+		t.Errorf("Expected a revoked override not to be found, got %+v", override)
+	}
+}
+
+// countingOverrideStore is an OFACOverrideStore delegating to store, or failing with err, counting lookups
+type countingOverrideStore struct {
+	store   self.OFACOverrideStore
+	err     error
+	lookups int
+}
+
+func (s *countingOverrideStore) LookupOverride(ctx context.Context, nullifier string) (*self.OFACOverride, error) {
+	s.lookups++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.store.LookupOverride(ctx, nullifier)
+}
+
+func TestVerify_OFACOverride(t *testing.T) {
+	nullifier := testPublicSignals[self.DiscloseIndices[self.Passport].NullifierIndex]
+	config := createTestVerificationConfig()
+	config.Ofac = true
+	verify := func(store *countingOverrideStore, signals []string) (*self.VerificationResult, self.VerificationAudit) {
+		t.Helper()
+		sink := self.NewInMemoryAuditSink()
+		verifier := newOfflineTestVerifier(t, config, self.WithOFACOverrideStore(store), self.WithAuditSink(sink))
+		result, err := verifier.Verify(context.Background(), 1, testProof, signals, createTestUserContextData())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		records := sink.Records()
+		if len(records) != 1 {
+			t.Fatalf("Expected 1 audit record, got %d", len(records))
+		}
+		return result, records[0]
+	}
+
+	// A holder cleared by compliance passes the OFAC hit, flagged on the result and audit record
+	cleared := &countingOverrideStore{store: self.NewInMemoryOFACOverrideStore(self.OFACOverride{Nullifier: nullifier, ClearedBy: "compliance"})}
+	result, audit := verify(cleared, ofacHitSignals())
+	if !result.IsValidDetails.IsOfacValid || result.OFACSource != self.OFACSourceOverride {
+		t.Errorf("Expected the override to clear the OFAC hit, got %v from %q", result.IsValidDetails.IsOfacValid, result.OFACSource)
+	}
+	if result.OFACOverride == nil || result.OFACOverride.Nullifier != nullifier || result.OFACOverride.ClearedBy != "compliance" {
+		t.Errorf("Expected the override on the result, got %+v", result.OFACOverride)
+	}
+	if !audit.OFACOverride {
+		t.Errorf("Expected the audit record to be flagged")
+	}
+
+	// The store is only consulted for OFAC hits
+	if result, _ := verify(cleared, testPublicSignals); result.OFACSource != self.OFACSourceProof || cleared.lookups != 1 {
+		t.Errorf("Expected no lookup for a user passing OFAC, got source %q after %d lookups", result.OFACSource, cleared.lookups)
+	}
+
+	// Holders without an override, and lookup failures, keep the OFAC hit
+	stores := map[string]*countingOverrideStore{
+		"no override":    {store: self.NewInMemoryOFACOverrideStore()},
+		"lookup failure": {err: errors.New("override store unavailable")},
+	}
+	for name, store := range stores {
+		result, audit := verify(store, ofacHitSignals())
+		if result.IsValidDetails.IsOfacValid || result.OFACSource != self.OFACSourceNone || result.OFACOverride != nil || audit.OFACOverride {
+			t.Errorf("%s: expected the OFAC hit to be kept, got %v from %q, override %+v", name,
+				result.IsValidDetails.IsOfacValid, result.OFACSource, result.OFACOverride)
+		}
+		if store.lookups != 1 {
+			t.Errorf("%s: expected 1 lookup, got %d", name, store.lookups)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package selfBackendVerifier
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+// stubOFACScreener is an OFACScreener returning a fixed outcome, counting its calls
+type stubOFACScreener struct {
+	clear bool
+	err   error
+	calls int
+}
+
+func (s *stubOFACScreener) Screen(ctx context.Context, discloseOutput self.GenericDiscloseOutput) (bool, error) {
+	s.calls++
+	return s.clear, s.err
+}
+
+// ofacHitSignals returns the passport test signals with the in-proof OFAC checks failed
+func ofacHitSignals() []string {
+	indices := self.RevealedDataIndices[self.Passport]
+	return withRevealedData(indices.OfacStart, strings.Repeat("\x01", indices.OfacEnd-indices.OfacStart+1))
+}
+
+func TestVerify_OfflineTestVerifier(t *testing.T) {
+	result, err := newOfflineTestVerifier(t, createTestVerificationConfig()).Verify(
+		context.Background(), 1, testProof, testPublicSignals, createTestUserContextData())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.IsValidDetails.IsValid || result.CircuitVersion != self.CircuitVersionV2 {
+		t.Errorf("Expected a valid V2 result, got %+v", result)
+	}
+}
+
+func TestVerify_OFACModes(t *testing.T) {
+	screenerErr := errors.New("sanctions list unavailable")
+	tests := []struct {
+		name           string
+		ofac           bool
+		mode           self.OFACMode
+		proofHit       bool
+		screener       *stubOFACScreener
+		expectedValid  bool
+		expectedSource self.OFACSource
+		expectedErr    string
+	}{
+		{name: "disabled", ofac: false, screener: &stubOFACScreener{}, expectedSource: self.OFACSourceNone},
+		{name: "proof clear", ofac: true, mode: self.OFACModeProof, screener: &stubOFACScreener{}, expectedValid: true, expectedSource: self.OFACSourceProof},
+		{name: "proof by default", ofac: true, screener: &stubOFACScreener{}, expectedValid: true, expectedSource: self.OFACSourceProof},
+		{name: "proof hit", ofac: true, mode: self.OFACModeProof, proofHit: true, screener: &stubOFACScreener{clear: true}, expectedSource: self.OFACSourceNone},
+		{name: "backend clear", ofac: true, mode: self.OFACModeBackend, proofHit: true, screener: &stubOFACScreener{clear: true}, expectedValid: true, expectedSource: self.OFACSourceBackend},
+		{name: "backend hit", ofac: true, mode: self.OFACModeBackend, screener: &stubOFACScreener{}, expectedSource: self.OFACSourceNone},
+		{name: "both clear", ofac: true, mode: self.OFACModeBoth, screener: &stubOFACScreener{clear: true}, expectedValid: true, expectedSource: self.OFACSourceBoth},
+		{name: "both with a proof hit", ofac: true, mode: self.OFACModeBoth, proofHit: true, screener: &stubOFACScreener{clear: true}, expectedSource: self.OFACSourceNone},
+		{name: "both with a backend hit", ofac: true, mode: self.OFACModeBoth, screener: &stubOFACScreener{}, expectedSource: self.OFACSourceNone},
+		{name: "missing screener", ofac: true, mode: self.OFACModeBackend, expectedErr: "requires an OFACScreener"},
+		{name: "failing screener", ofac: true, mode: self.OFACModeBoth, screener: &stubOFACScreener{err: screenerErr}, expectedErr: "OFAC screening failed"},
+	}
+	for _, tt := range tests {
+		config := createTestVerificationConfig()
+		config.Ofac = tt.ofac
+		config.OfacMode = tt.mode
+		var opts []self.VerifierOption
+		if tt.screener != nil {
+			opts = append(opts, self.WithOFACScreener(tt.screener))
+		}
+		signals := testPublicSignals
+		if tt.proofHit {
+			signals = ofacHitSignals()
+		}
+
+		result, err := newOfflineTestVerifier(t, config, opts...).Verify(context.Background(), 1, testProof, signals, createTestUserContextData())
+		if tt.expectedErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.expectedErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if result.IsValidDetails.IsOfacValid != tt.expectedValid || result.OFACSource != tt.expectedSource {
+			t.Errorf("%s: expected OFAC valid %v from %q, got %v from %q",
+				tt.name, tt.expectedValid, tt.expectedSource, result.IsValidDetails.IsOfacValid, result.OFACSource)
+		}
+		screened := tt.mode == self.OFACModeBackend || tt.mode == self.OFACModeBoth
+		if screened != (tt.screener.calls == 1) {
+			t.Errorf("%s: expected the screener to be called %v, got %d calls", tt.name, screened, tt.screener.calls)
+		}
+	}
+}
+
+func TestVerify_TrustProofOfac(t *testing.T) {
+	config := createTestVerificationConfig()
+	config.Ofac = true
+	config.OfacMode = self.OFACModeBoth
+	config.TrustProofOfac = true
+	screener := &stubOFACScreener{clear: true}
+	verifier := newOfflineTestVerifier(t, config, self.WithOFACScreener(screener))
+
+	// Users cleared by the proof are not screened again
+	result, err := verifier.Verify(context.Background(), 1, testProof, testPublicSignals, createTestUserContextData())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.IsValidDetails.IsOfacValid || result.OFACSource != self.OFACSourceProof || screener.calls != 0 {
+		t.Errorf("Expected the proof to clear the user without screening, got %v from %q after %d screens",
+			result.IsValidDetails.IsOfacValid, result.OFACSource, screener.calls)
+	}
+
+	// Users the proof does not clear are still screened, and must pass both
+	result, err = verifier.Verify(context.Background(), 1, testProof, ofacHitSignals(), createTestUserContextData())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.IsValidDetails.IsOfacValid || result.OFACSource != self.OFACSourceNone || screener.calls != 1 {
+		t.Errorf("Expected the flagged user to be screened and fail, got %v from %q after %d screens",
+			result.IsValidDetails.IsOfacValid, result.OFACSource, screener.calls)
+	}
+
+	// In backend mode, the screen alone clears users the proof does not
+	config.OfacMode = self.OFACModeBackend
+	screener = &stubOFACScreener{clear: true}
+	result, err = newOfflineTestVerifier(t, config, self.WithOFACScreener(screener)).Verify(
+		context.Background(), 1, testProof, ofacHitSignals(), createTestUserContextData())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.IsValidDetails.IsOfacValid || result.OFACSource != self.OFACSourceBackend || screener.calls != 1 {
+		t.Errorf("Expected the screen to clear the user, got %v from %q after %d screens",
+			result.IsValidDetails.IsOfacValid, result.OFACSource, screener.calls)
+	}
+}
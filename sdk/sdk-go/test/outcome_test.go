@@ -0,0 +1,253 @@
+package selfBackendVerifier
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+// collectingOutcomeRecorder keeps every outcome it receives, optionally blocking until release is closed
+type collectingOutcomeRecorder struct {
+	mu       sync.Mutex
+	outcomes []self.VerificationOutcome
+	release  chan struct{}
+}
+
+func (r *collectingOutcomeRecorder) RecordOutcome(ctx context.Context, outcome self.VerificationOutcome) error {
+	if r.release != nil {
+		<-r.release
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.outcomes = append(r.outcomes, outcome)
+	return nil
+}
+
+func (r *collectingOutcomeRecorder) recorded() []self.VerificationOutcome {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]self.VerificationOutcome(nil), r.outcomes...)
+}
+
+func TestVerify_RecordsOutcome(t *testing.T) {
+	const pilotId self.AttestationId = 99
+	recorder := &collectingOutcomeRecorder{}
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{self.Passport: true, pilotId: true},
+		createTestMockConfigStore(createTestVerificationConfig()),
+		self.UserIDTypeUUID,
+		self.WithOutcomeRecorder(recorder),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	verifier.RegisterAttestationVerifier(pilotId, staticAttestationVerifier{})
+
+	if _, err := verifier.Verify(context.Background(), int(pilotId), testProof, testPublicSignals, createTestUserContextData()); err != nil {
+		t.Fatalf("Expected a valid proof, got %v", err)
+	}
+	_, verifyErr := verifier.Verify(context.Background(), int(self.Passport), testProof, testPublicSignals, createTestUserContextData())
+
+	outcomes := recorder.recorded()
+	if len(outcomes) != 2 {
+		t.Fatalf("Expected 2 outcomes, got %d", len(outcomes))
+	}
+	if valid := outcomes[0]; valid.Outcome != self.OutcomeValid || valid.AttestationId != pilotId || valid.Scope != "self-playground" || valid.ErrorCode != "" {
+		t.Errorf("Unexpected outcome for a valid proof: %+v", valid)
+	}
+	if failed := outcomes[1]; failed.Outcome == self.OutcomeValid || failed.ErrorCode != self.ErrorCodeFor(verifyErr) || failed.ConfigId != "test-config-id" {
+		t.Errorf("Unexpected outcome for a failed proof: %+v (err=%v)", failed, verifyErr)
+	}
+}
+
+func TestVerify_RecordsServiceFailuresAsErrors(t *testing.T) {
+	const slowId self.AttestationId = 100
+	store := hangingConfigStore{release: make(chan struct{})}
+	defer close(store.release)
+
+	tests := []struct {
+		name          string
+		attestationId self.AttestationId
+		store         self.ConfigStore
+		opts          []self.VerifierOption
+		expected      self.ErrorCode
+	}{
+		{"a verification timeout", slowId, createTestMockConfigStore(createTestVerificationConfig()), []self.VerifierOption{self.WithVerifyTimeout(20 * time.Millisecond)}, self.ErrorCodeVerificationTimeout},
+		{"a config store timeout", self.Passport, store, []self.VerifierOption{self.WithConfigStoreTimeout(20 * time.Millisecond)}, self.ErrorCodeConfigStoreTimeout},
+		{"an unreachable RPC endpoint", self.Passport, createTestMockConfigStore(createTestVerificationConfig()), []self.VerifierOption{self.WithRPCURL("http://127.0.0.1:1")}, self.ErrorCodeInternal},
+	}
+	for _, tt := range tests {
+		recorder := &collectingOutcomeRecorder{}
+		opts := append(offlineTestOptions(t), self.WithOutcomeRecorder(recorder))
+		verifier, err := self.NewBackendVerifier(
+			"self-playground",
+			"https://playground.self.xyz/api/verify",
+			false,
+			map[self.AttestationId]bool{self.Passport: true, slowId: true},
+			tt.store,
+			self.UserIDTypeUUID,
+			append(opts, tt.opts...)...,
+		)
+		if err != nil {
+			t.Fatalf("Failed to create verifier: %v", err)
+		}
+		verifier.RegisterAttestationVerifier(slowId, slowAttestationVerifier{})
+
+		_, err = verifier.Verify(context.Background(), int(tt.attestationId), testProof, testPublicSignals, createTestUserContextData())
+		if self.ErrorCodeFor(err) != tt.expected {
+			t.Fatalf("%s: expected %s, got %v", tt.name, tt.expected, err)
+		}
+		outcomes := recorder.recorded()
+		if len(outcomes) != 1 || outcomes[0].Outcome != self.OutcomeError || outcomes[0].ErrorCode != tt.expected {
+			t.Errorf("%s: expected an error outcome with %s, got %+v", tt.name, tt.expected, outcomes)
+		}
+	}
+}
+
+func TestBufferedOutcomeRecorder(t *testing.T) {
+	recorder := &collectingOutcomeRecorder{release: make(chan struct{})}
+	buffered := self.NewBufferedOutcomeRecorder(recorder, 1, nil)
+
+	// The writer blocks on the first outcome, so the buffer of one soon overflows
+	outcome := self.VerificationOutcome{Outcome: self.OutcomeValid}
+	accepted, dropped := 0, 0
+	for i := 0; i < 4; i++ {
+		switch err := buffered.RecordOutcome(context.Background(), outcome); {
+		case err == nil:
+			accepted++
+		case errors.Is(err, self.ErrOutcomeBufferFull):
+			dropped++
+		default:
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if dropped == 0 {
+		t.Errorf("Expected outcomes to be dropped once the buffer is full")
+	}
+
+	close(recorder.release)
+	if err := buffered.Close(context.Background()); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+	if got := len(recorder.recorded()); got != accepted {
+		t.Errorf("Expected the %d queued outcomes to be flushed, got %d", accepted, got)
+	}
+	if err := buffered.RecordOutcome(context.Background(), outcome); !errors.Is(err, self.ErrOutcomeBufferFull) {
+		t.Errorf("Expected outcomes to be dropped after Close, got %v", err)
+	}
+}
+
+func TestCountryMetrics(t *testing.T) {
+	metrics := self.NewCountryMetrics(2)
+	collector := &collectingOutcomeRecorder{}
+	recorder := self.MultiOutcomeRecorder{metrics, collector}
+	record := func(country, nationality string, outcome self.Outcome, times int) {
+		for i := 0; i < times; i++ {
+			if err := recorder.RecordOutcome(context.Background(), self.VerificationOutcome{
+				Country: country, Nationality: nationality, Outcome: outcome,
+			}); err != nil {
+				t.Fatalf("RecordOutcome failed: %v", err)
+			}
+		}
+	}
+	record("USA", "USA", self.OutcomeValid, 3)
+	record("GBR", "IND", self.OutcomeValid, 1)
+	record("GBR", "IND", self.OutcomeInvalid, 1)
+	record("FRA", "", self.OutcomeError, 1)
+	record("ZZZ", "", self.OutcomeInvalid, 1) // Not a country code, so counted as other
+
+	if got := len(collector.recorded()); got != 7 {
+		t.Errorf("Expected every outcome to reach each recorder, got %d", got)
+	}
+
+	snapshot := metrics.Snapshot()
+	want := []self.CountryStats{
+		{Country: "USA", Total: 3, Valid: 3},
+		{Country: "GBR", Total: 2, Valid: 1, Invalid: 1},
+		{Country: self.CountryLabelOther, Total: 2, Invalid: 1, Errors: 1},
+	}
+	if len(snapshot.IssuingStates) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, snapshot.IssuingStates)
+	}
+	for i := range want {
+		if snapshot.IssuingStates[i] != want[i] {
+			t.Errorf("Expected issuing state %d to be %+v, got %+v", i, want[i], snapshot.IssuingStates[i])
+		}
+	}
+	if rate := snapshot.IssuingStates[1].SuccessRate(); rate != 0.5 {
+		t.Errorf("Expected a success rate of 0.5, got %v", rate)
+	}
+
+	// Undisclosed nationalities compete for the top N like countries, ties ordered by label, so they
+	// fall into other here
+	var nationalities []string
+	for _, stats := range snapshot.Nationalities {
+		nationalities = append(nationalities, stats.Country)
+	}
+	if got := strings.Join(nationalities, ","); got != "USA,IND,other" {
+		t.Errorf("Expected nationalities USA,IND,other, got %s", got)
+	}
+
+	metrics.Reset()
+	if snapshot := metrics.Snapshot(); len(snapshot.IssuingStates) != 0 {
+		t.Errorf("Expected no counts after Reset, got %v", snapshot.IssuingStates)
+	}
+}
+
+func TestVerify_ReadOnly(t *testing.T) {
+	const pilotId self.AttestationId = 99
+	recorder := &collectingOutcomeRecorder{}
+	auditSink := self.NewInMemoryAuditSink()
+	traceSink := &memoryTraceSink{}
+	cache := self.NewResultCache(time.Hour)
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{self.Passport: true, pilotId: true},
+		createTestMockConfigStore(createTestVerificationConfig()),
+		self.UserIDTypeUUID,
+		self.WithOutcomeRecorder(recorder),
+		self.WithAuditSink(auditSink),
+		self.WithTraceSink(traceSink),
+		self.WithResultCache(cache),
+		self.WithReadOnly(true),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	if err := verifier.RegisterAttestationVerifier(pilotId, staticAttestationVerifier{}); err != nil {
+		t.Fatalf("Failed to register attestation verifier: %v", err)
+	}
+
+	// A valid and a rejected proof still produce their result and error
+	result, err := verifier.Verify(context.Background(), int(pilotId), testProof, testPublicSignals, createTestUserContextData())
+	if err != nil || !result.IsValidDetails.IsValid {
+		t.Fatalf("Expected a valid result, got %+v (err=%v)", result, err)
+	}
+	if _, err := verifier.Verify(context.Background(), 1, testProof, testPublicSignals, "00"); err == nil {
+		t.Fatal("Expected the malformed proof to be rejected")
+	}
+
+	if outcomes := recorder.recorded(); len(outcomes) != 0 {
+		t.Errorf("Expected no outcomes in read-only mode, got %+v", outcomes)
+	}
+	if records := auditSink.Records(); len(records) != 0 {
+		t.Errorf("Expected no audit records in read-only mode, got %+v", records)
+	}
+	if len(traceSink.traces) != 0 {
+		t.Errorf("Expected no traces in read-only mode, got %+v", traceSink.traces)
+	}
+	result, err = verifier.Verify(context.Background(), int(pilotId), testProof, testPublicSignals, createTestUserContextData())
+	if err != nil || result.Cached {
+		t.Errorf("Expected the valid result not to be cached in read-only mode, got cached %v (err=%v)", result != nil && result.Cached, err)
+	}
+}
@@ -0,0 +1,93 @@
+package selfBackendVerifier
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func TestParseAttestationIDs(t *testing.T) {
+	allowedIds, err := self.ParseAttestationIDs([]string{"Passport", " AADHAAR ", "2", ""})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(allowedIds) != 3 || !allowedIds[self.Passport] || !allowedIds[self.EUCard] || !allowedIds[self.Aadhaar] {
+		t.Errorf("Expected all attestation types, got %v", allowedIds)
+	}
+
+	if _, err := self.ParseAttestationIDs([]string{"passport", "drivers_license", "9"}); err == nil {
+		t.Error("Expected unknown attestation IDs to fail")
+	}
+	if _, err := self.ParseAttestationIDs(nil); err == nil {
+		t.Error("Expected an empty list to fail")
+	}
+}
+
+func TestVerify_StrictAttestationIds(t *testing.T) {
+	const customId self.AttestationId = 96
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{self.Passport: true, customId: true},
+		createTestMockConfigStore(createTestVerificationConfig()),
+		self.UserIDTypeUUID,
+		self.WithStrictAttestationIds(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	verifier.RegisterAttestationVerifier(customId, staticAttestationVerifier{})
+
+	_, err = verifier.Verify(context.Background(), 7, testProof, testPublicSignals, createTestUserContextData())
+	if !errors.Is(err, self.ErrUnknownAttestationType) || !errors.Is(err, self.ErrAttestationNotAllowed) {
+		t.Fatalf("Expected ErrUnknownAttestationType, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "received 7") {
+		t.Errorf("Expected the error to name the received value, got %v", err)
+	}
+	if code := self.ErrorCodeFor(err); code != self.ErrorCodeUnknownAttestationType {
+		t.Errorf("Expected code %s, got %s", self.ErrorCodeUnknownAttestationType, code)
+	}
+
+	// Built-in and registered types are known
+	for _, id := range []int{int(self.Passport), int(self.Aadhaar), int(customId)} {
+		if _, err := verifier.ParseAttestationType(id); err != nil {
+			t.Errorf("Expected attestation ID %d to be known, got %v", id, err)
+		}
+	}
+	if _, err := verifier.Verify(context.Background(), int(customId), testProof, testPublicSignals, createTestUserContextData()); err != nil {
+		t.Errorf("Expected the registered type to verify, got %v", err)
+	}
+}
+
+func TestVerify_AttestationNotAllowedError(t *testing.T) {
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{self.Aadhaar: true, self.EUCard: true, self.Passport: false},
+		createTestMockConfigStore(createTestVerificationConfig()),
+		self.UserIDTypeUUID,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+
+	_, err = verifier.Verify(context.Background(), int(self.Passport), testProof, testPublicSignals, createTestUserContextData())
+	var notAllowed *self.AttestationNotAllowedError
+	if !errors.As(err, &notAllowed) || !errors.Is(err, self.ErrAttestationNotAllowed) {
+		t.Fatalf("Expected an AttestationNotAllowedError, got %v", err)
+	}
+	expected := []self.AttestationId{self.EUCard, self.Aadhaar}
+	if notAllowed.AttestationId != self.Passport || !reflect.DeepEqual(notAllowed.Allowed, expected) {
+		t.Errorf("Expected attestation %d with allowed %v, got %+v", self.Passport, expected, notAllowed)
+	}
+	if code := self.ErrorCodeFor(err); code != self.ErrorCodeAttestationNotAllowed {
+		t.Errorf("Expected code %s, got %s", self.ErrorCodeAttestationNotAllowed, code)
+	}
+}
@@ -0,0 +1,34 @@
+package selfBackendVerifier
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func TestVerify_PlaceholderData(t *testing.T) {
+	config := createTestVerificationConfig()
+	indices := self.RevealedDataIndices[self.Passport]
+	zeroDocumentNumber := withRevealedData(indices.IdNumberStart, strings.Repeat("0", indices.IdNumberEnd-indices.IdNumberStart+1))
+	epochDateOfBirth := withRevealedData(indices.DateOfBirthStart, "700101")
+
+	// Placeholders are accepted unless rejection is enabled
+	result, err := verifyOffline(t, config, zeroDocumentNumber)
+	if err != nil || !result.IsValidDetails.IsValid {
+		t.Errorf("Expected placeholders to be accepted by default, got %+v, %v", result, err)
+	}
+
+	for name, signals := range map[string][]string{"document number": zeroDocumentNumber, "date of birth": epochDateOfBirth} {
+		_, err := verifyOffline(t, config, signals, self.WithPlaceholderRejection())
+		if !errors.Is(err, self.ErrPlaceholderData) || self.ErrorCodeFor(err) != self.ErrorCodePlaceholderData {
+			t.Errorf("Expected ErrPlaceholderData for a placeholder %s, got %v", name, err)
+		}
+	}
+
+	result, err = verifyOffline(t, config, testPublicSignals, self.WithPlaceholderRejection())
+	if err != nil || !result.IsValidDetails.IsValid {
+		t.Errorf("Expected the test proof to carry no placeholders, got %+v, %v", result, err)
+	}
+}
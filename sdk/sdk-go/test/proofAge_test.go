@@ -0,0 +1,48 @@
+package selfBackendVerifier
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func TestVerify_MaxProofAge(t *testing.T) {
+	// The test proof was generated on 2025-08-12
+	_, err := verifyOffline(t, createTestVerificationConfig(), testPublicSignals, self.WithMaxProofAge(time.Hour))
+	if !errors.Is(err, self.ErrProofTooOld) || !errors.Is(err, self.ErrInvalidTimestamp) {
+		t.Errorf("Expected ErrProofTooOld, got %v", err)
+	}
+
+	result, err := verifyOffline(t, createTestVerificationConfig(), testPublicSignals, self.WithMaxProofAge(100*365*24*time.Hour))
+	if err != nil || !result.IsValidDetails.IsValid {
+		t.Errorf("Expected a valid result with a large maximum proof age, got %+v, %v", result, err)
+	}
+}
+
+func TestVerify_AdvisoryTimestampCheck(t *testing.T) {
+	config := createTestVerificationConfig()
+	config.AdvisoryChecks = []self.CheckName{self.CheckTimestamp}
+
+	result, err := verifyOffline(t, config, testPublicSignals, self.WithMaxProofAge(time.Hour))
+	if err != nil || !result.IsValidDetails.IsValid {
+		t.Fatalf("Expected advisory timestamp check not to fail verification, got %+v, %v", result, err)
+	}
+	if len(result.AdvisoryIssues) != 1 || result.AdvisoryIssues[0].Type != self.InvalidTimestamp {
+		t.Errorf("Expected the timestamp issue to be reported as advisory, got %+v", result.AdvisoryIssues)
+	}
+}
+
+func TestVerify_ZeroProofDate(t *testing.T) {
+	// A proof committing no date would otherwise escape the age window and the circuit's expiry check
+	signals := append([]string(nil), testPublicSignals...)
+	dateIndex := self.DiscloseIndices[self.Passport].CurrentDateIndex
+	for i := dateIndex; i < dateIndex+6; i++ {
+		signals[i] = "0"
+	}
+	_, err := verifyOffline(t, createTestVerificationConfig(), signals)
+	if !errors.Is(err, self.ErrProofTooOld) || !errors.Is(err, self.ErrInvalidTimestamp) {
+		t.Errorf("Expected ErrProofTooOld for a proof without a date, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package selfBackendVerifier
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func TestVerificationResult_ProtoRoundTrip(t *testing.T) {
+	result := createTestVerificationResult(t)
+	result.IsValidDetails.IsValid = true
+	result.IsValidDetails.IsOfacValid = true
+	result.Scope = "self-playground"
+	result.ConfigId = "test-config-id"
+	result.ConfigHash = "abc123"
+	result.ProofVerificationTime = 42 * time.Millisecond
+	issuedAsMinor := true
+	result.IssuedAsMinor = &issuedAsMinor
+	result.DisclosureWithheld = true
+	result.DisclosureStatus = map[self.DisclosureField]self.DisclosureStatus{
+		self.DisclosureDateOfBirth: self.DisclosureStatusWithheld,
+		self.DisclosureName:        self.DisclosureStatusNotProvided,
+		self.DisclosureGender:      self.DisclosureStatusHiddenByPolicy,
+	}
+	result.DiscloseOutput.DateOfIssueISO = "2010-05-01"
+	result.UserData.UserIdentifier = "user-1"
+	result.Score = &self.VerificationScore{Score: 0.75, Reasons: []string{"a", "b"}}
+	result.AdvisoryIssues = []self.ConfigIssue{{Type: self.InvalidOfac, Message: "advisory"}}
+	result.OFACOverride = &self.OFACOverride{Nullifier: "n", ClearedBy: "compliance", ClearedAt: time.Unix(1700000000, 5).UTC()}
+	documentValid := false
+	result.DiscloseOutput.DocumentValid = &documentValid
+	result.DiscloseOutput.Ofac = []bool{true, false, true}
+	result.DiscloseOutput.MRZ = &self.MRZComponents{OptionalData: "<<<", CompositeCheckDigit: "4"}
+	result.DiscloseOutput.DocumentSubtype = self.DocumentSubtypeDiplomatic
+	result.Config = createTestVerificationConfig()
+	result.RawDiscloseOutput = nil
+
+	data, err := result.MarshalProto()
+	if err != nil {
+		t.Fatalf("Failed to marshal result: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		if again, _ := result.MarshalProto(); !bytes.Equal(again, data) {
+			t.Fatal("Expected the disclosure statuses to encode deterministically")
+		}
+	}
+
+	var decoded self.VerificationResult
+	if err := decoded.UnmarshalProto(data); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, *result) {
+		t.Errorf("Round trip mismatch:\n got %+v\nwant %+v", decoded, *result)
+	}
+
+	if err := decoded.UnmarshalProto(data[:len(data)-1]); err == nil {
+		t.Error("Expected an error for a truncated message")
+	}
+}
@@ -0,0 +1,171 @@
+package selfBackendVerifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func TestCanonicalizePublicSignals(t *testing.T) {
+	scope, _ := new(big.Int).SetString(testPublicSignals[19], 10)
+
+	signals := append([]string{}, testPublicSignals...)
+	signals[19] = fmt.Sprintf("0x%x", scope)
+	signals[10] = "02"
+
+	canonical := self.CanonicalizePublicSignals(signals)
+	for i := range testPublicSignals {
+		if canonical[i] != testPublicSignals[i] {
+			t.Errorf("Signal %d: expected %s, got %s", i, testPublicSignals[i], canonical[i])
+		}
+	}
+}
+
+func TestValidatePublicSignalsOrder(t *testing.T) {
+	layout, err := self.DetectCircuitVersion(self.Passport, testPublicSignals)
+	if err != nil {
+		t.Fatalf("Failed to detect circuit version: %v", err)
+	}
+	if err := self.ValidatePublicSignalsOrder(layout, self.Passport, testPublicSignals); err != nil {
+		t.Errorf("Expected circuit ordered signals to be valid: %v", err)
+	}
+
+	sorted := append([]string{}, testPublicSignals...)
+	sort.Strings(sorted)
+	if err := self.ValidatePublicSignalsOrder(layout, self.Passport, sorted); !errors.Is(err, self.ErrMisorderedPublicSignals) {
+		t.Errorf("Expected ErrMisorderedPublicSignals for sorted signals, got %v", err)
+	}
+}
+
+func TestVerifyInput_AcceptsNumericFieldElements(t *testing.T) {
+	body := `{
+		"attestationId": 1,
+		"proof": {"a": [1, "0x2"], "b": [["3", 4], [5, "6"]], "c": [7, "8"]},
+		"publicSignals": [12345678901234567890123456789, "0xff", "42"],
+		"userContextData": "00"
+	}`
+	var input self.VerifyInput
+	if err := json.Unmarshal([]byte(body), &input); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedSignals := []string{"12345678901234567890123456789", "255", "42"}
+	for i, signal := range expectedSignals {
+		if input.PublicSignals[i] != signal {
+			t.Errorf("Signal %d: expected %s, got %s", i, signal, input.PublicSignals[i])
+		}
+	}
+	if input.Proof.A[1] != "2" || input.Proof.B[0][1] != "4" || input.Proof.C[0] != "7" {
+		t.Errorf("Expected normalized proof elements, got %+v", input.Proof)
+	}
+
+	// Values outside the BN254 scalar field are rejected
+	outOfField := `{"publicSignals": ["21888242871839275222246405745257275088548364400416034343698204186575808495617"]}`
+	if err := json.Unmarshal([]byte(outOfField), &input); !errors.Is(err, self.ErrInvalidFieldElement) {
+		t.Errorf("Expected ErrInvalidFieldElement, got %v", err)
+	}
+	if err := json.Unmarshal([]byte(`{"publicSignals": [1.5]}`), &input); !errors.Is(err, self.ErrInvalidFieldElement) {
+		t.Errorf("Expected ErrInvalidFieldElement for a fractional number, got %v", err)
+	}
+
+	// Proof coordinates are elements of the larger base field
+	coordinate := `{"proof": {"a": ["21888242871839275222246405745257275088548364400416034343698204186575808495617", "1"]}}`
+	if err := json.Unmarshal([]byte(coordinate), &input); err != nil {
+		t.Errorf("Expected a coordinate above the scalar field order to be accepted, got %v", err)
+	}
+	outOfField = `{"proof": {"a": ["21888242871839275222246405745257275088696311157297823662689037894645226208583", "1"]}}`
+	if err := json.Unmarshal([]byte(outOfField), &input); !errors.Is(err, self.ErrInvalidFieldElement) {
+		t.Errorf("Expected ErrInvalidFieldElement for a coordinate outside the base field, got %v", err)
+	}
+}
+
+func TestPublicSignals_RejectsOversizedArrays(t *testing.T) {
+	oversized := "[" + strings.Repeat(`"1",`, self.DefaultMaxPublicSignals) + `"1"]`
+	var signals self.PublicSignals
+	if err := json.Unmarshal([]byte(oversized), &signals); !errors.Is(err, self.ErrTooManyPublicSignals) {
+		t.Errorf("Expected ErrTooManyPublicSignals, got %v", err)
+	}
+
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{self.Passport: true},
+		createTestMockConfigStore(createTestVerificationConfig()),
+		self.UserIDTypeUUID,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	_, err = verifier.Verify(context.Background(), 1, testProof, make([]string, self.DefaultMaxPublicSignals+1), createTestUserContextData())
+	if !errors.Is(err, self.ErrTooManyPublicSignals) || self.ErrorCodeFor(err) != self.ErrorCodeInvalidPublicSignals {
+		t.Errorf("Expected ErrTooManyPublicSignals from Verify, got %v", err)
+	}
+}
+
+func TestVerify_MaxPublicSignals(t *testing.T) {
+	const pilotId self.AttestationId = 99
+	limit := len(testPublicSignals) - 1
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{self.Passport: true, pilotId: true},
+		createTestMockConfigStore(createTestVerificationConfig()),
+		self.UserIDTypeUUID,
+		self.WithMaxPublicSignals(limit),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	if err := verifier.RegisterAttestationVerifier(pilotId, staticAttestationVerifier{}); err != nil {
+		t.Fatalf("Failed to register attestation verifier: %v", err)
+	}
+
+	// The limit applies to built-in and custom attestation types alike
+	for _, id := range []self.AttestationId{self.Passport, pilotId} {
+		_, err := verifier.Verify(context.Background(), int(id), testProof, testPublicSignals, createTestUserContextData())
+		if !errors.Is(err, self.ErrTooManyPublicSignals) {
+			t.Errorf("Attestation %d: expected ErrTooManyPublicSignals, got %v", id, err)
+		}
+	}
+	if _, err := verifier.Verify(context.Background(), int(pilotId), testProof, testPublicSignals[:limit], createTestUserContextData()); err != nil {
+		t.Errorf("Expected signals within the limit to be accepted, got %v", err)
+	}
+
+	// VerifyHandler decodes at most the verifier's limit, or its own when set
+	body, _ := json.Marshal(self.VerifyInput{
+		AttestationId:   int(pilotId),
+		Proof:           testProof,
+		PublicSignals:   testPublicSignals,
+		UserContextData: createTestUserContextData(),
+	})
+	handler := self.NewVerifyHandler(verifier)
+	for _, handlerLimit := range []int{0, 4} {
+		handler.MaxPublicSignals = handlerLimit
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/verify", bytes.NewReader(body)))
+		if recorder.Code != http.StatusBadRequest {
+			t.Fatalf("Handler limit %d: expected status %d, got %d", handlerLimit, http.StatusBadRequest, recorder.Code)
+		}
+		response := decodeErrorResponse(t, recorder)
+		if len(response.Errors) != 1 || response.Errors[0].Field != "publicSignals" {
+			t.Errorf("Handler limit %d: expected a publicSignals error, got %+v", handlerLimit, response.Errors)
+		}
+	}
+
+	_, err = self.DecodeVerifyInputWithLimits(bytes.NewReader(body), self.DecodeLimits{MaxPublicSignals: 4})
+	if err == nil || !strings.Contains(err.Error(), self.ErrTooManyPublicSignals.Error()) {
+		t.Errorf("Expected DecodeVerifyInputWithLimits to reject the signals, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package selfBackendVerifier
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+// recordingPublisher keeps every published message
+type recordingPublisher struct {
+	mu       sync.Mutex
+	messages [][]byte
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, message []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages = append(p.messages, message)
+	return nil
+}
+
+func TestQueueConsumer_Run(t *testing.T) {
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{self.EUCard: true},
+		createTestMockConfigStore(createTestVerificationConfig()),
+		self.UserIDTypeUUID,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+
+	results := &recordingPublisher{}
+	deadLetter := &recordingPublisher{}
+	consumer, err := self.NewQueueConsumer(verifier, self.QueueConsumerConfig{
+		Concurrency: 2,
+		Results:     results,
+		DeadLetter:  deadLetter,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create consumer: %v", err)
+	}
+
+	message, _ := json.Marshal(self.VerifyMessage{
+		CorrelationId: "request-1",
+		VerifyInput: self.VerifyInput{
+			AttestationId:   int(self.Passport),
+			Proof:           testProof,
+			PublicSignals:   testPublicSignals,
+			UserContextData: createTestUserContextData(),
+		},
+	})
+	messages := make(chan []byte, 2)
+	messages <- message
+	messages <- []byte("{not json")
+	close(messages)
+
+	if err := consumer.Run(context.Background(), messages); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(results.messages) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results.messages))
+	}
+	var result self.VerifyResultMessage
+	if err := json.Unmarshal(results.messages[0], &result); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if result.CorrelationId != "request-1" || result.Error == nil || result.Error.Code != self.ErrorCodeAttestationNotAllowed {
+		t.Errorf("Expected an %s error result for request-1, got %+v", self.ErrorCodeAttestationNotAllowed, result)
+	}
+
+	if len(deadLetter.messages) != 1 {
+		t.Fatalf("Expected the malformed message to be dead-lettered, got %d dead letters", len(deadLetter.messages))
+	}
+}
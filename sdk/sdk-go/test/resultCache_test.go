@@ -0,0 +1,216 @@
+package selfBackendVerifier
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+	"github.com/selfxyz/self/sdk/sdk-go/common"
+)
+
+// nullifierAttestationVerifier is an AttestationVerifier accepting every proof with a fixed nullifier,
+// counting its calls
+type nullifierAttestationVerifier struct{ calls *int }
+
+func (v nullifierAttestationVerifier) Verify(ctx context.Context, proof self.VcAndDiscloseProof, publicSignals []string, userContextData string) (*self.VerificationResult, error) {
+	*v.calls++
+	return &self.VerificationResult{
+		IsValidDetails: self.IsValidDetails{IsValid: true, IsMinimumAgeValid: true, IsOfacValid: true},
+		DiscloseOutput: self.GenericDiscloseOutput{Nullifier: "42"},
+	}, nil
+}
+
+func TestVerify_ResultCache(t *testing.T) {
+	const pilotId self.AttestationId = 99
+	cache := self.NewResultCache(time.Hour)
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{pilotId: true},
+		createTestMockConfigStore(createTestVerificationConfig()),
+		self.UserIDTypeUUID,
+		self.WithResultCache(cache),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	calls := 0
+	verifier.RegisterAttestationVerifier(pilotId, nullifierAttestationVerifier{calls: &calls})
+
+	input := self.VerifyInput{
+		AttestationId:   int(pilotId),
+		Proof:           testProof,
+		PublicSignals:   testPublicSignals,
+		UserContextData: createTestUserContextData(),
+	}
+	verify := func() *self.VerificationResult {
+		result, err := verifier.Verify(context.Background(), input.AttestationId, input.Proof, input.PublicSignals, input.UserContextData)
+		if err != nil {
+			t.Fatalf("Expected a valid proof, got %v", err)
+		}
+		return result
+	}
+
+	if first := verify(); first.Cached {
+		t.Errorf("Expected the first result to be verified")
+	}
+	if retried := verify(); !retried.Cached || calls != 1 {
+		t.Errorf("Expected the retry to be answered from cache, cached=%v after %d verifications", retried.Cached, calls)
+	}
+
+	if !cache.InvalidateResult(self.ResultCacheKey(input)) {
+		t.Errorf("Expected the result to be invalidated by key")
+	}
+	if verify(); calls != 2 {
+		t.Errorf("Expected the proof to be verified again after invalidation, got %d verifications", calls)
+	}
+
+	if removed := cache.InvalidateByNullifier("42"); removed != 1 {
+		t.Errorf("Expected 1 result to be invalidated by nullifier, got %d", removed)
+	}
+	if _, ok := cache.Get(self.ResultCacheKey(input)); ok {
+		t.Errorf("Expected no cached result after invalidation by nullifier")
+	}
+}
+
+func TestVerify_ResultCacheSkipsDisclosureOverrides(t *testing.T) {
+	const pilotId self.AttestationId = 99
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{pilotId: true},
+		createTestMockConfigStore(createTestVerificationConfig()),
+		self.UserIDTypeUUID,
+		self.WithResultCache(self.NewResultCache(time.Hour)),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	calls := 0
+	if err := verifier.RegisterAttestationVerifier(pilotId, nullifierAttestationVerifier{calls: &calls}); err != nil {
+		t.Fatalf("Failed to register attestation verifier: %v", err)
+	}
+	verify := func(ctx context.Context) *self.VerificationResult {
+		result, err := verifier.Verify(ctx, int(pilotId), testProof, testPublicSignals, createTestUserContextData())
+		if err != nil {
+			t.Fatalf("Expected a valid proof, got %v", err)
+		}
+		return result
+	}
+
+	// A result checked against the store's config does not answer a call requesting other disclosures
+	verify(context.Background())
+	overrideCtx := self.ContextWithDisclosures(context.Background(), self.SelfAppDisclosureConfig{Name: true})
+	if result := verify(overrideCtx); result.Cached || calls != 2 {
+		t.Errorf("Expected the call with requested disclosures to be verified, cached=%v after %d verifications", result.Cached, calls)
+	}
+
+	// Nor is a result checked against requested disclosures cached for later calls
+	if result := verify(overrideCtx); result.Cached || calls != 3 {
+		t.Errorf("Expected no cached result for requested disclosures, cached=%v after %d verifications", result.Cached, calls)
+	}
+	if result := verify(context.Background()); !result.Cached || calls != 3 {
+		t.Errorf("Expected the store's config result to stay cached, cached=%v after %d verifications", result.Cached, calls)
+	}
+}
+
+func TestVerify_ResultCacheFollowsConfigChanges(t *testing.T) {
+	cache := self.NewResultCache(time.Hour)
+	sink := self.NewInMemoryAuditSink()
+	store := createTestMockConfigStore(createTestVerificationConfig())
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{self.Passport: true},
+		store,
+		self.UserIDTypeUUID,
+		append(offlineTestOptions(t), self.WithResultCache(cache), self.WithAuditSink(sink))...,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	verify := func() (*self.VerificationResult, error) {
+		return verifier.Verify(context.Background(), 1, testProof, testPublicSignals, createTestUserContextData())
+	}
+
+	if _, err := verify(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	retried, err := verify()
+	if err != nil || !retried.Cached {
+		t.Fatalf("Expected the retry to be answered from cache, got %+v, %v", retried, err)
+	}
+
+	// Cache hits are audited too, marked as cached
+	records := sink.Records()
+	if len(records) != 2 || records[0].Cached || !records[1].Cached || !records[1].IsValid {
+		t.Errorf("Expected the verification and its cached retry to be audited, got %+v", records)
+	}
+	if records[1].ConfigId != "test-config-id" || records[1].ConfigHash != records[0].ConfigHash || records[1].UserIdentifier == "" {
+		t.Errorf("Expected the cached retry to report the config and user, got %+v", records[1])
+	}
+
+	// A config change applies to the next call, even while the result is cached
+	stricter := createTestVerificationConfig()
+	stricter.MinimumAge = 21
+	store.SetConfig(context.Background(), "test-config-id", stricter)
+	if _, err := verify(); !errors.Is(err, self.ErrMinimumAgeNotMet) {
+		t.Errorf("Expected the changed config to apply, got %v", err)
+	}
+	if _, ok := cache.Get(self.ResultCacheKey(self.VerifyInput{
+		AttestationId:   1,
+		Proof:           testProof,
+		PublicSignals:   testPublicSignals,
+		UserContextData: createTestUserContextData(),
+	})); ok {
+		t.Errorf("Expected the outdated result to be purged")
+	}
+}
+
+func TestResultCache_CopiesResults(t *testing.T) {
+	cache := self.NewResultCache(time.Hour)
+	newResult := func() *self.VerificationResult {
+		valid := true
+		return &self.VerificationResult{
+			ForbiddenCountriesList: []string{"PRK"},
+			DiscloseOutput:         self.GenericDiscloseOutput{Nullifier: "42", Ofac: []bool{true}, DocumentValid: &valid},
+			Score:                  &self.VerificationScore{Score: 1, Reasons: []string{"fresh"}},
+			AdvisoryIssues:         []self.ConfigIssue{{Type: self.InvalidOfac, Message: "inconclusive"}},
+			Config:                 self.VerificationConfig{ExcludedCountries: []common.Country3LetterCode{"PRK"}},
+			DisclosureStatus:       map[self.DisclosureField]self.DisclosureStatus{self.DisclosureName: self.DisclosureStatusDisclosed},
+			MRZLines:               []string{"P<UTO"},
+		}
+	}
+	mutate := func(result *self.VerificationResult) {
+		result.ForbiddenCountriesList[0] = "FRA"
+		result.DiscloseOutput.Ofac[0] = false
+		*result.DiscloseOutput.DocumentValid = false
+		result.Score.Reasons[0] = "stale"
+		result.AdvisoryIssues[0].Message = "changed"
+		result.Config.ExcludedCountries[0] = "FRA"
+		result.DisclosureStatus[self.DisclosureName] = self.DisclosureStatusWithheld
+		result.MRZLines[0] = "changed"
+	}
+
+	// Neither the result put nor the results handed out share state with the cached one
+	original := newResult()
+	cache.Put("key", original)
+	mutate(original)
+	first, _ := cache.Get("key")
+	mutate(first)
+
+	cached, ok := cache.Get("key")
+	if !ok {
+		t.Fatalf("Expected a cached result")
+	}
+	expected := newResult()
+	if !reflect.DeepEqual(cached, expected) {
+		t.Errorf("Expected the cached result to be unchanged\ngot:      %+v\nexpected: %+v", cached, expected)
+	}
+}
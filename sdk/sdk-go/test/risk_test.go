@@ -0,0 +1,48 @@
+package selfBackendVerifier
+
+import (
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+	common "github.com/selfxyz/self/sdk/sdk-go/common"
+)
+
+func TestVerify_CountryRiskTiers(t *testing.T) {
+	indices := self.RevealedDataIndices[self.Passport]
+	tiers := self.WithCountryRiskTiers(map[common.Country3LetterCode]self.RiskTier{
+		common.FRA: self.RiskTierLow,
+		common.IRN: self.RiskTierHigh,
+		"D":        self.RiskTierMedium,
+	})
+	disclosing := func(issuingState, nationality string) []string {
+		return revealData(withRevealedData(indices.IssuingStateStart, issuingState), indices.NationalityStart, nationality)
+	}
+	tests := []struct {
+		name                 string
+		signals              []string
+		opts                 []self.VerifierOption
+		expectedIssuingState self.RiskTier
+		expectedNationality  self.RiskTier
+	}{
+		{name: "mapped countries", signals: disclosing("FRA", "IRN"), opts: []self.VerifierOption{tiers},
+			expectedIssuingState: self.RiskTierLow, expectedNationality: self.RiskTierHigh},
+		{name: "unmapped country", signals: disclosing("DEU", "FRA"), opts: []self.VerifierOption{tiers},
+			expectedIssuingState: self.RiskTierUnknown, expectedNationality: self.RiskTierLow},
+		{name: "undisclosed countries", signals: testPublicSignals, opts: []self.VerifierOption{tiers},
+			expectedIssuingState: self.RiskTierUnknown, expectedNationality: self.RiskTierUnknown},
+		{name: "null-padded country", signals: disclosing("D\x00\x00", "\x00D\x00"), opts: []self.VerifierOption{tiers},
+			expectedIssuingState: self.RiskTierMedium, expectedNationality: self.RiskTierMedium},
+		{name: "no tiers", signals: disclosing("FRA", "IRN")},
+	}
+	for _, tt := range tests {
+		result, err := verifyOffline(t, createTestVerificationConfig(), tt.signals, tt.opts...)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if result.IssuingStateRiskTier != tt.expectedIssuingState || result.NationalityRiskTier != tt.expectedNationality {
+			t.Errorf("%s: expected tiers %q and %q, got %q and %q", tt.name, tt.expectedIssuingState, tt.expectedNationality,
+				result.IssuingStateRiskTier, result.NationalityRiskTier)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package selfBackendVerifier
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func TestVerify_PinnedRoots(t *testing.T) {
+	proofRoot, _ := new(big.Int).SetString(testPublicSignals[self.DiscloseIndices[self.Passport].MerkleRootIndex], 10)
+	oldRoot := big.NewInt(42)
+	config := createTestVerificationConfig()
+	// The proof itself is accepted by a test chain, so only the pinned roots decide
+	chain := newTestChain(t, true)
+	verifyWithRoots := func(opts ...self.VerifierOption) error {
+		opts = append([]self.VerifierOption{self.WithRPCURL(chain), self.WithMaxProofAge(100 * 365 * 24 * time.Hour)}, opts...)
+		return verifyTestProofWithOptions(t, config, opts...)
+	}
+
+	var logs bytes.Buffer
+	logger := self.WithLogger(log.New(&logs, "", 0))
+	err := verifyWithRoots(logger, self.WithPinnedRoots(self.Passport,
+		self.PinnedRoot{Root: oldRoot, ExpiresAt: time.Now().Add(time.Hour)},
+		self.PinnedRoot{Root: proofRoot},
+	))
+	if err != nil {
+		t.Errorf("Expected the pinned root to be accepted, got %v", err)
+	}
+	if !strings.Contains(logs.String(), "matched pinned root 2 of 2") {
+		t.Errorf("Expected the matching root to be logged, got %q", logs.String())
+	}
+
+	err = verifyWithRoots(self.WithPinnedRoots(self.Passport,
+		self.PinnedRoot{Root: proofRoot, ExpiresAt: time.Now().Add(-time.Minute)},
+	))
+	if !errors.Is(err, self.ErrInvalidRoot) {
+		t.Errorf("Expected ErrInvalidRoot for an expired root, got %v", err)
+	}
+
+	err = verifyWithRoots(self.WithPinnedRoots(self.Passport, self.PinnedRoot{Root: oldRoot}))
+	if !errors.Is(err, self.ErrInvalidRoot) {
+		t.Errorf("Expected ErrInvalidRoot for an unpinned root, got %v", err)
+	}
+}
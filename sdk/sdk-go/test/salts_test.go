@@ -0,0 +1,101 @@
+package selfBackendVerifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+// saltedAttestationVerifier is an AttestationVerifier whose proofs commit the name with a fixed salt
+type saltedAttestationVerifier struct {
+	staticAttestationVerifier
+}
+
+func (saltedAttestationVerifier) VerifyDisclosureSalts(ctx context.Context, result *self.VerificationResult, publicSignals []string, salts map[self.DisclosureField]string) error {
+	for field, salt := range salts {
+		if field != self.DisclosureName || salt != "pepper" {
+			return fmt.Errorf("%w: %s", self.ErrSaltMismatch, field)
+		}
+	}
+	return nil
+}
+
+func TestVerify_DisclosureSalts(t *testing.T) {
+	const plainId, saltedId self.AttestationId = 98, 99
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{self.Passport: true, plainId: true, saltedId: true},
+		createTestMockConfigStore(createTestVerificationConfig()),
+		self.UserIDTypeUUID,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	if err := verifier.RegisterAttestationVerifier(plainId, staticAttestationVerifier{}); err != nil {
+		t.Fatalf("Failed to register attestation verifier: %v", err)
+	}
+	if err := verifier.RegisterAttestationVerifier(saltedId, saltedAttestationVerifier{}); err != nil {
+		t.Fatalf("Failed to register attestation verifier: %v", err)
+	}
+	verify := func(id self.AttestationId, salts map[self.DisclosureField]string) error {
+		_, err := verifier.VerifyBool(context.Background(), self.VerifyInput{
+			AttestationId:   int(id),
+			Proof:           testProof,
+			PublicSignals:   testPublicSignals,
+			UserContextData: createTestUserContextData(),
+			DisclosureSalts: salts,
+		})
+		return err
+	}
+
+	if err := verify(saltedId, map[self.DisclosureField]string{self.DisclosureName: "pepper"}); err != nil {
+		t.Errorf("Expected matching salts to verify, got %v", err)
+	}
+	if err := verify(saltedId, map[self.DisclosureField]string{self.DisclosureName: "salt"}); !errors.Is(err, self.ErrSaltMismatch) || self.ErrorCodeFor(err) != self.ErrorCodeSaltMismatch {
+		t.Errorf("Expected ErrSaltMismatch for a wrong salt, got %v", err)
+	}
+
+	// Salts are never ignored: types without salted fields reject them, built-in ones included
+	if err := verify(plainId, nil); err != nil {
+		t.Errorf("Expected a proof without salts to verify, got %v", err)
+	}
+	for _, id := range []self.AttestationId{plainId, self.Passport} {
+		if err := verify(id, map[self.DisclosureField]string{self.DisclosureName: "pepper"}); !errors.Is(err, self.ErrSaltMismatch) {
+			t.Errorf("Expected ErrSaltMismatch for salts sent with attestation type %d, got %v", id, err)
+		}
+	}
+}
+
+func TestDecodeVerifyInput_DisclosureSalts(t *testing.T) {
+	body := func(salts interface{}) []byte {
+		encoded, _ := json.Marshal(map[string]interface{}{
+			"attestationId":   1,
+			"proof":           testProof,
+			"publicSignals":   testPublicSignals,
+			"userContextData": createTestUserContextData(),
+			"disclosureSalts": salts,
+		})
+		return encoded
+	}
+
+	input, err := self.DecodeVerifyInput(bytes.NewReader(body(map[string]string{"name": "pepper"})))
+	if err != nil || input.DisclosureSalts[self.DisclosureName] != "pepper" {
+		t.Errorf("Expected the salts to be decoded, got %v (err=%v)", input.DisclosureSalts, err)
+	}
+	if _, err := self.DecodeVerifyInput(bytes.NewReader(body(nil))); err != nil {
+		t.Errorf("Expected salts to be optional, got %v", err)
+	}
+
+	_, err = self.DecodeVerifyInput(bytes.NewReader(body(map[string]string{"name": "", "shoe_size": "42"})))
+	var validationErr *self.ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Errors) != 2 {
+		t.Errorf("Expected an empty salt and an unknown field to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package selfBackendVerifier
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+	common "github.com/selfxyz/self/sdk/sdk-go/common"
+)
+
+func TestVerify_SanctionedNationalities(t *testing.T) {
+	config := createTestVerificationConfig()
+	config.SanctionedNationalities = []common.Country3LetterCode{common.PRK}
+	result, err := verifyOffline(t, config, testPublicSignals)
+	if err != nil || !result.IsValidDetails.IsValid {
+		t.Errorf("Expected sanctions enforced by the proof to pass, got %+v, %v", result, err)
+	}
+
+	// The test proof does not exclude Iran
+	config.SanctionedNationalities = []common.Country3LetterCode{common.IRN}
+	_, err = verifyOffline(t, config, testPublicSignals)
+	if !errors.Is(err, self.ErrSanctionedNationality) || errors.Is(err, self.ErrCountryExcluded) {
+		t.Errorf("Expected only ErrSanctionedNationality, got %v", err)
+	}
+}
+
+func TestVerify_RejectionLogOmitsDisclosedData(t *testing.T) {
+	var logs bytes.Buffer
+	config := createTestVerificationConfig()
+	config.SanctionedNationalities = []common.Country3LetterCode{common.IRN}
+	signals := withRevealedData(self.RevealedDataIndices[self.Passport].NationalityStart, "IRN")
+
+	_, err := verifyOffline(t, config, signals, self.WithLogger(log.New(&logs, "", 0)))
+	if !errors.Is(err, self.ErrSanctionedNationality) {
+		t.Fatalf("Expected ErrSanctionedNationality, got %v", err)
+	}
+	if !strings.Contains(logs.String(), string(self.InvalidSanctionedNationality)) {
+		t.Errorf("Expected the rejection to be logged with its issue type, got %q", logs.String())
+	}
+	if strings.Contains(logs.String(), "IRN") {
+		t.Errorf("Expected the rejection log not to reveal the nationality, got %q", logs.String())
+	}
+}
@@ -0,0 +1,84 @@
+package selfBackendVerifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+// newScopeTestVerifier creates a verifier for scope checking the passport test proof with opts
+func newScopeTestVerifier(t *testing.T, scope string, opts ...self.VerifierOption) *self.BackendVerifier {
+	t.Helper()
+	verifier, err := self.NewBackendVerifier(
+		scope,
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{self.Passport: true},
+		createTestMockConfigStore(createTestVerificationConfig()),
+		self.UserIDTypeUUID,
+		opts...,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	return verifier
+}
+
+func TestVerify_ExpectedScopeOverride(t *testing.T) {
+	verifier := newScopeTestVerifier(t, "other-app", append(offlineTestOptions(t), self.WithAllowedScopes("self-playground"))...)
+	verifyWithContext := func(ctx context.Context) (*self.VerificationResult, error) {
+		return verifier.Verify(ctx, 1, testProof, testPublicSignals, createTestUserContextData())
+	}
+
+	// The verifier's own scope is expected by default
+	if _, err := verifyWithContext(context.Background()); !errors.Is(err, self.ErrScopeMismatch) {
+		t.Errorf("Expected ErrScopeMismatch for the default scope, got %v", err)
+	}
+
+	// The proof is bound to the allowed scope, so it passes when that scope is expected
+	result, err := verifyWithContext(self.ContextWithExpectedScope(context.Background(), "self-playground"))
+	if err != nil || !result.IsValidDetails.IsValid {
+		t.Fatalf("Expected a valid result for the allowed scope, got %+v, %v", result, err)
+	}
+	if result.Scope != "self-playground" {
+		t.Errorf("Expected the result to report the expected scope, got %q", result.Scope)
+	}
+
+	_, err = verifyWithContext(self.ContextWithExpectedScope(context.Background(), "unknown-app"))
+	if !errors.Is(err, self.ErrScopeNotAllowed) || !errors.Is(err, self.ErrScopeMismatch) {
+		t.Errorf("Expected ErrScopeNotAllowed, got %v", err)
+	}
+}
+
+func TestVerify_ScopeMismatchError(t *testing.T) {
+	verifyWithScope := func(scope string, opts ...self.VerifierOption) error {
+		_, err := newScopeTestVerifier(t, scope, opts...).Verify(
+			context.Background(), 1, testProof, testPublicSignals, createTestUserContextData())
+		return err
+	}
+
+	// Offline, the scope is the only failure
+	err := verifyWithScope("other-app", offlineTestOptions(t)...)
+	var scopeErr *self.ScopeMismatchError
+	if !errors.As(err, &scopeErr) || !errors.Is(err, self.ErrScopeMismatch) {
+		t.Fatalf("Expected a *ScopeMismatchError, got %v", err)
+	}
+	if _, isMismatch := err.(*self.ConfigMismatchError); isMismatch {
+		t.Errorf("Expected the scope mismatch on its own, got %v", err)
+	}
+	if scopeErr.Got != testPublicSignals[self.DiscloseIndices[self.Passport].ScopeIndex] || scopeErr.Expected == scopeErr.Got || scopeErr.ScopeName != "other-app" {
+		t.Errorf("Expected both scopes to be reported, got %+v", scopeErr)
+	}
+	if code := self.ErrorCodeFor(err); code != self.ErrorCodeScopeMismatch {
+		t.Errorf("Expected code %s, got %s", self.ErrorCodeScopeMismatch, code)
+	}
+
+	// Alongside other failures the scope is one issue among several
+	err = verifyWithScope("other-app")
+	var mismatchErr *self.ConfigMismatchError
+	if !errors.As(err, &mismatchErr) || !errors.As(err, &scopeErr) || !errors.Is(err, self.ErrInvalidRoot) {
+		t.Errorf("Expected a *ConfigMismatchError carrying the scope mismatch, got %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package selfBackendVerifier
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func TestVerify_ScoringFunc(t *testing.T) {
+	var scored []self.IsValidDetails
+	scoring := self.WithScoringFunc(func(ctx context.Context, discloseOutput self.GenericDiscloseOutput, checks self.IsValidDetails) self.VerificationScore {
+		scored = append(scored, checks)
+		return self.VerificationScore{Score: 0.25, Reasons: []string{"dob:" + discloseOutput.DateOfBirth}}
+	})
+	config := createTestVerificationConfig()
+	config.Ofac = true
+
+	result, err := verifyOffline(t, config, testPublicSignals, scoring)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := &self.VerificationScore{Score: 0.25, Reasons: []string{"dob:" + result.DiscloseOutput.DateOfBirth}}
+	if !reflect.DeepEqual(result.Score, expected) {
+		t.Errorf("Expected score %+v, got %+v", expected, result.Score)
+	}
+	valid := self.IsValidDetails{IsValid: true, IsMinimumAgeValid: true, IsOfacValid: true}
+	if result.IsValidDetails != valid || len(scored) != 1 || scored[0] != valid {
+		t.Errorf("Expected the score to leave the valid checks unchanged, got %+v scored from %+v", result.IsValidDetails, scored)
+	}
+
+	// Results of proofs the chain rejects are not scored, and stay invalid
+	scored = nil
+	result, err = verifyOffline(t, config, testPublicSignals, scoring, self.WithRPCURL(newTestChain(t, false)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Score != nil || len(scored) != 0 {
+		t.Errorf("Expected no score for an invalid result, got %+v after %d calls", result.Score, len(scored))
+	}
+	if result.IsValidDetails.IsValid {
+		t.Errorf("Expected the result to stay invalid, got %+v", result.IsValidDetails)
+	}
+}
@@ -0,0 +1,92 @@
+package selfBackendVerifier
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func TestVerify_RequireSecureContextForDisclosure(t *testing.T) {
+	const pilotId self.AttestationId = 99
+	newVerifier := func(opts ...self.VerifierOption) *self.BackendVerifier {
+		verifier, err := self.NewBackendVerifier(
+			"self-playground",
+			"https://playground.self.xyz/api/verify",
+			false,
+			map[self.AttestationId]bool{pilotId: true},
+			createTestMockConfigStore(createTestVerificationConfig()),
+			self.UserIDTypeUUID,
+			opts...,
+		)
+		if err != nil {
+			t.Fatalf("Failed to create verifier: %v", err)
+		}
+		holder := holderAttestationVerifier{
+			output: self.GenericDiscloseOutput{Name: "DUPONT ALPHONSE", DateOfBirth: "310101", MinimumAge: "18", Nullifier: "1"},
+		}
+		if err := verifier.RegisterAttestationVerifier(pilotId, holder); err != nil {
+			t.Fatalf("Failed to register attestation verifier: %v", err)
+		}
+		return verifier
+	}
+	verify := func(verifier *self.BackendVerifier, ctx context.Context) *self.VerificationResult {
+		result, err := verifier.Verify(ctx, int(pilotId), testProof, testPublicSignals, createTestUserContextData())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return result
+	}
+
+	// Disclosed data is returned whatever the context by default
+	if result := verify(newVerifier(), context.Background()); result.DiscloseOutput.Name == "" || result.DisclosureWithheld {
+		t.Errorf("Expected disclosed data without the option, got %+v", result.DiscloseOutput)
+	}
+
+	verifier := newVerifier(self.WithRequireSecureContextForDisclosure())
+	result := verify(verifier, context.Background())
+	output := result.DiscloseOutput
+	if !result.DisclosureWithheld || output.Name != "" || output.DateOfBirth != "" {
+		t.Errorf("Expected disclosed data to be withheld from an insecure context, got %+v", output)
+	}
+	if !result.IsValidDetails.IsValid || output.MinimumAge != "18" || output.Nullifier != "1" {
+		t.Errorf("Expected validity and predicates to be kept, got %+v", result)
+	}
+
+	result = verify(verifier, self.ContextWithSecureTransport(context.Background()))
+	if result.DisclosureWithheld || result.DiscloseOutput.Name != "DUPONT ALPHONSE" {
+		t.Errorf("Expected disclosed data in a secure context, got %+v", result.DiscloseOutput)
+	}
+}
+
+func TestSecureTransportMiddleware(t *testing.T) {
+	serve := func(trustForwardedProto bool, r *http.Request) bool {
+		var secure bool
+		handler := self.SecureTransportMiddleware(trustForwardedProto)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			secure = self.IsSecureTransport(r.Context())
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+		return secure
+	}
+
+	if serve(false, httptest.NewRequest(http.MethodPost, "/api/verify", nil)) {
+		t.Error("Expected a plaintext request not to be marked secure")
+	}
+	tlsRequest := httptest.NewRequest(http.MethodPost, "/api/verify", nil)
+	tlsRequest.TLS = &tls.ConnectionState{}
+	if !serve(false, tlsRequest) {
+		t.Error("Expected a TLS request to be marked secure")
+	}
+
+	forwarded := httptest.NewRequest(http.MethodPost, "/api/verify", nil)
+	forwarded.Header.Set("X-Forwarded-Proto", "https")
+	if serve(false, forwarded) {
+		t.Error("Expected X-Forwarded-Proto to be ignored unless trusted")
+	}
+	if !serve(true, forwarded) {
+		t.Error("Expected a trusted X-Forwarded-Proto of https to mark the request secure")
+	}
+}
@@ -3,6 +3,7 @@ package selfBackendVerifier
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/big"
 	"strings"
@@ -149,6 +150,22 @@ func extractUserDefinedDataFromContextData(userContextData string) string {
 	return string(userDefinedDataBytes)
 }
 
+// Helper function to create a mock config store resolving the test userContextData (UUID user IDs) to config
+func createTestMockConfigStore(config self.VerificationConfig) *MockConfigStore {
+	userContextData := createTestUserContextData()
+	userIdentifierBigInt := new(big.Int)
+	userIdentifierBigInt.SetString(userContextData[64:128], 16)
+
+	return &MockConfigStore{
+		configs: map[string]self.VerificationConfig{
+			"test-config-id": config,
+		},
+		actionIds: map[string]string{
+			castToUUID(userIdentifierBigInt) + userContextData[128:]: "test-config-id",
+		},
+	}
+}
+
 func TestSelfBackendVerifier_Verify_WithUUIDUserIDType(t *testing.T) {
 	userContextData := createTestUserContextData()
 
@@ -278,3 +295,25 @@ func TestUserContextHashValidation(t *testing.T) {
 		}
 	}
 }
+
+func TestNewBackendVerifier_EmptyAllowedIds(t *testing.T) {
+	for name, allowedIds := range map[string]map[self.AttestationId]bool{
+		"nil":        nil,
+		"empty":      {},
+		"disallowed": {self.Passport: false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := self.NewBackendVerifier(
+				"self-playground",
+				"https://playground.self.xyz/api/verify",
+				false,
+				allowedIds,
+				createTestMockConfigStore(createTestVerificationConfig()),
+				self.UserIDTypeUUID,
+			)
+			if !errors.Is(err, self.ErrAttestationNotAllowed) {
+				t.Errorf("Expected ErrAttestationNotAllowed for %s allowedIds, got %v", name, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package selfBackendVerifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func TestSelfTest(t *testing.T) {
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{self.Passport: true},
+		createTestMockConfigStore(createTestVerificationConfig()),
+		self.UserIDTypeUUID,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+
+	// Without network access the registry cannot be reached; with it, the bundled proof verifies
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := verifier.SelfTest(ctx); err != nil && !errors.Is(err, self.ErrSelfTestFailed) {
+		t.Errorf("Expected nil or ErrSelfTestFailed, got %v", err)
+	}
+}
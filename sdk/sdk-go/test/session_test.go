@@ -0,0 +1,50 @@
+package selfBackendVerifier
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func TestVerify_SessionTimestamp(t *testing.T) {
+	session := func(start time.Time, err error) self.VerifierOption {
+		return self.WithSessionTimestamp(func(userDefinedData string) (time.Time, error) {
+			return start, err
+		})
+	}
+
+	// The test proof was generated on 2025-08-12, so a session started later that day accepts it
+	result, err := verifyOffline(t, createTestVerificationConfig(), testPublicSignals,
+		session(time.Date(2025, 8, 12, 18, 0, 0, 0, time.UTC), nil))
+	if err != nil || !result.IsValidDetails.IsValid {
+		t.Errorf("Expected a proof from the session day to pass, got %+v, %v", result, err)
+	}
+
+	_, err = verifyOffline(t, createTestVerificationConfig(), testPublicSignals,
+		session(time.Date(2025, 8, 13, 0, 0, 0, 0, time.UTC), nil))
+	if !errors.Is(err, self.ErrProofPredatesSession) || !errors.Is(err, self.ErrInvalidTimestamp) {
+		t.Errorf("Expected ErrProofPredatesSession, got %v", err)
+	}
+
+	_, err = verifyOffline(t, createTestVerificationConfig(), testPublicSignals, session(time.Time{}, errors.New("no timestamp")))
+	if !errors.Is(err, self.ErrProofPredatesSession) {
+		t.Errorf("Expected a missing session timestamp to fail, got %v", err)
+	}
+}
+
+func TestSessionTimestampEncoding(t *testing.T) {
+	start := time.Date(2025, 8, 12, 9, 30, 0, 0, time.UTC)
+	encoded := self.EncodeSessionTimestamp(start)
+	if len(encoded) != 2*self.SessionTimestampSize {
+		t.Fatalf("Expected %d hex characters, got %q", 2*self.SessionTimestampSize, encoded)
+	}
+	parsed, err := self.ParseSessionTimestamp(encoded + "deadbeef")
+	if err != nil || !parsed.Equal(start) {
+		t.Errorf("Expected %v, got %v, %v", start, parsed, err)
+	}
+	if _, err := self.ParseSessionTimestamp("abcd"); err == nil {
+		t.Error("Expected user defined data without a timestamp to fail")
+	}
+}
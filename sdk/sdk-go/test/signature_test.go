@@ -0,0 +1,86 @@
+package selfBackendVerifier
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func TestContextDataVerifier_Ed25519(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	verifier := self.ContextDataVerifier{Algorithm: self.SignatureAlgorithmEd25519, PublicKey: publicKey}
+
+	message, err := hex.DecodeString(createTestUserContextData())
+	if err != nil {
+		t.Fatalf("Failed to decode user context data: %v", err)
+	}
+	signed := append(append([]byte{}, message...), ed25519.Sign(privateKey, message)...)
+	if err := verifier.Verify(signed); err != nil {
+		t.Errorf("Expected valid signature to pass, got %v", err)
+	}
+
+	signed[70] ^= 0xff
+	if err := verifier.Verify(signed); !errors.Is(err, self.ErrContextDataSignatureInvalid) {
+		t.Errorf("Expected ErrContextDataSignatureInvalid for tampered data, got %v", err)
+	}
+	if err := verifier.Verify(message[:64]); !errors.Is(err, self.ErrContextDataSignatureInvalid) {
+		t.Errorf("Expected ErrContextDataSignatureInvalid without a signature, got %v", err)
+	}
+}
+
+func TestContextDataVerifier_ECDSAP256(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	verifier := self.ContextDataVerifier{Algorithm: self.SignatureAlgorithmECDSAP256, PublicKey: &privateKey.PublicKey}
+
+	message, err := hex.DecodeString(createTestUserContextData())
+	if err != nil {
+		t.Fatalf("Failed to decode user context data: %v", err)
+	}
+	digest := sha256.Sum256(message)
+	r, s, err := ecdsa.Sign(rand.Reader, privateKey, digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+	if err := verifier.Verify(append(message, signature...)); err != nil {
+		t.Errorf("Expected valid signature to pass, got %v", err)
+	}
+
+	wrongKey := self.ContextDataVerifier{Algorithm: self.SignatureAlgorithmEd25519, PublicKey: &privateKey.PublicKey}
+	if err := wrongKey.Verify(append(message, signature...)); !errors.Is(err, self.ErrContextDataSignatureInvalid) {
+		t.Errorf("Expected ErrContextDataSignatureInvalid for a mismatched key type, got %v", err)
+	}
+}
+
+func TestVerify_ContextDataSignature(t *testing.T) {
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	config := createTestVerificationConfig()
+	if err := verifyTestProofWithOptions(t, config); errors.Is(err, self.ErrContextDataSignatureInvalid) {
+		t.Errorf("Expected no signature check without a ContextDataVerifier, got %v", err)
+	}
+
+	// The test user context data is not signed
+	verifier := self.ContextDataVerifier{Algorithm: self.SignatureAlgorithmEd25519, PublicKey: publicKey}
+	err = verifyTestProofWithOptions(t, config, self.WithContextDataVerifier(verifier))
+	if !errors.Is(err, self.ErrContextDataSignatureInvalid) {
+		t.Errorf("Expected ErrContextDataSignatureInvalid, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package selfBackendVerifier
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+// newTestChain starts a JSON-RPC server standing in for the Celo node: the hub resolves every
+// attestation type to a verifier contract, which reports every proof as proofValid
+func newTestChain(t *testing.T, proofValid bool) string {
+	t.Helper()
+	verifierAddress := "0x" + strings.Repeat("0", 62) + "aa"
+	proofResult := "0x" + strings.Repeat("0", 64)
+	if proofValid {
+		proofResult = "0x" + strings.Repeat("0", 63) + "1"
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		var call struct {
+			To string `json:"to"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Method != "eth_call" || len(request.Params) == 0 {
+			http.Error(w, "unsupported request", http.StatusBadRequest)
+			return
+		}
+		json.Unmarshal(request.Params[0], &call)
+		result := proofResult
+		if strings.EqualFold(call.To, self.IDENTITY_VERIFICATION_HUB_ADDRESS) {
+			result = verifierAddress
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": request.ID, "result": result})
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+// offlineTestOptions makes a verifier accept the passport test proof without network access: proofs
+// are checked against a test chain, the proof's root is pinned and its age accepted
+func offlineTestOptions(t *testing.T) []self.VerifierOption {
+	t.Helper()
+	proofRoot, _ := new(big.Int).SetString(testPublicSignals[self.DiscloseIndices[self.Passport].MerkleRootIndex], 10)
+	return []self.VerifierOption{
+		self.WithRPCURL(newTestChain(t, true)),
+		self.WithPinnedRoots(self.Passport, self.PinnedRoot{Root: proofRoot}),
+		self.WithMaxProofAge(100 * 365 * 24 * time.Hour),
+	}
+}
+
+// newOfflineTestVerifier creates a verifier for the test config store holding config that accepts the
+// passport test proof, see offlineTestOptions. opts are applied after the offline options.
+func newOfflineTestVerifier(t *testing.T, config self.VerificationConfig, opts ...self.VerifierOption) *self.BackendVerifier {
+	t.Helper()
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{self.Passport: true},
+		createTestMockConfigStore(config),
+		self.UserIDTypeUUID,
+		append(offlineTestOptions(t), opts...)...,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	return verifier
+}
+
+// verifyOffline verifies the passport test proof with signals on a verifier from newOfflineTestVerifier
+func verifyOffline(t *testing.T, config self.VerificationConfig, signals []string, opts ...self.VerifierOption) (*self.VerificationResult, error) {
+	t.Helper()
+	return newOfflineTestVerifier(t, config, opts...).Verify(context.Background(), 1, testProof, signals, createTestUserContextData())
+}
+
+func verifyTestProofWithOptions(t *testing.T, config self.VerificationConfig, opts ...self.VerifierOption) error {
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{self.Passport: true},
+		createTestMockConfigStore(config),
+		self.UserIDTypeUUID,
+		opts...,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	_, err = verifier.Verify(context.Background(), 1, testProof, testPublicSignals, createTestUserContextData())
+	return err
+}
+
+// withRevealedData returns a copy of the passport test signals with the revealed bytes from offset
+// replaced by value. The test chain accepts the modified proof, so only the policy decides.
+func withRevealedData(offset int, value string) []string {
+	return revealData(testPublicSignals, offset, value)
+}
+
+// revealData returns a copy of the passport signals with the revealed bytes from offset replaced by value
+func revealData(publicSignals []string, offset int, value string) []string {
+	signals := append([]string(nil), publicSignals...)
+	start := self.DiscloseIndices[self.Passport].RevealedDataPackedIndex
+	byteIndex := 0
+	for i, count := range self.BytesCount[self.Passport] {
+		packed, _ := new(big.Int).SetString(signals[start+i], 10)
+		for j := 0; j < count; j++ {
+			if k := byteIndex - offset; k >= 0 && k < len(value) {
+				shift := uint(8 * j)
+				packed.AndNot(packed, new(big.Int).Lsh(big.NewInt(0xff), shift))
+				packed.Or(packed, new(big.Int).Lsh(big.NewInt(int64(value[k])), shift))
+			}
+			byteIndex++
+		}
+		signals[start+i] = packed.String()
+	}
+	return signals
+}
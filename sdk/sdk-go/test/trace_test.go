@@ -0,0 +1,108 @@
+package selfBackendVerifier
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+	common "github.com/selfxyz/self/sdk/sdk-go/common"
+)
+
+// memoryTraceSink keeps recorded traces in memory
+type memoryTraceSink struct {
+	traces []self.VerificationTrace
+}
+
+func (sink *memoryTraceSink) RecordTrace(ctx context.Context, trace self.VerificationTrace) error {
+	sink.traces = append(sink.traces, trace)
+	return nil
+}
+
+func TestVerificationTrace_RecordAndReplay(t *testing.T) {
+	sink := &memoryTraceSink{}
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{self.Passport: true},
+		createTestMockConfigStore(createTestVerificationConfig()),
+		self.UserIDTypeUUID,
+		self.WithTraceSink(sink),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+
+	// Verification fails offline, so a trace is recorded
+	if _, err := verifier.Verify(context.Background(), 1, testProof, testPublicSignals, createTestUserContextData()); err == nil {
+		t.Fatal("Expected verification to fail offline")
+	}
+	if len(sink.traces) != 1 {
+		t.Fatalf("Expected 1 trace, got %d", len(sink.traces))
+	}
+
+	trace := sink.traces[0]
+	if trace.ConfigId != "test-config-id" || trace.ConfigHash != createTestVerificationConfig().Hash() {
+		t.Errorf("Expected the resolved config to be traced, got %q %q", trace.ConfigId, trace.ConfigHash)
+	}
+	for i := 0; i < 3; i++ {
+		if trace.PublicSignals[i] != "0" {
+			t.Errorf("Expected revealed data signal %d to be redacted, got %s", i, trace.PublicSignals[i])
+		}
+	}
+
+	issues, err := verifier.ReplayTrace(trace)
+	if err != nil {
+		t.Fatalf("Unexpected replay error: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("Expected no scope or signal issues on replay, got %+v", issues)
+	}
+
+	// Replaying against a verifier with another scope pinpoints the mismatch
+	other, err := self.NewBackendVerifier(
+		"another-app",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{self.Passport: true},
+		createTestMockConfigStore(createTestVerificationConfig()),
+		self.UserIDTypeUUID,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	issues, err = other.ReplayTrace(trace)
+	if err != nil || len(issues) != 1 || issues[0].Type != self.InvalidScope {
+		t.Errorf("Expected a single scope issue, got %+v (%v)", issues, err)
+	}
+}
+
+func TestVerificationTrace_OmitsIssueMessages(t *testing.T) {
+	sink := &memoryTraceSink{}
+	config := createTestVerificationConfig()
+	config.SanctionedNationalities = []common.Country3LetterCode{common.IRN}
+	signals := withRevealedData(self.RevealedDataIndices[self.Passport].NationalityStart, "IRN")
+
+	if _, err := verifyOffline(t, config, signals, self.WithTraceSink(sink)); err == nil {
+		t.Fatal("Expected a sanctioned nationality to fail verification")
+	}
+	if len(sink.traces) != 1 {
+		t.Fatalf("Expected 1 trace, got %d", len(sink.traces))
+	}
+
+	trace := sink.traces[0]
+	expected := []self.ConfigMismatch{self.InvalidSanctionedNationality}
+	if !reflect.DeepEqual(trace.Issues, expected) || trace.ErrorCode != self.ErrorCodeFor(self.ErrSanctionedNationality) {
+		t.Errorf("Expected issues %v with code %s, got %v with %s", expected, self.ErrorCodeFor(self.ErrSanctionedNationality), trace.Issues, trace.ErrorCode)
+	}
+	encoded, err := json.Marshal(trace)
+	if err != nil {
+		t.Fatalf("Failed to encode trace: %v", err)
+	}
+	if strings.Contains(string(encoded), "IRN") {
+		t.Errorf("Expected the trace not to reveal the nationality, got %s", encoded)
+	}
+}
@@ -0,0 +1,41 @@
+package selfBackendVerifier
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func TestRedactUserContextData(t *testing.T) {
+	userContextData := createTestUserContextData()
+	redacted := self.RedactUserContextData(userContextData)
+	if !regexp.MustCompile(`^redacted:[0-9a-f]{12}$`).MatchString(redacted) {
+		t.Fatalf("Expected redacted:<12 hex characters>, got %q", redacted)
+	}
+
+	// The same data always redacts the same way, so log lines can be correlated
+	if again := self.RedactUserContextData(userContextData); again != redacted {
+		t.Errorf("Expected a stable redaction, got %q and %q", redacted, again)
+	}
+	other := userContextData[:len(userContextData)-1] + "0"
+	if other == userContextData {
+		other = userContextData[:len(userContextData)-1] + "1"
+	}
+	if self.RedactUserContextData(other) == redacted {
+		t.Errorf("Expected different data to redact differently, got %q for both", redacted)
+	}
+
+	// Nothing of the user identifier or user defined data is kept
+	hash := strings.TrimPrefix(redacted, "redacted:")
+	for _, part := range []string{userContextData[64:128], userContextData[128:]} {
+		if strings.Contains(part, hash) || strings.Contains(redacted, part) {
+			t.Errorf("Expected %q not to reveal %q", redacted, part)
+		}
+	}
+
+	if got := self.RedactUserContextData(""); got != "" {
+		t.Errorf("Expected empty data to stay empty, got %q", got)
+	}
+}
@@ -0,0 +1,70 @@
+package selfBackendVerifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func TestVerifierPool_RejectsWhenFull(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	store := self.NewInMemoryConfigStore(func(ctx context.Context, userIdentifier string, userDefinedData string) (string, error) {
+		started <- struct{}{}
+		<-release
+		return "", nil
+	})
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{self.Passport: true},
+		store,
+		self.UserIDTypeUUID,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+
+	pool, err := self.NewVerifierPool(verifier, self.PoolConfig{Workers: 1, QueueSize: 1})
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+
+	verify := func() error {
+		_, err := pool.Verify(context.Background(), 1, testProof, testPublicSignals, createTestUserContextData())
+		return err
+	}
+
+	// Occupy the worker, then fill the queue
+	errs := make(chan error, 2)
+	go func() { errs <- verify() }()
+	<-started
+	go func() { errs <- verify() }()
+	for pool.Stats().QueueDepth != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := verify(); !errors.Is(err, self.ErrPoolFull) {
+		t.Errorf("Expected ErrPoolFull, got %v", err)
+	}
+	stats := pool.Stats()
+	if stats.Active != 1 || stats.Rejected != 1 {
+		t.Errorf("Expected 1 active and 1 rejected verification, got %+v", stats)
+	}
+
+	close(release)
+	for i := 0; i < 2; i++ {
+		if err := <-errs; errors.Is(err, self.ErrPoolFull) {
+			t.Errorf("Expected queued verification to run, got %v", err)
+		}
+	}
+
+	pool.Close()
+	if err := verify(); !errors.Is(err, self.ErrPoolClosed) {
+		t.Errorf("Expected ErrPoolClosed after Close, got %v", err)
+	}
+}
@@ -0,0 +1,324 @@
+package selfBackendVerifier
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func newTestVerifyHandler(t *testing.T, allowedIds map[self.AttestationId]bool) *self.VerifyHandler {
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		allowedIds,
+		self.NewDefaultConfigStore(createTestVerificationConfig()),
+		self.UserIDTypeUUID,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	return self.NewVerifyHandler(verifier)
+}
+
+func decodeErrorResponse(t *testing.T, recorder *httptest.ResponseRecorder) self.ErrorResponse {
+	var body self.ErrorResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode error response: %v", err)
+	}
+	return body
+}
+
+func TestVerifyHandler_InvalidBody(t *testing.T) {
+	handler := newTestVerifyHandler(t, map[self.AttestationId]bool{self.Passport: true})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/verify", strings.NewReader("{not json")))
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, recorder.Code)
+	}
+	if body := decodeErrorResponse(t, recorder); body.Code != self.ErrorCodeInvalidRequest {
+		t.Errorf("Expected code %s, got %s", self.ErrorCodeInvalidRequest, body.Code)
+	}
+}
+
+func TestVerifyHandler_AttestationNotAllowed(t *testing.T) {
+	handler := newTestVerifyHandler(t, map[self.AttestationId]bool{self.EUCard: true})
+
+	input, _ := json.Marshal(self.VerifyInput{
+		AttestationId:   int(self.Passport),
+		Proof:           testProof,
+		PublicSignals:   testPublicSignals,
+		UserContextData: createTestUserContextData(),
+	})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/verify", strings.NewReader(string(input))))
+
+	// The request is well-formed, so the failed verification is not a client or server error
+	if recorder.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status %d, got %d", http.StatusUnprocessableEntity, recorder.Code)
+	}
+	body := decodeErrorResponse(t, recorder)
+	if body.Code != self.ErrorCodeAttestationNotAllowed {
+		t.Errorf("Expected code %s, got %s", self.ErrorCodeAttestationNotAllowed, body.Code)
+	}
+	if body.Result {
+		t.Errorf("Expected result false")
+	}
+}
+
+func TestErrorCodeFor(t *testing.T) {
+	err := self.NewConfigMismatchError([]self.ConfigIssue{
+		{Type: self.InvalidMinimumAge, Message: "age"},
+		{Type: self.InvalidScope, Message: "scope"},
+	})
+
+	if !errors.Is(err, self.ErrMinimumAgeNotMet) || !errors.Is(err, self.ErrScopeMismatch) {
+		t.Fatalf("Expected ConfigMismatchError to match the errors of its issues")
+	}
+	if code := self.ErrorCodeFor(err); code != self.ErrorCodeScopeMismatch {
+		t.Errorf("Expected code %s, got %s", self.ErrorCodeScopeMismatch, code)
+	}
+	if code := self.ErrorCodeFor(errors.New("boom")); code != self.ErrorCodeInternal {
+		t.Errorf("Expected code %s, got %s", self.ErrorCodeInternal, code)
+	}
+}
+
+func TestVerifyHandler_ReportsAllFieldErrors(t *testing.T) {
+	handler := newTestVerifyHandler(t, map[self.AttestationId]bool{self.Passport: true})
+
+	body := `{"attestationId": "1", "publicSignals": [], "userContextData": "not-hex"}`
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/verify", strings.NewReader(body)))
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, recorder.Code)
+	}
+	response := decodeErrorResponse(t, recorder)
+	if response.Code != self.ErrorCodeInvalidRequest {
+		t.Errorf("Expected code %s, got %s", self.ErrorCodeInvalidRequest, response.Code)
+	}
+
+	fields := make(map[string]bool)
+	for _, fieldErr := range response.Errors {
+		fields[fieldErr.Field] = true
+	}
+	for _, field := range []string{"attestationId", "proof", "publicSignals", "userContextData"} {
+		if !fields[field] {
+			t.Errorf("Expected an error for %s, got %+v", field, response.Errors)
+		}
+	}
+}
+
+func TestVerifyHandler_SubjectNaming(t *testing.T) {
+	const pilotId self.AttestationId = 99
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{pilotId: true},
+		createTestMockConfigStore(createTestVerificationConfig()),
+		self.UserIDTypeUUID,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	if err := verifier.RegisterAttestationVerifier(pilotId, staticAttestationVerifier{}); err != nil {
+		t.Fatalf("Failed to register attestation verifier: %v", err)
+	}
+	input, _ := json.Marshal(self.VerifyInput{
+		AttestationId:   int(pilotId),
+		Proof:           testProof,
+		PublicSignals:   testPublicSignals,
+		UserContextData: createTestUserContextData(),
+	})
+
+	for _, tt := range []struct {
+		naming self.FieldNaming
+		key    string
+	}{
+		{self.CamelCaseNaming(), "dateOfBirth"},
+		{self.SnakeCaseNaming(), "date_of_birth"},
+		{self.FieldNaming{"dateOfBirth": "dob"}, "dob"},
+	} {
+		handler := self.NewVerifyHandler(verifier)
+		handler.SubjectNaming = tt.naming
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/verify", strings.NewReader(string(input))))
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body)
+		}
+
+		var body struct {
+			CredentialSubject map[string]string `json:"credentialSubject"`
+		}
+		if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if body.CredentialSubject[tt.key] != "980327" || len(body.CredentialSubject) != 1 {
+			t.Errorf("Expected credential subject keyed %q, got %v", tt.key, body.CredentialSubject)
+		}
+	}
+}
+
+func TestHTTPStatusFor(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{nil, http.StatusOK},
+		{self.ErrInvalidRequest, http.StatusBadRequest},
+		{self.ErrMinimumAgeNotMet, http.StatusUnprocessableEntity},
+		{self.ErrInvalidProof, http.StatusUnprocessableEntity},
+		{self.ErrVerificationTimeout, http.StatusGatewayTimeout},
+		{errors.New("rpc unavailable"), http.StatusInternalServerError},
+	}
+	for _, test := range tests {
+		if status := self.HTTPStatusFor(test.err); status != test.status {
+			t.Errorf("HTTPStatusFor(%v) = %d, expected %d", test.err, status, test.status)
+		}
+	}
+
+	// An invalid result without an error is a failed verification, not a server error
+	status, body := self.NewVerifyHTTPResponse(nil, nil, nil)
+	response, ok := body.(self.ErrorResponse)
+	if status != http.StatusUnprocessableEntity || !ok || response.Code != self.ErrorCodeInvalidProof || response.Result {
+		t.Errorf("Expected a %d INVALID_PROOF response, got %d %+v", http.StatusUnprocessableEntity, status, body)
+	}
+}
+
+func TestWriteVerifyResponse(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   self.ErrorCode
+	}{
+		{nil, http.StatusUnprocessableEntity, self.ErrorCodeInvalidProof},
+		{self.ErrMinimumAgeNotMet, http.StatusUnprocessableEntity, self.ErrorCodeAgeNotMet},
+		{self.ErrVerificationTimeout, http.StatusGatewayTimeout, self.ErrorCodeVerificationTimeout},
+		{errors.New("rpc unavailable"), http.StatusInternalServerError, self.ErrorCodeInternal},
+	}
+	for _, test := range tests {
+		recorder := httptest.NewRecorder()
+		self.WriteVerifyResponse(recorder, nil, test.err)
+		if recorder.Code != test.status || recorder.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("WriteVerifyResponse(%v): expected a JSON %d, got %d %q", test.err, test.status, recorder.Code, recorder.Header().Get("Content-Type"))
+		}
+		if body := decodeErrorResponse(t, recorder); body.Code != test.code || body.Result {
+			t.Errorf("WriteVerifyResponse(%v): expected code %s with result false, got %+v", test.err, test.code, body)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	self.WriteVerifyResponse(recorder, &self.VerificationResult{
+		AttestationId:  self.Passport,
+		IsValidDetails: self.IsValidDetails{IsValid: true, IsMinimumAgeValid: true},
+	}, nil)
+	var body self.VerifyResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode verify response: %v", err)
+	}
+	if recorder.Code != http.StatusOK || body.Status != "success" || !body.Result || !body.Details.IsValid {
+		t.Errorf("Expected a 200 success response, got %d %+v", recorder.Code, body)
+	}
+}
+
+func TestDecodeVerifyInputWithLimits(t *testing.T) {
+	valid, _ := json.Marshal(self.VerifyInput{
+		AttestationId:   int(self.Passport),
+		Proof:           testProof,
+		PublicSignals:   testPublicSignals,
+		UserContextData: createTestUserContextData(),
+	})
+	if _, err := self.DecodeVerifyInput(strings.NewReader(string(valid))); err != nil {
+		t.Fatalf("Expected a valid input to decode, got %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		limits  self.DecodeLimits
+		message string
+	}{
+		{"truncated", string(valid[:len(valid)/2]), self.DecodeLimits{}, "is truncated"},
+		{"too deep", `{"extra":` + strings.Repeat("[", 20) + strings.Repeat("]", 20) + `}`, self.DecodeLimits{}, "nested at most"},
+		{"too large", string(valid), self.DecodeLimits{MaxBytes: 256}, "at most 256 bytes"},
+		{"brackets in strings", `{"userContextData":"` + strings.Repeat("[", 20) + `"}`, self.DecodeLimits{}, ""},
+	}
+	for _, test := range tests {
+		_, err := self.DecodeVerifyInputWithLimits(strings.NewReader(test.body), test.limits)
+		var validationErr *self.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("%s: expected a *ValidationError, got %v", test.name, err)
+		}
+		bodyErr := validationErr.Errors[0].Field == "body"
+		if test.message == "" && bodyErr {
+			t.Errorf("%s: expected the body to parse, got %v", test.name, err)
+		}
+		if test.message != "" && (!bodyErr || !strings.Contains(validationErr.Errors[0].Message, test.message)) {
+			t.Errorf("%s: expected a body error containing %q, got %v", test.name, test.message, err)
+		}
+	}
+}
+
+func TestVerifyHandler_GzipBody(t *testing.T) {
+	handler := newTestVerifyHandler(t, map[self.AttestationId]bool{self.EUCard: true})
+	handler.MaxBodyBytes = 64 << 10
+	post := func(body []byte, encoding string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/api/verify", bytes.NewReader(body))
+		request.Header.Set("Content-Encoding", encoding)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+	compress := func(data []byte) []byte {
+		var compressed bytes.Buffer
+		writer := gzip.NewWriter(&compressed)
+		writer.Write(data)
+		writer.Close()
+		return compressed.Bytes()
+	}
+
+	input, _ := json.Marshal(self.VerifyInput{
+		AttestationId:   int(self.Passport),
+		Proof:           testProof,
+		PublicSignals:   testPublicSignals,
+		UserContextData: createTestUserContextData(),
+	})
+	// The compressed input decodes, so verification runs and rejects the attestation type
+	recorder := post(compress(input), "gzip")
+	if body := decodeErrorResponse(t, recorder); recorder.Code != http.StatusUnprocessableEntity || body.Code != self.ErrorCodeAttestationNotAllowed {
+		t.Errorf("Expected the gzip body to be verified, got %d %+v", recorder.Code, body)
+	}
+
+	tests := []struct {
+		name    string
+		body    []byte
+		message string
+	}{
+		{"malformed", []byte("not gzip at all"), "is not valid gzip"},
+		{"corrupted", append(compress(input)[:40], bytes.Repeat([]byte{0xff}, 40)...), "is not valid gzip"},
+		{"bomb", compress(append(append([]byte(`{"extra":"`), bytes.Repeat([]byte("a"), 1<<20)...), '"', '}')), "at most 65536 bytes"},
+	}
+	for _, test := range tests {
+		recorder := post(test.body, "gzip")
+		body := decodeErrorResponse(t, recorder)
+		if recorder.Code != http.StatusBadRequest || len(body.Errors) != 1 || !strings.Contains(body.Errors[0].Message, test.message) {
+			t.Errorf("%s: expected a body error containing %q, got %d %+v", test.name, test.message, recorder.Code, body)
+		}
+	}
+
+	if recorder := post(input, "br"); recorder.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected status %d for an unsupported encoding, got %d", http.StatusUnsupportedMediaType, recorder.Code)
+	}
+}
@@ -0,0 +1,44 @@
+package selfBackendVerifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	self "github.com/selfxyz/self/sdk/sdk-go"
+)
+
+func TestVerify_Timeout(t *testing.T) {
+	err := verifyTestProofWithOptions(t, createTestVerificationConfig(), self.WithVerifyTimeout(time.Nanosecond))
+	if !errors.Is(err, self.ErrVerificationTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected ErrVerificationTimeout, got %v", err)
+	}
+	if code := self.ErrorCodeFor(err); code != self.ErrorCodeVerificationTimeout {
+		t.Errorf("Expected code %s, got %s", self.ErrorCodeVerificationTimeout, code)
+	}
+}
+
+func TestVerify_ContextDeadlineOverridesTimeout(t *testing.T) {
+	verifier, err := self.NewBackendVerifier(
+		"self-playground",
+		"https://playground.self.xyz/api/verify",
+		false,
+		map[self.AttestationId]bool{self.Passport: true},
+		createTestMockConfigStore(createTestVerificationConfig()),
+		self.UserIDTypeUUID,
+		self.WithVerifyTimeout(time.Nanosecond),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+
+	// A longer per-request deadline replaces the verifier timeout, so the verification fails on its
+	// own merits (the proof cannot be checked offline) rather than timing out
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	_, err = verifier.Verify(ctx, 1, testProof, testPublicSignals, createTestUserContextData())
+	if errors.Is(err, self.ErrVerificationTimeout) {
+		t.Errorf("Expected the context deadline to override the verifier timeout, got %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+package self
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// VerificationTrace captures a failed verification for replay with ReplayTrace, without PII:
+// the proof is hashed, userContextData is redacted, the revealed data signals are zeroed and the
+// failure is kept as issue types and an error code, since issue messages can carry disclosed data.
+type VerificationTrace struct {
+	Timestamp       time.Time        `json:"timestamp"`
+	AttestationId   AttestationId    `json:"attestationId"`
+	ProofHash       string           `json:"proofHash"`
+	PublicSignals   []string         `json:"publicSignals"`
+	UserContextData string           `json:"userContextData,omitempty"` // Redacted with RedactUserContextData
+	UserContextHash string           `json:"userContextHash,omitempty"` // Hash the proof must commit to
+	ConfigId        string           `json:"configId,omitempty"`
+	Scope           string           `json:"scope,omitempty"` // Expected scope, when not the verifier's own
+	ConfigHash      string           `json:"configHash,omitempty"`
+	Issues          []ConfigMismatch `json:"issues,omitempty"`
+	ErrorCode       ErrorCode        `json:"errorCode"`
+}
+
+// TraceSink persists verification traces
+type TraceSink interface {
+	// RecordTrace stores a single verification trace
+	RecordTrace(ctx context.Context, trace VerificationTrace) error
+}
+
+// WithTraceSink records a VerificationTrace to sink for every failed verification
+func WithTraceSink(sink TraceSink) VerifierOption {
+	return func(s *BackendVerifier) {
+		s.traceSink = sink
+	}
+}
+
+// recordTrace builds the trace of a failed verification and writes it to the trace sink.
+// Sink failures are logged and never change the verification outcome.
+func (s *BackendVerifier) recordTrace(
+	ctx context.Context,
+	attestationId AttestationId,
+	proof VcAndDiscloseProof,
+	pubSignals []string,
+	userContextData string,
+	state *verificationState,
+	err error,
+) {
+	if err == nil {
+		err = ErrInvalidProof
+	}
+
+	proofJSON, _ := json.Marshal(proof)
+	proofHash := sha256.Sum256(proofJSON)
+	trace := VerificationTrace{
+		Timestamp:       time.Now().UTC(),
+		AttestationId:   attestationId,
+		ProofHash:       hex.EncodeToString(proofHash[:]),
+		PublicSignals:   s.redactRevealedData(attestationId, CanonicalizePublicSignals(pubSignals)),
+		UserContextData: RedactUserContextData(userContextData),
+		ConfigId:        state.configId,
+		ErrorCode:       ErrorCodeFor(err),
+	}
+	if userContextDataBytes, decodeErr := hex.DecodeString(userContextData); decodeErr == nil {
+		trace.UserContextHash = CalculateUserIdentifierHash(userContextDataBytes)
+	}
+	if state.scope.name != s.scopeName {
+		trace.Scope = state.scope.name
+	}
+	if state.config != nil {
+		trace.ConfigHash = state.config.Hash()
+	}
+	var mismatchErr *ConfigMismatchError
+	if errors.As(err, &mismatchErr) {
+		trace.Issues = issueTypes(mismatchErr.Issues)
+	}
+
+	if recordErr := s.traceSink.RecordTrace(ctx, trace); recordErr != nil {
+		s.logger.Printf("self: failed to record trace for userContextData %s: %v", trace.UserContextData, recordErr)
+	}
+}
+
+// redactRevealedData returns a copy of publicSignals with the signals packing the disclosed attributes zeroed
+func (s *BackendVerifier) redactRevealedData(attestationId AttestationId, publicSignals []string) []string {
+	redacted := make([]string, len(publicSignals))
+	copy(redacted, publicSignals)
+
+	layout, err := s.circuitLayouts.detect(attestationId, publicSignals)
+	if err != nil {
+		return redacted
+	}
+	length, err := GetRevealedDataPublicSignalsLength(attestationId)
+	if err != nil {
+		return redacted
+	}
+	for i := 0; i < length; i++ {
+		if index := layout.Indices.RevealedDataPackedIndex + i; index < len(redacted) {
+			redacted[index] = "0"
+		}
+	}
+	return redacted
+}
+
+// ReplayTrace re-runs the checks of a recorded verification that do not depend on PII or on
+// the time of the original verification against this verifier: attestation ID, signal layout,
+// scope and user context binding. It returns the issues found, empty if the trace passes them.
+//
+// Use it to diagnose the scope and signal mismatches behind a failure; the proof, the onchain
+// root and the config cannot be rechecked from a trace.
+func (s *BackendVerifier) ReplayTrace(trace VerificationTrace) ([]ConfigIssue, error) {
+	var issues []ConfigIssue
+	if !s.allowedIDs[trace.AttestationId] {
+		issues = append(issues, s.attestationNotAllowedIssue(trace.AttestationId))
+	}
+
+	layout, err := s.circuitLayouts.detect(trace.AttestationId, trace.PublicSignals)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidatePublicSignalsOrder(layout, trace.AttestationId, trace.PublicSignals); err != nil {
+		return nil, err
+	}
+	indices := layout.Indices
+
+	if trace.UserContextHash != "" {
+		expected, _ := new(big.Int).SetString(strings.TrimPrefix(trace.UserContextHash, "0x"), 16)
+		inCircuit, _ := new(big.Int).SetString(trace.PublicSignals[indices.UserIdentifierIndex], 10)
+		if expected == nil || inCircuit == nil || expected.Cmp(inCircuit) != 0 {
+			issues = append(issues, ConfigIssue{
+				Type: InvalidUserContextHash,
+				Message: fmt.Sprintf("User context hash does not match with the one in the circuit\nCircuit: %s\nUser context hash: %s",
+					trace.PublicSignals[indices.UserIdentifierIndex], trace.UserContextHash),
+			})
+		}
+	}
+
+	scope, err := s.lookupScope(trace.Scope)
+	if err != nil {
+		return nil, err
+	}
+	if scope.hash != trace.PublicSignals[indices.ScopeIndex] {
+		issues = append(issues, s.scopeMismatchIssue(scope, trace.PublicSignals[indices.ScopeIndex]))
+	}
+
+	if fmt.Sprintf("%d", trace.AttestationId) != trace.PublicSignals[indices.AttestationIdIndex] {
+		issues = append(issues, ConfigIssue{
+			Type: InvalidAttestationId,
+			Message: fmt.Sprintf("Attestation ID does not match with the one in the circuit\nCircuit: %s\nAttestation ID: %d",
+				trace.PublicSignals[indices.AttestationIdIndex], trace.AttestationId),
+		})
+	}
+
+	return issues, nil
+}
+
+// FileTraceSink appends verification traces to a file as JSON lines
+type FileTraceSink struct {
+	file *jsonLinesFile
+}
+
+// Compile-time check to ensure FileTraceSink implements TraceSink interface
+var _ TraceSink = (*FileTraceSink)(nil)
+
+// NewFileTraceSink opens (or creates) the file at path for appending traces
+func NewFileTraceSink(path string) (*FileTraceSink, error) {
+	file, err := openJSONLinesFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trace file: %v", err)
+	}
+	return &FileTraceSink{file: file}, nil
+}
+
+// RecordTrace writes the trace as a single JSON line
+func (sink *FileTraceSink) RecordTrace(ctx context.Context, trace VerificationTrace) error {
+	if err := sink.file.write(trace); err != nil {
+		return fmt.Errorf("failed to write trace: %v", err)
+	}
+	return nil
+}
+
+// Close closes the underlying file
+func (sink *FileTraceSink) Close() error {
+	return sink.file.close()
+}
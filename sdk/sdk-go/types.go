@@ -1,6 +1,8 @@
 package self
 
 import (
+	"time"
+
 	"github.com/selfxyz/self/sdk/sdk-go/common"
 )
 
@@ -19,6 +21,47 @@ type VerificationConfig struct {
 	MinimumAge        int                         `json:"minimumAge,omitempty"`
 	ExcludedCountries []common.Country3LetterCode `json:"excludedCountries,omitempty"`
 	Ofac              bool                        `json:"ofac,omitempty"`
+	OfacMode          OFACMode                    `json:"ofacMode,omitempty"` // Sources that must clear the user when Ofac is set, defaults to OFACModeProof
+	// AdvisoryChecks lists checks whose failures are reported in VerificationResult.AdvisoryIssues
+	// instead of failing the verification
+	AdvisoryChecks []CheckName `json:"advisoryChecks,omitempty"`
+	// ExpiryDisclosure selects how the document expiry date is reported, defaults to ExpiryDisclosureDate
+	ExpiryDisclosure ExpiryDisclosure `json:"expiryDisclosure,omitempty"`
+	// SanctionedNationalities are nationality-based sanctions, reported as ErrSanctionedNationality
+	// separately from the business exclusions in ExcludedCountries
+	SanctionedNationalities []common.Country3LetterCode `json:"sanctionedNationalities,omitempty"`
+	// Disclosures lists the attributes the policy requests. Proofs disclosing more are masked or
+	// rejected depending on the verifier's DisclosureMode. Nil accepts any disclosure.
+	Disclosures *SelfAppDisclosureConfig `json:"disclosures,omitempty"`
+	// AllowedIssuingStates, when set, requires the document to be issued by one of these states,
+	// reported as ErrIssuingStateNotAllowed. Proofs must disclose the issuing state.
+	AllowedIssuingStates []common.Country3LetterCode `json:"allowedIssuingStates,omitempty"`
+	// RequireBiometricCommitment rejects proofs that do not commit to a biometric hash with
+	// ErrMissingBiometric. Built-in attestation types carry no commitment and always fail it.
+	RequireBiometricCommitment bool `json:"requireBiometricCommitment,omitempty"`
+	// RequireNationalityMatchesIssuer rejects documents whose disclosed nationality differs from
+	// the disclosed issuing state with ErrNationalityIssuerMismatch. It is a no-op unless the proof
+	// discloses both, so also request both in Disclosures.
+	RequireNationalityMatchesIssuer bool `json:"requireNationalityMatchesIssuer,omitempty"`
+	// MinimumAgeMarginDays additionally requires the holder to have turned MinimumAge at least this
+	// many days before the proof date, so users on their birthday in another time zone do not flap
+	// between passing and failing. Proofs must disclose the date of birth.
+	MinimumAgeMarginDays int `json:"minimumAgeMarginDays,omitempty"`
+	// TrustProofOfac skips the backend OFAC screen of OFACModeBackend and OFACModeBoth when the
+	// proof already proves the user OFAC-clean, reporting OFACSourceProof. Users the proof does not
+	// clear are still screened according to OfacMode.
+	TrustProofOfac bool `json:"trustProofOfac,omitempty"`
+	// AllowedDocumentSubtypes, when set, requires passports to be of one of these subtypes, reported
+	// as ErrDocumentSubtypeNotAllowed. Proofs must disclose the document code; other documents have no
+	// subtype and are not checked. Empty accepts every subtype.
+	AllowedDocumentSubtypes []DocumentSubtype `json:"allowedDocumentSubtypes,omitempty"`
+	// RejectIssuedAsMinor rejects documents issued before the holder turned 18 (child passports)
+	// with ErrIssuedAsMinor. It needs the date of issue and the date of birth, and documents not
+	// disclosing both pass; no built-in layout discloses the date of issue, see DateOfIssueISO.
+	RejectIssuedAsMinor bool `json:"rejectIssuedAsMinor,omitempty"`
+	// Parent is the ID of a config this one inherits from. The verifier resolves the chain with
+	// ResolveConfig, applying this config over its parent as described by MergeConfigs.
+	Parent string `json:"parent,omitempty"`
 }
 
 // IsValidDetails contains the validation results
@@ -47,15 +90,72 @@ type GenericDiscloseOutput struct {
 	ExpiryDate                   string   `json:"expiryDate"`
 	MinimumAge                   string   `json:"minimumAge"`
 	Ofac                         []bool   `json:"ofac"`
+	// DocumentValid replaces ExpiryDate when the config uses ExpiryDisclosureValidity
+	DocumentValid *bool `json:"documentValid,omitempty"`
+	// MRZ holds the parsed MRZ components of passports and ID cards, nil for other documents
+	MRZ *MRZComponents `json:"mrz,omitempty"`
+	// DateOfBirthISO and ExpiryDateISO are the disclosed dates as ISO 8601 (YYYY-MM-DD),
+	// set by Verify and empty when the date is not disclosed
+	DateOfBirthISO string `json:"dateOfBirthISO,omitempty"`
+	ExpiryDateISO  string `json:"expiryDateISO,omitempty"`
+	// PersonalNumber is the holder's personal (national ID) number from the MRZ optional data of
+	// passports and ID cards, distinct from the document number in IdNumber. Empty when the
+	// document has none or it is not disclosed.
+	PersonalNumber string `json:"personalNumber,omitempty"`
+	// BiometricCommitment reports whether the proof commits to a hash of the document photo or
+	// biometrics. The commitment itself is never exposed. None of the built-in circuit layouts
+	// commit one, so it is only set by custom AttestationVerifiers.
+	BiometricCommitment bool `json:"biometricCommitment,omitempty"`
+	// DocumentSubtype is the passport subtype from the disclosed MRZ document code, empty for other
+	// documents and when the document code is not disclosed
+	DocumentSubtype DocumentSubtype `json:"documentSubtype,omitempty"`
+	// DateOfIssueISO is the document's date of issue as ISO 8601 (YYYY-MM-DD). None of the built-in
+	// circuit layouts disclose it, so it is only set by custom AttestationVerifiers.
+	DateOfIssueISO string `json:"dateOfIssueISO,omitempty"`
 }
 
 // VerificationResult represents the complete result of a verification
 type VerificationResult struct {
 	AttestationId          AttestationId         `json:"attestationId"`
+	CircuitVersion         CircuitVersion        `json:"circuitVersion"`
 	IsValidDetails         IsValidDetails        `json:"isValidDetails"`
+	OFACSource             OFACSource            `json:"ofacSource,omitempty"`
+	OFACOverride           *OFACOverride         `json:"ofacOverride,omitempty"` // Set when compliance cleared an OFAC hit
 	ForbiddenCountriesList []string              `json:"forbiddenCountriesList"`
 	DiscloseOutput         GenericDiscloseOutput `json:"discloseOutput"`
 	UserData               UserData              `json:"userData"`
+	Score                  *VerificationScore    `json:"score,omitempty"`                // Advisory, set when a ScoringFunc is configured
+	AdvisoryIssues         []ConfigIssue         `json:"advisoryIssues,omitempty"`       // Failed or inconclusive advisory checks
+	IssuingStateRiskTier   RiskTier              `json:"issuingStateRiskTier,omitempty"` // Set when country risk tiers are configured
+	NationalityRiskTier    RiskTier              `json:"nationalityRiskTier,omitempty"`  // Set when country risk tiers are configured
+	ConfigId               string                `json:"configId,omitempty"`             // ID of the config the proof was checked against, the satisfied candidate
+	Config                 VerificationConfig    `json:"config"`                         // Config the proof was checked against
+	ConfigHash             string                `json:"configHash,omitempty"`           // Config.Hash(), identifying the policy version
+	Scope                  string                `json:"scope,omitempty"`                // Application scope the proof was checked against
+	// ProofVerificationTime is how long the proof verification step took, the verifier contract call
+	// or the custom AttestationVerifier; see WithVerificationBudget
+	ProofVerificationTime time.Duration `json:"proofVerificationTime,omitempty"`
+	// IssuedAsMinor reports whether the document was issued before the holder turned 18, nil when
+	// the disclosure lacks the date of issue or the date of birth
+	IssuedAsMinor *bool `json:"issuedAsMinor,omitempty"`
+	// DisclosureWithheld is set when disclosed data was blanked out because the context was not
+	// marked secure; see WithRequireSecureContextForDisclosure
+	DisclosureWithheld bool `json:"disclosureWithheld,omitempty"`
+	// DisclosureStatus tells for every DisclosureField whether DiscloseOutput reports it, or why not:
+	// not provided by the proof, hidden by the config's policy or withheld
+	DisclosureStatus map[DisclosureField]DisclosureStatus `json:"disclosureStatus,omitempty"`
+
+	// RawDiscloseOutput is the disclosure before masking, set only with WithIncludeRawDisclosure.
+	// It is never serialized, so it cannot leak through results returned to clients.
+	RawDiscloseOutput *GenericDiscloseOutput `json:"-"`
+	// MRZLines are the MRZ lines reconstructed from the proof for manual review, set only with
+	// WithIncludeMRZLines. Like RawDiscloseOutput they are never serialized.
+	MRZLines []string `json:"-"`
+	// Cached is set when the result was answered from the ResultCache rather than verified again
+	Cached bool `json:"-"`
+
+	// disclosedFields are the fields the proof disclosed before masking, see DisclosedFieldSet
+	disclosedFields DisclosureSet
 }
 
 // UserIDType represents the type of user identifier
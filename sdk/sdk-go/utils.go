@@ -337,17 +337,21 @@ func GetRevealedDataPublicSignalsLength(attestationId AttestationId) (int, error
 //   - An array of bytes representing the revealed data for the specified attestation
 //   - An error if the attestation ID is invalid or if there's an issue processing the signals
 func GetRevealedDataBytes(attestationId AttestationId, publicSignals PublicSignals) ([]int, error) {
-	// Get the length of revealed data public signals
-	length, err := GetRevealedDataPublicSignalsLength(attestationId)
-	if err != nil {
-		return nil, err
-	}
-
 	// Get the disclose indices for this attestation ID
 	discloseIndices, exists := DiscloseIndices[attestationId]
 	if !exists {
 		return nil, fmt.Errorf("disclose indices not found for attestation ID: %d", attestationId)
 	}
+	return revealedDataBytes(attestationId, discloseIndices, publicSignals)
+}
+
+// revealedDataBytes implements GetRevealedDataBytes for the signal layout given by discloseIndices
+func revealedDataBytes(attestationId AttestationId, discloseIndices DiscloseIndicesEntry, publicSignals PublicSignals) ([]int, error) {
+	// Get the length of revealed data public signals
+	length, err := GetRevealedDataPublicSignalsLength(attestationId)
+	if err != nil {
+		return nil, err
+	}
 
 	// Get the bytes count for this attestation ID
 	bytesCount, exists := BytesCount[attestationId]
@@ -382,16 +386,20 @@ func GetRevealedDataBytes(attestationId AttestationId, publicSignals PublicSigna
 
 // FormatRevealedDataPacked extracts and formats revealed data from public signals
 func FormatRevealedDataPacked(attestationID AttestationId, publicSignals PublicSignals) (GenericDiscloseOutput, error) {
-	revealedDataPacked, err := GetRevealedDataBytes(attestationID, publicSignals)
-
-	if err != nil {
-		return GenericDiscloseOutput{}, err
-	}
-
 	discloseIndices, exists := DiscloseIndices[attestationID]
 	if !exists {
 		return GenericDiscloseOutput{}, fmt.Errorf("disclose indices not found for attestation ID: %d", attestationID)
 	}
+	return formatRevealedDataPacked(attestationID, discloseIndices, publicSignals)
+}
+
+// formatRevealedDataPacked implements FormatRevealedDataPacked for the signal layout given by discloseIndices
+func formatRevealedDataPacked(attestationID AttestationId, discloseIndices DiscloseIndicesEntry, publicSignals PublicSignals) (GenericDiscloseOutput, error) {
+	revealedDataPacked, err := revealedDataBytes(attestationID, discloseIndices, publicSignals)
+
+	if err != nil {
+		return GenericDiscloseOutput{}, err
+	}
 
 	// Convert revealedDataPacked ([]int) to byte array for string operations
 	revealedDataPackedBytes := make([]byte, len(revealedDataPacked))
@@ -487,6 +495,9 @@ func FormatRevealedDataPacked(attestationID AttestationId, publicSignals PublicS
 		ExpiryDate:                   expiryDate,
 		MinimumAge:                   minimumAge,
 		Ofac:                         ofac,
+		MRZ:                          parseMRZComponents(attestationID, revealedDataPackedBytes),
+		PersonalNumber:               personalNumber(attestationID, revealedDataPackedBytes),
+		DocumentSubtype:              documentSubtype(attestationID, revealedDataPackedBytes),
 	}, nil
 }
 
@@ -510,3 +521,15 @@ func cleanName(nameRaw string) string {
 
 	return name
 }
+
+// RedactUserContextData returns a log-safe representation of userContextData.
+//
+// The user identifier and user defined data are replaced by a short SHA-256 prefix of the
+// whole value, which is enough to correlate log lines for the same request.
+func RedactUserContextData(userContextData string) string {
+	if userContextData == "" {
+		return ""
+	}
+	hash := sha256.Sum256([]byte(userContextData))
+	return fmt.Sprintf("redacted:%x", hash[:6])
+}
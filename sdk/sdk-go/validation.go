@@ -0,0 +1,409 @@
+package self
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/selfxyz/self/sdk/sdk-go/common"
+)
+
+// ErrInvalidRequest is matched by a *ValidationError
+var ErrInvalidRequest = errors.New("invalid request")
+
+// FieldError describes a problem with a single request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every problem found in a verification request
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, fieldErr := range e.Errors {
+		messages[i] = fmt.Sprintf("%s: %s", fieldErr.Field, fieldErr.Message)
+	}
+	return fmt.Sprintf("%v: %s", ErrInvalidRequest, strings.Join(messages, "; "))
+}
+
+// Is reports whether target is ErrInvalidRequest
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidRequest
+}
+
+// add records a problem with field
+func (e *ValidationError) add(field string, format string, args ...interface{}) {
+	e.Errors = append(e.Errors, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// DefaultMaxJSONDepth is the nesting limit applied by DecodeVerifyInput, well above the depth of a valid request
+const DefaultMaxJSONDepth = 8
+
+// DecodeLimits bounds the input accepted by DecodeVerifyInputWithLimits
+type DecodeLimits struct {
+	// MaxBytes limits the size of the body (DefaultMaxBodyBytes when zero)
+	MaxBytes int64
+	// MaxDepth limits the nesting of JSON objects and arrays (DefaultMaxJSONDepth when zero)
+	MaxDepth int
+	// MaxPublicSignals limits the number of public signals (DefaultMaxPublicSignals when zero)
+	MaxPublicSignals int
+}
+
+// DecodeVerifyInput decodes a VerifyInput from a JSON request body and validates it, with the
+// default DecodeLimits. Unlike json.Decoder it does not stop at the first problem: the returned
+// *ValidationError lists every missing, mistyped or malformed field.
+func DecodeVerifyInput(body io.Reader) (VerifyInput, error) {
+	return DecodeVerifyInputWithLimits(body, DecodeLimits{})
+}
+
+// DecodeVerifyInputWithLimits is DecodeVerifyInput with custom limits. The body is parsed as a
+// stream, one field at a time, and rejected as soon as it exceeds the limits, so oversized or
+// deeply nested inputs are never buffered whole. Truncated bodies are reported as such.
+func DecodeVerifyInputWithLimits(body io.Reader, limits DecodeLimits) (VerifyInput, error) {
+	return decodeVerifyInput(body, limits, false)
+}
+
+// decodeVerifyInput implements DecodeVerifyInputWithLimits. With anonymous set, a missing or empty
+// userContextData is accepted, for verifiers in anonymous mode.
+func decodeVerifyInput(body io.Reader, limits DecodeLimits, anonymous bool) (VerifyInput, error) {
+	var input VerifyInput
+	fields, err := decodeObjectFields(json.NewDecoder(newLimitedJSONReader(body, limits)))
+	if err != nil {
+		validationErr := &ValidationError{}
+		validationErr.add("body", "%v", err)
+		return input, validationErr
+	}
+
+	validationErr := &ValidationError{}
+	decodeField := func(name string, target interface{}) bool {
+		raw, ok := fields[name]
+		if !ok || string(raw) == "null" {
+			validationErr.add(name, "is required")
+			return false
+		}
+		if err := json.Unmarshal(raw, target); err != nil {
+			validationErr.add(name, "is invalid: %v", err)
+			return false
+		}
+		return true
+	}
+
+	// Whether the ID is supported depends on the verifier, which may have custom attestation types registered
+	if decodeField("attestationId", &input.AttestationId) && input.AttestationId <= 0 {
+		validationErr.add("attestationId", "must be a positive integer, got %d", input.AttestationId)
+	}
+	if decodeField("proof", &input.Proof) {
+		validateProof(input.Proof, validationErr)
+	}
+	maxPublicSignals := limits.MaxPublicSignals
+	if maxPublicSignals <= 0 {
+		maxPublicSignals = DefaultMaxPublicSignals
+	}
+	if raw, ok := fields["publicSignals"]; !ok || string(raw) == "null" {
+		validationErr.add("publicSignals", "is required")
+	} else if err := input.PublicSignals.decode(raw, maxPublicSignals); err != nil {
+		validationErr.add("publicSignals", "is invalid: %v", err)
+	} else {
+		validatePublicSignals(input.PublicSignals, validationErr)
+	}
+	// Anonymous verifiers accept proofs without a user context
+	if raw := string(fields["userContextData"]); !anonymous || (raw != "" && raw != "null" && raw != `""`) {
+		if decodeField("userContextData", &input.UserContextData) {
+			validateUserContextData(input.UserContextData, validationErr)
+		}
+	}
+	// Disclosure salts are optional
+	if raw := string(fields["disclosureSalts"]); raw != "" && raw != "null" && decodeField("disclosureSalts", &input.DisclosureSalts) {
+		validateDisclosureSalts(input.DisclosureSalts, validationErr)
+	}
+
+	if len(validationErr.Errors) > 0 {
+		return input, validationErr
+	}
+	return input, nil
+}
+
+// decodeObjectFields reads a JSON object from decoder one field at a time, keeping only the fields of
+// a VerifyInput and skipping the others
+func decodeObjectFields(decoder *json.Decoder) (map[string]json.RawMessage, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, decodeStreamError(err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, errors.New("must be a JSON object")
+	}
+
+	fields := make(map[string]json.RawMessage)
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, decodeStreamError(err)
+		}
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, decodeStreamError(err)
+		}
+		switch name := token.(string); name {
+		case "attestationId", "proof", "publicSignals", "userContextData", "disclosureSalts":
+			fields[name] = raw
+		}
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, decodeStreamError(err)
+	}
+	return fields, nil
+}
+
+// decodeStreamError describes an error reading the JSON body
+func decodeStreamError(err error) error {
+	if errors.Is(err, errMalformedGzip) || errors.Is(err, errJSONLimit) {
+		return err
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return errors.New("is truncated")
+	}
+	return fmt.Errorf("must be a JSON object: %v", err)
+}
+
+// errMalformedGzip is matched by errors reading a gzip-encoded body that is not valid gzip
+var errMalformedGzip = errors.New("is not valid gzip")
+
+// errJSONLimit is matched by errors reporting a body beyond its DecodeLimits
+var errJSONLimit = errors.New("exceeds the decode limits")
+
+// limitedJSONReader fails once the JSON read through it exceeds its size or nesting limits.
+// It tracks nesting as bytes stream past, so the limits apply before the decoder buffers a value.
+type limitedJSONReader struct {
+	reader   io.Reader
+	limits   DecodeLimits
+	read     int64
+	depth    int
+	inString bool
+	escaped  bool
+}
+
+// newLimitedJSONReader wraps reader with limits, applying the defaults for zero limits
+func newLimitedJSONReader(reader io.Reader, limits DecodeLimits) *limitedJSONReader {
+	if limits.MaxBytes <= 0 {
+		limits.MaxBytes = DefaultMaxBodyBytes
+	}
+	if limits.MaxDepth <= 0 {
+		limits.MaxDepth = DefaultMaxJSONDepth
+	}
+	return &limitedJSONReader{reader: reader, limits: limits}
+}
+
+func (r *limitedJSONReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+	if r.read > r.limits.MaxBytes {
+		return 0, fmt.Errorf("%w: must be at most %d bytes", errJSONLimit, r.limits.MaxBytes)
+	}
+	for _, char := range p[:n] {
+		switch {
+		case r.escaped:
+			r.escaped = false
+		case r.inString:
+			r.escaped = char == '\\'
+			r.inString = char != '"'
+		case char == '"':
+			r.inString = true
+		case char == '{' || char == '[':
+			if r.depth++; r.depth > r.limits.MaxDepth {
+				return 0, fmt.Errorf("%w: must be nested at most %d levels deep", errJSONLimit, r.limits.MaxDepth)
+			}
+		case char == '}' || char == ']':
+			r.depth--
+		}
+	}
+	return n, err
+}
+
+// validateProof checks that every proof element is present and numeric
+func validateProof(proof VcAndDiscloseProof, validationErr *ValidationError) {
+	elements := []struct {
+		field string
+		value string
+	}{
+		{"proof.a[0]", proof.A[0]},
+		{"proof.a[1]", proof.A[1]},
+		{"proof.b[0][0]", proof.B[0][0]},
+		{"proof.b[0][1]", proof.B[0][1]},
+		{"proof.b[1][0]", proof.B[1][0]},
+		{"proof.b[1][1]", proof.B[1][1]},
+		{"proof.c[0]", proof.C[0]},
+		{"proof.c[1]", proof.C[1]},
+	}
+	for _, element := range elements {
+		if _, ok := parsePublicSignal(element.value); !ok {
+			validationErr.add(element.field, "must be a decimal or hex field element, got %q", element.value)
+		}
+	}
+}
+
+// validatePublicSignals checks that signals are present and numeric
+func validatePublicSignals(signals []string, validationErr *ValidationError) {
+	if len(signals) == 0 {
+		validationErr.add("publicSignals", "must not be empty")
+		return
+	}
+	for i, signal := range signals {
+		if _, ok := parsePublicSignal(signal); !ok {
+			validationErr.add(fmt.Sprintf("publicSignals[%d]", i), "must be a decimal or hex field element, got %q", signal)
+		}
+	}
+}
+
+// validateUserContextData checks that userContextData is hex holding at least the config ID and user identifier
+func validateUserContextData(userContextData string, validationErr *ValidationError) {
+	if _, err := hex.DecodeString(userContextData); err != nil {
+		validationErr.add("userContextData", "must be a hex string without 0x prefix: %v", err)
+		return
+	}
+	if len(userContextData) < 128 {
+		validationErr.add("userContextData", "must be at least 128 hex characters, got %d", len(userContextData))
+	}
+}
+
+// validateDisclosureSalts checks that every salt names a known field and holds a value
+func validateDisclosureSalts(salts map[DisclosureField]string, validationErr *ValidationError) {
+	fields := make([]string, 0, len(salts))
+	for field := range salts {
+		fields = append(fields, string(field))
+	}
+	sort.Strings(fields)
+
+	known := NewDisclosureSet(AllDisclosureFields...)
+	for _, field := range fields {
+		switch {
+		case !known[DisclosureField(field)]:
+			validationErr.add("disclosureSalts", "has unknown field %q", field)
+		case salts[DisclosureField(field)] == "":
+			validationErr.add("disclosureSalts", "has an empty salt for %s", field)
+		}
+	}
+}
+
+// DecodeVerificationConfig decodes a VerificationConfig from a JSON body, rejecting unknown fields,
+// removes duplicate country codes and validates it with ValidateVerificationConfig
+func DecodeVerificationConfig(body io.Reader) (VerificationConfig, error) {
+	var config VerificationConfig
+	decoder := json.NewDecoder(body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		validationErr := &ValidationError{}
+		validationErr.add("body", "must be a JSON verification config: %v", err)
+		return config, validationErr
+	}
+	config = dedupeCountryLists(config)
+	return config, ValidateVerificationConfig(config)
+}
+
+// NormalizeCountryLists returns config with duplicate codes removed from its country lists, keeping
+// the first occurrence of each. Unknown codes are rejected with a *ValidationError listing every one,
+// since a mistyped code would otherwise silently match nobody. Config stores apply it in SetConfig.
+func NormalizeCountryLists(config VerificationConfig) (VerificationConfig, error) {
+	validationErr := &ValidationError{}
+	validateCountryCodes("excludedCountries", config.ExcludedCountries, validationErr)
+	validateCountryCodes("sanctionedNationalities", config.SanctionedNationalities, validationErr)
+	validateCountryCodes("allowedIssuingStates", config.AllowedIssuingStates, validationErr)
+	if len(validationErr.Errors) > 0 {
+		return config, validationErr
+	}
+	return dedupeCountryLists(config), nil
+}
+
+// dedupeCountryLists returns config with duplicate codes removed from its country lists
+func dedupeCountryLists(config VerificationConfig) VerificationConfig {
+	config.ExcludedCountries = dedupeCountries(config.ExcludedCountries)
+	config.SanctionedNationalities = dedupeCountries(config.SanctionedNationalities)
+	config.AllowedIssuingStates = dedupeCountries(config.AllowedIssuingStates)
+	return config
+}
+
+// dedupeCountries returns a copy of codes without duplicates, in first-occurrence order
+func dedupeCountries(codes []common.Country3LetterCode) []common.Country3LetterCode {
+	if codes == nil {
+		return nil
+	}
+	seen := make(map[common.Country3LetterCode]bool, len(codes))
+	deduped := make([]common.Country3LetterCode, 0, len(codes))
+	for _, code := range codes {
+		if !seen[code] {
+			seen[code] = true
+			deduped = append(deduped, code)
+		}
+	}
+	return deduped
+}
+
+// ValidateVerificationConfig checks that config is usable by the verifier: it sets at least one
+// requirement, the minimum age fits the circuit, and every enumerated value is known.
+// The returned *ValidationError lists every problem.
+func ValidateVerificationConfig(config VerificationConfig) error {
+	validationErr := &ValidationError{}
+	if isEmptyVerificationConfig(config) {
+		validationErr.add("config", "must set at least one requirement")
+	}
+	// The circuit discloses the minimum age as two digits
+	if config.MinimumAge < 0 || config.MinimumAge > 99 {
+		validationErr.add("minimumAge", "must be between 0 and 99, got %d", config.MinimumAge)
+	}
+	if config.MinimumAgeMarginDays < 0 {
+		validationErr.add("minimumAgeMarginDays", "must not be negative, got %d", config.MinimumAgeMarginDays)
+	} else if config.MinimumAgeMarginDays > 0 && config.MinimumAge == 0 {
+		validationErr.add("minimumAgeMarginDays", "requires minimumAge to be set")
+	}
+	validateCountryCodes("excludedCountries", config.ExcludedCountries, validationErr)
+	validateCountryCodes("sanctionedNationalities", config.SanctionedNationalities, validationErr)
+	validateCountryCodes("allowedIssuingStates", config.AllowedIssuingStates, validationErr)
+	for i, subtype := range config.AllowedDocumentSubtypes {
+		if !subtype.isKnown() {
+			validationErr.add(fmt.Sprintf("allowedDocumentSubtypes[%d]", i), "unknown document subtype %q", subtype)
+		}
+	}
+
+	switch config.OfacMode {
+	case "", OFACModeProof, OFACModeBackend, OFACModeBoth:
+	default:
+		validationErr.add("ofacMode", "must be %q, %q or %q, got %q", OFACModeProof, OFACModeBackend, OFACModeBoth, config.OfacMode)
+	}
+	switch config.ExpiryDisclosure {
+	case "", ExpiryDisclosureDate, ExpiryDisclosureValidity:
+	default:
+		validationErr.add("expiryDisclosure", "must be %q or %q, got %q", ExpiryDisclosureDate, ExpiryDisclosureValidity, config.ExpiryDisclosure)
+	}
+
+	knownChecks := make(map[CheckName]bool)
+	for _, check := range issueChecks {
+		knownChecks[check] = true
+	}
+	for i, check := range config.AdvisoryChecks {
+		if !knownChecks[check] {
+			validationErr.add(fmt.Sprintf("advisoryChecks[%d]", i), "unknown check %q", check)
+		}
+	}
+
+	if len(validationErr.Errors) > 0 {
+		return validationErr
+	}
+	return nil
+}
+
+// validateCountryCodes checks that every code is a known Country3LetterCode
+func validateCountryCodes(field string, codes []common.Country3LetterCode, validationErr *ValidationError) {
+	for i, code := range codes {
+		if !code.IsKnown() {
+			validationErr.add(fmt.Sprintf("%s[%d]", field, i), "unknown country code %q", code)
+		}
+	}
+}
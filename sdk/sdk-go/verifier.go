@@ -3,12 +3,16 @@ package self
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/big"
+	"runtime/debug"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 
@@ -37,6 +41,17 @@ const (
 	InvalidMinimumAge             ConfigMismatch = "InvalidMinimumAge"
 	InvalidTimestamp              ConfigMismatch = "InvalidTimestamp"
 	InvalidOfac                   ConfigMismatch = "InvalidOfac"
+	InvalidSanctionedNationality  ConfigMismatch = "InvalidSanctionedNationality"
+	InvalidDisclosure             ConfigMismatch = "InvalidDisclosure"
+	InvalidIssuingState           ConfigMismatch = "InvalidIssuingState"
+	InvalidContextDataSignature   ConfigMismatch = "InvalidContextDataSignature"
+	MissingBiometric              ConfigMismatch = "MissingBiometric"
+	InvalidNationalityIssuer      ConfigMismatch = "InvalidNationalityIssuer"
+	InvalidDocumentSubtype        ConfigMismatch = "InvalidDocumentSubtype"
+	InvalidIssuedAsMinor          ConfigMismatch = "InvalidIssuedAsMinor"
+	InconsistentDisclosure        ConfigMismatch = "InconsistentDisclosure"
+	MissingDisclosure             ConfigMismatch = "MissingDisclosure"
+	PlaceholderData               ConfigMismatch = "PlaceholderData"
 	ConfigNotFound                ConfigMismatch = "ConfigNotFound"
 )
 
@@ -44,6 +59,16 @@ const (
 type ConfigIssue struct {
 	Type    ConfigMismatch `json:"type"`
 	Message string         `json:"message"`
+	// Err is a more specific error for the issue, defaults to Type.Err()
+	Err error `json:"-"`
+}
+
+// err returns the error the issue is reported as
+func (issue ConfigIssue) err() error {
+	if issue.Err != nil {
+		return issue.Err
+	}
+	return issue.Type.Err()
 }
 
 // ConfigMismatchError represents an error with multiple configuration issues
@@ -59,6 +84,30 @@ func (e *ConfigMismatchError) Error() string {
 	return strings.Join(message, "\n")
 }
 
+// issueTypes lists the type of each issue, leaving out the messages since they can carry disclosed data
+func issueTypes(issues []ConfigIssue) []ConfigMismatch {
+	types := make([]ConfigMismatch, 0, len(issues))
+	for _, issue := range issues {
+		types = append(types, issue.Type)
+	}
+	return types
+}
+
+// Unwrap returns the errors matching each issue type, so callers can use errors.Is
+func (e *ConfigMismatchError) Unwrap() []error {
+	var errs []error
+	seen := make(map[error]bool)
+	for _, issue := range e.Issues {
+		err := issue.err()
+		if seen[err] {
+			continue
+		}
+		seen[err] = true
+		errs = append(errs, err)
+	}
+	return errs
+}
+
 // NewConfigMismatchError creates a new ConfigMismatchError with the given issues
 func NewConfigMismatchError(issue []ConfigIssue) *ConfigMismatchError {
 	return &ConfigMismatchError{Issues: issue}
@@ -67,11 +116,48 @@ func NewConfigMismatchError(issue []ConfigIssue) *ConfigMismatchError {
 // BackendVerifier handles verification of Self protocol attestations
 type BackendVerifier struct {
 	scope                           string
+	scopeName                       string
+	endpoint                        string
+	mockPassport                    bool
+	rpcURL                          string
 	identityVerificationHubContract *bindings.IdentityVerificationHubImpl
 	configStorage                   ConfigStore
 	provider                        *ethclient.Client
 	allowedIDs                      map[AttestationId]bool
 	userIdentifierType              UserIDType
+	logger                          Logger
+	auditSink                       AuditSink
+	outcomeRecorder                 OutcomeRecorder
+	resultCache                     *ResultCache
+	traceSink                       TraceSink
+	ofacScreener                    OFACScreener
+	ofacOverrideStore               OFACOverrideStore
+	scoringFunc                     ScoringFunc
+	maxProofAge                     time.Duration
+	futureSkew                      time.Duration
+	sessionTimestamp                SessionTimestampFunc
+	countryRiskTiers                map[commonUtils.Country3LetterCode]RiskTier
+	disclosureMode                  DisclosureMode
+	rejectPlaceholders              bool
+	requireSecureDisclosure         bool
+	strictAttestationIds            bool
+	includeRawDisclosure            bool
+	includeMRZLines                 bool
+	readOnly                        bool
+	contextDataVerifier             *ContextDataVerifier
+	verifyTimeout                   time.Duration
+	maxPublicSignals                int
+	circuitLayouts                  circuitLayouts
+	verificationBudget              time.Duration
+	configStoreTimeout              time.Duration
+	configStoreFallback             *VerificationConfig
+	anonymousConfigId               string
+	allowedScopeNames               []string
+	allowedScopes                   map[string]string
+	pinnedRoots                     map[AttestationId][]PinnedRoot
+	attestationVerifiersMu          sync.RWMutex
+	attestationVerifiers            map[AttestationId]AttestationVerifier
+	keyCache                        *keyCache
 }
 
 // NewBackendVerifier creates a new BackendVerifier instance
@@ -80,13 +166,18 @@ type BackendVerifier struct {
 //   - scope: The verification scope identifier
 //   - endpoint: The endpoint URL for scope hashing
 //   - mockPassport: Whether to use testnet (staging) contracts
-//   - allowedIds: Map of allowed attestation IDs
+//   - allowedIds: Map of allowed attestation IDs, at least one must be allowed
 //   - configStorage: Configuration storage interface implementation
 //   - userIdentifierType: Type of user identifier (hex or uuid)
+//   - opts: Optional VerifierOption values
 //
 // Returns:
 //   - A new BackendVerifier instance
-//   - An error if initialization fails
+//   - An error if initialization fails, matching ErrAttestationNotAllowed when allowedIds allows nothing
+//
+// A BackendVerifier is safe for concurrent use. Construct it once at startup and share it between
+// requests rather than constructing one per request: construction sets up an RPC client and hashes
+// the scope (see BenchmarkNewBackendVerifier).
 func NewBackendVerifier(
 	scope string,
 	endpoint string,
@@ -94,7 +185,37 @@ func NewBackendVerifier(
 	allowedIds map[AttestationId]bool,
 	configStorage ConfigStore,
 	userIdentifierType UserIDType,
+	opts ...VerifierOption,
 ) (*BackendVerifier, error) {
+	// An empty allow-list would reject every proof, which is always a configuration mistake
+	if !allowsAnyAttestation(allowedIds) {
+		return nil, fmt.Errorf("%w: allowedIds is empty, allow at least one attestation ID (e.g. self.Passport)", ErrAttestationNotAllowed)
+	}
+
+	hashedScope, err := commonUtils.HashEndpointWithScope(endpoint, scope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash endpoint with scope: %v", err)
+	}
+
+	verifier := &BackendVerifier{
+		scope:              hashedScope,
+		scopeName:          scope,
+		endpoint:           endpoint,
+		mockPassport:       mockPassport,
+		configStorage:      configStorage,
+		allowedIDs:         allowedIds,
+		userIdentifierType: userIdentifierType,
+		logger:             nopLogger{},
+		maxProofAge:        DefaultMaxProofAge,
+		futureSkew:         DefaultFutureSkew,
+		verifyTimeout:      DefaultVerifyTimeout,
+		maxPublicSignals:   DefaultMaxPublicSignals,
+		circuitLayouts:     defaultCircuitLayouts,
+	}
+	for _, opt := range opts {
+		opt(verifier)
+	}
+
 	rpcUrl := CELO_MAINNET_RPC_URL
 	hubAddress := IDENTITY_VERIFICATION_HUB_ADDRESS
 
@@ -102,6 +223,9 @@ func NewBackendVerifier(
 		rpcUrl = CELO_TESTNET_RPC_URL
 		hubAddress = IDENTITY_VERIFICATION_HUB_ADDRESS_STAGING
 	}
+	if verifier.rpcURL != "" {
+		rpcUrl = verifier.rpcURL
+	}
 
 	provider, err := ethclient.Dial(rpcUrl)
 	if err != nil {
@@ -116,20 +240,30 @@ func NewBackendVerifier(
 	if err != nil {
 		return nil, fmt.Errorf("failed to create hub contract binding: %v", err)
 	}
+	verifier.provider = provider
+	verifier.identityVerificationHubContract = hubContract
 
-	hashedScope, err := commonUtils.HashEndpointWithScope(endpoint, scope)
-	if err != nil {
-		return nil, fmt.Errorf("failed to hash endpoint with scope: %v", err)
+	if err := verifier.hashAllowedScopes(); err != nil {
+		return nil, err
+	}
+	if verifier.includeRawDisclosure {
+		verifier.logger.Printf("self: raw disclosures are included in verification results, which exposes PII masked by the config")
+	}
+	if verifier.includeMRZLines {
+		verifier.logger.Printf("self: MRZ lines are included in verification results, which exposes PII masked by the config")
 	}
 
-	return &BackendVerifier{
-		scope:                           hashedScope,
-		identityVerificationHubContract: hubContract,
-		configStorage:                   configStorage,
-		provider:                        provider,
-		allowedIDs:                      allowedIds,
-		userIdentifierType:              userIdentifierType,
-	}, nil
+	return verifier, nil
+}
+
+// allowsAnyAttestation reports whether allowedIds allows at least one attestation ID
+func allowsAnyAttestation(allowedIds map[AttestationId]bool) bool {
+	for _, allowed := range allowedIds {
+		if allowed {
+			return true
+		}
+	}
+	return false
 }
 
 // containsHexChars checks if a string contains hexadecimal characters (a-f)
@@ -153,7 +287,8 @@ func containsHexChars(s string) bool {
 //
 // Returns:
 //   - VerificationResult containing all verification details
-//   - An error if verification fails or validation issues are found
+//   - An error if verification fails or validation issues are found, matching ErrVerificationTimeout
+//     if the verification timeout or the deadline of ctx expired first
 func (s *BackendVerifier) Verify(
 	ctx context.Context,
 	attestationIdInt int,
@@ -161,101 +296,227 @@ func (s *BackendVerifier) Verify(
 	pubSignals []string,
 	userContextData string,
 ) (*VerificationResult, error) {
+	start := time.Now()
+
+	// Results verified against a config snapshot or per-call disclosures do not answer requests
+	// checked against the store's config, and salts are checked on every call
+	_, hasSnapshot := configSnapshotFromContext(ctx)
+	_, hasDisclosures := disclosuresFromContext(ctx)
+	useCache := s.resultCache != nil && !hasSnapshot && !hasDisclosures && len(DisclosureSaltsFromContext(ctx)) == 0
+
+	var cacheKey string
+	if useCache {
+		cacheKey = ResultCacheKey(VerifyInput{
+			AttestationId:   attestationIdInt,
+			Proof:           proof,
+			PublicSignals:   pubSignals,
+			UserContextData: userContextData,
+		})
+		if cached, ok := s.resultCache.Get(cacheKey); ok {
+			if state, current := s.cachedResultState(ctx, AttestationId(attestationIdInt), cached, userContextData); current {
+				cached.Cached = true
+				if !s.readOnly {
+					s.recordVerification(ctx, AttestationId(attestationIdInt), start, userContextData, state, cached, nil)
+				}
+				return s.withheldDisclosure(ctx, cached), nil
+			}
+			// The config changed since, so the proof is checked against the current one
+			s.resultCache.InvalidateResult(cacheKey)
+		}
+	}
+
+	// The caller's deadline, if any, takes precedence over the verifier's timeout
+	verifyCtx := ctx
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline && s.verifyTimeout > 0 {
+		var cancel context.CancelFunc
+		verifyCtx, cancel = context.WithTimeout(ctx, s.verifyTimeout)
+		defer cancel()
+	}
+
+	state := &verificationState{}
+	result, err := s.verify(verifyCtx, attestationIdInt, proof, pubSignals, userContextData, state)
+	if err != nil && errors.Is(verifyCtx.Err(), context.DeadlineExceeded) {
+		// Whatever failed, it failed because the deadline cut the verification short
+		result, err = nil, fmt.Errorf("%w: %w", ErrVerificationTimeout, verifyCtx.Err())
+	}
+
+	if err == nil {
+		result.Scope = state.scope.name
+		if err = applyIssuedAsMinor(result); err != nil {
+			result = nil
+		}
+	}
+
+	if err == nil && result.IsValidDetails.IsValid && s.scoringFunc != nil {
+		score := s.scoringFunc(ctx, result.DiscloseOutput, result.IsValidDetails)
+		result.Score = &score
+	}
+
+	if err == nil && s.countryRiskTiers != nil {
+		s.applyRiskTiers(result)
+	}
+
+	// Read-only verifiers cache and record nothing
+	if s.readOnly {
+		return s.withheldDisclosure(ctx, result), err
+	}
+
+	if err == nil && result.IsValidDetails.IsValid && useCache {
+		s.resultCache.Put(cacheKey, result)
+	}
+
+	if s.traceSink != nil && (err != nil || !result.IsValidDetails.IsValid) {
+		s.recordTrace(ctx, AttestationId(attestationIdInt), proof, pubSignals, userContextData, state, err)
+	}
+
+	s.recordVerification(ctx, AttestationId(attestationIdInt), start, userContextData, state, result, err)
+
+	return s.withheldDisclosure(ctx, result), err
+}
+
+// recordVerification writes the audit record and outcome of a finished verification, when enabled
+func (s *BackendVerifier) recordVerification(
+	ctx context.Context,
+	attestationId AttestationId,
+	start time.Time,
+	userContextData string,
+	state *verificationState,
+	result *VerificationResult,
+	err error,
+) {
+	if s.auditSink != nil {
+		s.recordAudit(ctx, attestationId, userContextData, state, result, err)
+	}
+	if s.outcomeRecorder != nil {
+		s.recordOutcome(ctx, attestationId, start, state, result, err)
+	}
+}
+
+// VerifyBool verifies input and reports only whether the proof is valid, for simple gating such as
+// letting a user in. No disclosed data is returned, so callers that do not need any attributes
+// never handle them. The error is the one Verify returns.
+func (s *BackendVerifier) VerifyBool(ctx context.Context, input VerifyInput) (bool, error) {
+	result, err := s.verifyInput(ctx, input)
+	if err != nil {
+		return false, err
+	}
+	return result.IsValidDetails.IsValid, nil
+}
+
+// verificationState collects what a verification resolved along the way, for reporting
+type verificationState struct {
+	configId       string
+	config         *VerificationConfig
+	userIdentifier string
+	scope          verifierScope
+	issuingState   string
+	nationality    string
+}
+
+// verify implements Verify, recording resolved values in state
+func (s *BackendVerifier) verify(
+	ctx context.Context,
+	attestationIdInt int,
+	proof VcAndDiscloseProof,
+	pubSignals []string,
+	userContextData string,
+	state *verificationState,
+) (*VerificationResult, error) {
+
+	scope, err := s.expectedScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	state.scope = scope
 
 	attestationId := AttestationId(attestationIdInt)
+	if s.strictAttestationIds {
+		if attestationId, err = s.ParseAttestationType(attestationIdInt); err != nil {
+			return nil, err
+		}
+	}
+	if len(pubSignals) > s.maxPublicSignals {
+		return nil, fmt.Errorf("%w: got %d, at most %d are accepted", ErrTooManyPublicSignals, len(pubSignals), s.maxPublicSignals)
+	}
+
+	callOpts := &bind.CallOpts{Context: ctx}
+	if custom := s.attestationVerifier(attestationId); custom != nil {
+		return s.verifyCustomAttestation(ctx, custom, attestationId, proof, pubSignals, userContextData)
+	}
+
+	if len(DisclosureSaltsFromContext(ctx)) > 0 {
+		return nil, noSaltedFieldsError(attestationId)
+	}
+
 	allowedId, exists := s.allowedIDs[attestationId]
 	var issues []ConfigIssue
 
 	if !exists || !allowedId {
-		issues = append(issues, ConfigIssue{
-			Type:    InvalidId,
-			Message: fmt.Sprintf("Attestation ID is not allowed, received: %d", attestationId),
-		})
+		issues = append(issues, s.attestationNotAllowedIssue(attestationId))
 	}
 
-	// Process public signals, adding 0x prefix for hex values if needed
-	publicSignals := make([]string, len(pubSignals))
-	for i, signal := range pubSignals {
-		if len(signal) > 0 && !strings.HasPrefix(signal, "0x") && containsHexChars(signal) {
-			publicSignals[i] = "0x" + signal
-		} else {
-			publicSignals[i] = signal
+	// Process public signals, converting hex values to base 10
+	publicSignals := CanonicalizePublicSignals(pubSignals)
+
+	attestationIdBytes32 := attestationIdToBytes32(attestationId)
+
+	// Detect the circuit version from the public signal layout before indexing into it
+	layout, layoutErr := s.circuitLayouts.detect(attestationId, publicSignals)
+	if errors.Is(layoutErr, ErrUnsupportedCircuitVersion) {
+		return nil, layoutErr
+	}
+	if layoutErr == nil {
+		if err := ValidatePublicSignalsOrder(layout, attestationId, publicSignals); err != nil {
+			return nil, err
 		}
 	}
 
-	attestationIdHex := fmt.Sprintf("%064x", attestationId)
-	attestationIdBytes32 := [32]byte{}
-	copy(attestationIdBytes32[:], common.FromHex("0x"+attestationIdHex))
+	// Anonymous proofs carry no userContextData, so they are bound to no user or request
+	anonymous := userContextData == "" && s.anonymousConfigId != ""
 
 	// Check if user context hash matches
-	discloseIndices, exists := DiscloseIndices[attestationId]
+	discloseIndices, exists := layout.Indices, layoutErr == nil
 	if !exists {
 		issues = append(issues, ConfigIssue{
 			Type:    InvalidAttestationId,
 			Message: fmt.Sprintf("Unknown attestation ID: %d", attestationId),
 		})
 	} else {
-
-		// Get user context hash from circuit
-		userContextHashInCircuit := new(big.Int)
-		userContextHashInCircuit.SetString(publicSignals[discloseIndices.UserIdentifierIndex], 10)
-
-		// Calculate expected user context hash
-		userContextDataBytes, err := hex.DecodeString(userContextData)
-		if err != nil {
-			issues = append(issues, ConfigIssue{
-				Type:    InvalidUserContextHash,
-				Message: fmt.Sprintf("Invalid hex string in userContextData: %v", err),
-			})
-		} else {
-			userContextHashStr := CalculateUserIdentifierHash(userContextDataBytes)
-			userContextHash := new(big.Int)
-			userContextHashStr = strings.TrimPrefix(userContextHashStr, "0x")
-			userContextHash.SetString(userContextHashStr, 16)
-
-			if userContextHashInCircuit.Cmp(userContextHash) != 0 {
-				issues = append(issues, ConfigIssue{
-					Type: InvalidUserContextHash,
-					Message: fmt.Sprintf("User context hash does not match with the one in the circuit\nCircuit: %s\nUser context hash: %s",
-						userContextHashInCircuit.String(), userContextHash.String()),
-				})
-			}
+		if !anonymous {
+			s.checkUserContextData(userContextData, publicSignals[discloseIndices.UserIdentifierIndex], &issues)
 		}
 
 		// Check if scope matches
-		isValidScope := s.scope == publicSignals[discloseIndices.ScopeIndex]
+		isValidScope := scope.hash == publicSignals[discloseIndices.ScopeIndex]
 		if !isValidScope {
-			issues = append(issues, ConfigIssue{
-				Type: InvalidScope,
-				Message: fmt.Sprintf("Scope does not match with the one in the circuit\nCircuit: %s\nScope: %s",
-					publicSignals[discloseIndices.ScopeIndex], s.scope),
-			})
+			issues = append(issues, s.scopeMismatchIssue(scope, publicSignals[discloseIndices.ScopeIndex]))
 		}
 
-		// Check the root (reusing pre-calculated attestationIdBytes32)
-		registryAddress, err := s.identityVerificationHubContract.Registry(nil, attestationIdBytes32)
-		if err != nil || registryAddress == (common.Address{}) {
-			issues = append(issues, ConfigIssue{
-				Type:    InvalidRoot,
-				Message: "Registry contract not found",
-			})
-		} else {
-			registryContract, err := bindings.NewRegistry(registryAddress, s.provider)
-			if err != nil {
+		// Check the root against the pinned roots, else on chain (reusing pre-calculated attestationIdBytes32)
+		merkleRoot := new(big.Int)
+		merkleRoot.SetString(publicSignals[discloseIndices.MerkleRootIndex], 10)
+		if !s.checkPinnedRoots(attestationId, merkleRoot, time.Now(), &issues) {
+			registryAddress, err := s.identityVerificationHubContract.Registry(callOpts, attestationIdBytes32)
+			if err != nil || registryAddress == (common.Address{}) {
 				issues = append(issues, ConfigIssue{
 					Type:    InvalidRoot,
-					Message: fmt.Sprintf("Failed to create registry contract binding: %v", err),
+					Message: "Registry contract not found",
 				})
 			} else {
-				merkleRoot := new(big.Int)
-				merkleRoot.SetString(publicSignals[discloseIndices.MerkleRootIndex], 10)
-
-				currentRoot, err := registryContract.CheckIdentityCommitmentRoot(nil, merkleRoot)
-				if err != nil || !currentRoot {
+				registryContract, err := bindings.NewRegistry(registryAddress, s.provider)
+				if err != nil {
 					issues = append(issues, ConfigIssue{
 						Type:    InvalidRoot,
-						Message: fmt.Sprintf("Onchain root does not exist, received: %s", publicSignals[discloseIndices.MerkleRootIndex]),
+						Message: fmt.Sprintf("Failed to create registry contract binding: %v", err),
 					})
+				} else {
+					currentRoot, err := registryContract.CheckIdentityCommitmentRoot(callOpts, merkleRoot)
+					if err != nil || !currentRoot {
+						issues = append(issues, ConfigIssue{
+							Type:    InvalidRoot,
+							Message: fmt.Sprintf("Onchain root does not exist, received: %s", publicSignals[discloseIndices.MerkleRootIndex]),
+						})
+					}
 				}
 			}
 		}
@@ -278,73 +539,330 @@ func (s *BackendVerifier) Verify(
 	var forbiddenCountriesList []string
 
 	// Precompute generic disclose output once and reuse
-	genericDiscloseOutput, err := FormatRevealedDataPacked(attestationId, publicSignals)
+	genericDiscloseOutput, err := formatRevealedDataPacked(attestationId, layout.Indices, publicSignals)
 	if err != nil {
 		issues = append(issues, ConfigIssue{
 			Type:    InvalidMinimumAge,
 			Message: fmt.Sprintf("Error formatting revealed data: %v", err),
 		})
+	} else if exists {
+		validateDisclosureConsistency(attestationId, genericDiscloseOutput, publicSignals, discloseIndices, &issues)
+		if s.rejectPlaceholders {
+			validatePlaceholderData(attestationId, genericDiscloseOutput, &issues)
+		}
 	}
 
-	if len(userContextData) < 128 {
+	if len(userContextData) < 128 && !anonymous {
 		issues = append(issues, ConfigIssue{
 			Type:    ConfigNotFound,
 			Message: "userContextData too short",
 		})
 	} else {
-		// Extract userIdentifier from bytes 64-128 (32-64 in hex string = 64-128 chars)
-		userIdentifierHex := userContextData[64:128]
-		userIdentifierBigInt := new(big.Int)
-		userIdentifierBigInt.SetString(userIdentifierHex, 16)
-
-		userIdentifier = CastToUserIdentifier(userIdentifierBigInt, s.userIdentifierType)
-		userDefinedData = userContextData[128:]
+		var configIds []string
+		var err error
+		if anonymous {
+			// Anonymous proofs carry no user, so they are checked against the anonymous config
+			configIds = []string{s.anonymousConfigId}
+		} else {
+			// Extract userIdentifier from bytes 64-128 (32-64 in hex string = 64-128 chars)
+			userIdentifierHex := userContextData[64:128]
+			userIdentifierBigInt := new(big.Int)
+			userIdentifierBigInt.SetString(userIdentifierHex, 16)
+
+			userIdentifier = CastToUserIdentifier(userIdentifierBigInt, s.userIdentifierType)
+			userDefinedData = userContextData[128:]
+			state.userIdentifier = userIdentifier
+			if s.sessionTimestamp != nil && exists {
+				s.validateSessionTimestamp(attestationId, userDefinedData, publicSignals, discloseIndices, &issues)
+			}
 
-		// Get config ID from storage
-		configId, err := s.configStorage.GetActionId(ctx, userIdentifier, userDefinedData)
-		if err != nil || configId == "" {
+			// Get the candidate config IDs from storage, unless a snapshot replaces them
+			if _, hasSnapshot := configSnapshotFromContext(ctx); !hasSnapshot {
+				configIds, err = callConfigStore(s, ctx, func(ctx context.Context) ([]string, error) {
+					return resolveActionIds(ctx, s.configStorage, ActionIdRequest{
+						UserIdentifier:  userIdentifier,
+						UserDefinedData: userDefinedData,
+						Scope:           scope.name,
+						AttestationId:   attestationId,
+					})
+				})
+			}
+		}
+		if _, hasSnapshot := configSnapshotFromContext(ctx); hasSnapshot {
+			// The snapshot passed to VerifyWithConfig is the only candidate
+			configIds = []string{""}
+		}
+		actionIdTimedOut := errors.Is(err, ErrConfigStoreTimeout)
+		if !actionIdTimedOut && (err != nil || len(configIds) == 0) {
 			issues = append(issues, ConfigIssue{
 				Type:    ConfigNotFound,
 				Message: "Config Id not found",
 			})
 		} else {
-			// Get verification config
-			verificationConfig, configErr = s.configStorage.GetConfig(ctx, configId)
-
-			// Check for GetConfig error first
-			if configErr != nil {
-				issues = append(issues, ConfigIssue{
-					Type:    ConfigNotFound,
-					Message: fmt.Sprintf("Config not found for %s", configId),
-				})
+			var lookupErr error
+			if actionIdTimedOut {
+				// Only the fallback config, if any, can be checked
+				configIds, lookupErr = []string{""}, err
 			}
 
-			// Check if returned config is empty/invalid (like TypeScript's finally block)
-			if s.isEmptyVerificationConfig(verificationConfig) {
-				issues = append(issues, ConfigIssue{
-					Type:    ConfigNotFound,
-					Message: fmt.Sprintf("Config not found for %s", configId),
-				})
+			// The proof passes if it satisfies any candidate. When none is satisfied the issues of
+			// the first candidate are reported.
+			var matched *configCandidate
+			for _, configId := range configIds {
+				candidate := s.checkConfigCandidate(ctx, configId, lookupErr, attestationId, publicSignals,
+					discloseIndices, exists, genericDiscloseOutput, userContextData)
+				if matched == nil || candidate.satisfied() {
+					matched = candidate
+				}
+				if candidate.satisfied() {
+					break
+				}
+			}
+			if len(configIds) > 1 {
+				s.logger.Printf("self: userContextData %s checked against config candidates %v, selected %s",
+					RedactUserContextData(userContextData), configIds, matched.configId)
 			}
 
-			// Only proceed with validations if no error and config is not empty
-			if configErr == nil && !s.isEmptyVerificationConfig(verificationConfig) {
-				forbiddenCountriesList, genericDiscloseOutput, _ = s.validateWithConfig(attestationId, verificationConfig, publicSignals, discloseIndices, genericDiscloseOutput, &issues)
+			state.configId = matched.configId
+			if matched.err == nil {
+				state.config = &matched.config
+				// Countries are reported only as far as the config lets the result disclose them
+				masked := matched.config.MaskDisclosures(matched.discloseOutput)
+				state.issuingState = string(documentIssuingState(attestationId, masked))
+				state.nationality = string(documentNationality(attestationId, masked))
 			}
+			verificationConfig, configErr = matched.config, matched.err
+			forbiddenCountriesList, genericDiscloseOutput = matched.forbiddenCountriesList, matched.discloseOutput
+			issues = append(issues, matched.issues...)
 		}
 	}
 
+	// Issues raised by advisory checks are reported on the result instead of failing verification
+	var advisoryIssues []ConfigIssue
+	issues, advisoryIssues = verificationConfig.partitionAdvisoryIssues(issues)
+
 	// If there are validation issues, return them
 	if len(issues) > 0 {
-		return nil, NewConfigMismatchError(issues)
+		var mismatchErr error = NewConfigMismatchError(issues)
+		if len(issues) == 1 && issues[0].Type == InvalidScope {
+			// The most common integration mistake, reported with both scopes
+			mismatchErr = issues[0].err()
+		}
+		s.logger.Printf("self: verification rejected for userContextData %s: %v",
+			RedactUserContextData(userContextData), issueTypes(issues))
+		return nil, mismatchErr
+	}
+
+	var isProofValid bool
+	var proofErr error
+	proofVerificationTime, err := s.runProofStep(ctx, "proof verification", func(ctx context.Context) {
+		isProofValid, proofErr = s.checkProof(&bind.CallOpts{Context: ctx}, attestationId, layout, attestationIdBytes32, proof, publicSignals)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if proofErr != nil {
+		return nil, proofErr
+	}
+	if !isProofValid {
+		s.logger.Printf("self: invalid proof for userContextData %s", RedactUserContextData(userContextData))
+	}
+
+	if forbiddenCountriesList == nil {
+		if exists {
+			forbiddenCountriesListPacked := make([]string, 4)
+			for i := 0; i < 4; i++ {
+				forbiddenCountriesListPacked[i] = publicSignals[discloseIndices.ForbiddenCountriesListPackedIndex+i]
+			}
+			forbiddenCountriesList = UnpackForbiddenCountriesList(forbiddenCountriesListPacked)
+		}
+	}
+
+	// Calculate cumulative OFAC: true if any OFAC check is enabled
+	cumulativeOfac := false
+	for _, ofacCheck := range genericDiscloseOutput.Ofac {
+		if ofacCheck {
+			cumulativeOfac = true
+			break
+		}
+	}
+
+	isOfacValid := false
+	ofacSource := OFACSourceNone
+	if configErr == nil {
+		isOfacValid, ofacSource, err = s.evaluateOfac(ctx, verificationConfig, genericDiscloseOutput, cumulativeOfac)
+		if err != nil && verificationConfig.isAdvisory(CheckOfac) {
+			advisoryIssues = append(advisoryIssues, ConfigIssue{
+				Type:    InvalidOfac,
+				Message: fmt.Sprintf("OFAC check inconclusive: %v", err),
+			})
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	var ofacOverride *OFACOverride
+	if configErr == nil && verificationConfig.Ofac && !isOfacValid && s.ofacOverrideStore != nil {
+		ofacOverride = s.applyOFACOverride(ctx, genericDiscloseOutput.Nullifier, userContextData)
+		if ofacOverride != nil {
+			isOfacValid, ofacSource = true, OFACSourceOverride
+		}
+	}
+
+	var rawDiscloseOutput *GenericDiscloseOutput
+	if s.includeRawDisclosure {
+		raw := genericDiscloseOutput
+		rawDiscloseOutput = &raw
+	}
+
+	var mrzLines []string
+	if s.includeMRZLines {
+		// Only documents with an MRZ have lines to review
+		mrzLines, _ = ReconstructMRZLines(attestationId, publicSignals)
+	}
+
+	// Mask what the policy did not request, then apply the expiry disclosure mode to what remains
+	disclosureStatus := verificationConfig.DisclosureStatuses(attestationId, genericDiscloseOutput)
+	disclosedFields := disclosedFieldSet(attestationId, genericDiscloseOutput)
+	genericDiscloseOutput = verificationConfig.MaskDisclosures(genericDiscloseOutput)
+	genericDiscloseOutput = verificationConfig.applyExpiryDisclosure(attestationId, genericDiscloseOutput, time.Now())
+	genericDiscloseOutput = applyISODates(attestationId, genericDiscloseOutput, time.Now())
+
+	return &VerificationResult{
+		AttestationId:  attestationId,
+		CircuitVersion: layout.Version,
+		IsValidDetails: IsValidDetails{
+			IsValid:           isProofValid,
+			IsMinimumAgeValid: true,
+			IsOfacValid:       isOfacValid,
+		},
+		OFACSource:             ofacSource,
+		OFACOverride:           ofacOverride,
+		ForbiddenCountriesList: forbiddenCountriesList,
+		DiscloseOutput:         genericDiscloseOutput,
+		DisclosureStatus:       disclosureStatus,
+		RawDiscloseOutput:      rawDiscloseOutput,
+		MRZLines:               mrzLines,
+		AdvisoryIssues:         advisoryIssues,
+		UserData: UserData{
+			UserIdentifier:  userIdentifier,
+			UserDefinedData: userDefinedData,
+		},
+		ConfigId:              state.configId,
+		Config:                verificationConfig,
+		ConfigHash:            verificationConfig.Hash(),
+		ProofVerificationTime: proofVerificationTime,
+		disclosedFields:       disclosedFields,
+	}, nil
+}
+
+// scopeMismatchIssue returns the InvalidScope issue for a proof committing to the scope hash got
+// rather than to the expected scope
+func (s *BackendVerifier) scopeMismatchIssue(expected verifierScope, got string) ConfigIssue {
+	return ConfigIssue{
+		Type: InvalidScope,
+		Message: fmt.Sprintf("Scope does not match with the one in the circuit\nCircuit: %s\nScope: %s",
+			got, expected.hash),
+		Err: &ScopeMismatchError{Expected: expected.hash, Got: got, ScopeName: expected.name, Endpoint: s.endpoint},
+	}
+}
+
+// configCandidate is the outcome of checking a proof against one candidate config
+type configCandidate struct {
+	configId               string
+	config                 VerificationConfig
+	err                    error
+	forbiddenCountriesList []string
+	discloseOutput         GenericDiscloseOutput
+	issues                 []ConfigIssue
+}
+
+// satisfied reports whether the candidate's config was found and raised no blocking issue
+func (c *configCandidate) satisfied() bool {
+	if c.err != nil {
+		return false
+	}
+	blocking, _ := c.config.partitionAdvisoryIssues(c.issues)
+	return len(blocking) == 0
+}
+
+// checkConfigCandidate fetches the config with the given ID and checks the proof against it.
+// A non-nil lookupErr (a timed-out action ID lookup) is used in place of fetching the config.
+func (s *BackendVerifier) checkConfigCandidate(
+	ctx context.Context,
+	configId string,
+	lookupErr error,
+	attestationId AttestationId,
+	publicSignals []string,
+	discloseIndices DiscloseIndicesEntry,
+	hasDiscloseIndices bool,
+	discloseOutput GenericDiscloseOutput,
+	userContextData string,
+) *configCandidate {
+	candidate := &configCandidate{configId: configId, discloseOutput: discloseOutput}
+
+	// Get verification config
+	if snapshot, hasSnapshot := configSnapshotFromContext(ctx); hasSnapshot {
+		candidate.config = snapshot
+	} else if lookupErr != nil {
+		candidate.err = lookupErr
+	} else {
+		candidate.config, candidate.err = callConfigStore(s, ctx, func(ctx context.Context) (VerificationConfig, error) {
+			return ResolveConfig(ctx, s.configStorage, configId)
+		})
+	}
+	if errors.Is(candidate.err, ErrConfigStoreTimeout) {
+		candidate.config, candidate.err = s.configStoreTimeoutFallback(candidate.err, userContextData)
+	}
+
+	// Check for GetConfig error first
+	if errors.Is(candidate.err, ErrConfigStoreTimeout) {
+		// Reported separately so store health can be told apart from missing configs
+		candidate.issues = append(candidate.issues, ConfigIssue{
+			Type:    ConfigNotFound,
+			Message: candidate.err.Error(),
+			Err:     candidate.err,
+		})
+	} else if candidate.err != nil {
+		candidate.issues = append(candidate.issues, ConfigIssue{
+			Type:    ConfigNotFound,
+			Message: fmt.Sprintf("Config not found for %s", configId),
+		})
 	}
 
-	isProofValid := false
+	// Check if returned config is empty/invalid (like TypeScript's finally block)
+	if isEmptyVerificationConfig(candidate.config) {
+		candidate.issues = append(candidate.issues, ConfigIssue{
+			Type:    ConfigNotFound,
+			Message: fmt.Sprintf("Config not found for %s", configId),
+		})
+	}
 
-	// Use the pre-calculated attestationIdBytes32 from above
-	verifierAddress, err := s.identityVerificationHubContract.DiscloseVerifier(nil, attestationIdBytes32)
+	// Only proceed with validations if no error and config is not empty
+	if hasDiscloseIndices && candidate.err == nil && !isEmptyVerificationConfig(candidate.config) {
+		disclosures, hasDisclosures := disclosuresFromContext(ctx)
+		if hasDisclosures {
+			applyDisclosureOverride(&candidate.config, disclosures, &candidate.issues)
+			validateRequiredDisclosures(attestationId, disclosures, discloseOutput, &candidate.issues)
+		}
+		candidate.forbiddenCountriesList, candidate.discloseOutput, _ = s.validateWithConfig(
+			attestationId, candidate.config, publicSignals, discloseIndices, discloseOutput, &candidate.issues)
+	}
+	return candidate
+}
+
+// checkProof verifies the zero-knowledge proof with the verifier contract registered for the attestation type
+func (s *BackendVerifier) checkProof(
+	callOpts *bind.CallOpts,
+	attestationId AttestationId,
+	layout CircuitLayout,
+	attestationIdBytes32 [32]byte,
+	proof VcAndDiscloseProof,
+	publicSignals []string,
+) (bool, error) {
+	verifierAddress, err := s.identityVerificationHubContract.DiscloseVerifier(callOpts, attestationIdBytes32)
 	if err != nil || verifierAddress == (common.Address{}) {
-		return nil, fmt.Errorf("verifier contract not found")
+		return false, fmt.Errorf("verifier contract not found")
 	}
 
 	var verifierContract *bindings.Verifier
@@ -353,47 +871,47 @@ func (s *BackendVerifier) Verify(
 	if attestationId == Aadhaar {
 		aadhaarVerifierContract, err = bindings.NewAadhaarVerifier(verifierAddress, s.provider)
 		if err != nil {
-			return nil, fmt.Errorf("aadhaar verifier contract not found")
+			return false, fmt.Errorf("aadhaar verifier contract not found")
 		}
 	} else {
 		verifierContract, err = bindings.NewVerifier(verifierAddress, s.provider)
 		if err != nil {
-			return nil, fmt.Errorf("verifier contract not found")
+			return false, fmt.Errorf("verifier contract not found")
 		}
 	}
 
 	// Convert string proof fields to *big.Int
 	a0, ok := new(big.Int).SetString(proof.A[0], 10)
 	if !ok {
-		return nil, fmt.Errorf("invalid proof.A[0]: %s", proof.A[0])
+		return false, fmt.Errorf("invalid proof.A[0]: %s", proof.A[0])
 	}
 	a1, ok := new(big.Int).SetString(proof.A[1], 10)
 	if !ok {
-		return nil, fmt.Errorf("invalid proof.A[1]: %s", proof.A[1])
+		return false, fmt.Errorf("invalid proof.A[1]: %s", proof.A[1])
 	}
 	b00, ok := new(big.Int).SetString(proof.B[0][0], 10)
 	if !ok {
-		return nil, fmt.Errorf("invalid proof.B[0][0]: %s", proof.B[0][0])
+		return false, fmt.Errorf("invalid proof.B[0][0]: %s", proof.B[0][0])
 	}
 	b01, ok := new(big.Int).SetString(proof.B[0][1], 10)
 	if !ok {
-		return nil, fmt.Errorf("invalid proof.B[0][1]: %s", proof.B[0][1])
+		return false, fmt.Errorf("invalid proof.B[0][1]: %s", proof.B[0][1])
 	}
 	b10, ok := new(big.Int).SetString(proof.B[1][0], 10)
 	if !ok {
-		return nil, fmt.Errorf("invalid proof.B[1][0]: %s", proof.B[1][0])
+		return false, fmt.Errorf("invalid proof.B[1][0]: %s", proof.B[1][0])
 	}
 	b11, ok := new(big.Int).SetString(proof.B[1][1], 10)
 	if !ok {
-		return nil, fmt.Errorf("invalid proof.B[1][1]: %s", proof.B[1][1])
+		return false, fmt.Errorf("invalid proof.B[1][1]: %s", proof.B[1][1])
 	}
 	c0, ok := new(big.Int).SetString(proof.C[0], 10)
 	if !ok {
-		return nil, fmt.Errorf("invalid proof.C[0]: %s", proof.C[0])
+		return false, fmt.Errorf("invalid proof.C[0]: %s", proof.C[0])
 	}
 	c1, ok := new(big.Int).SetString(proof.C[1], 10)
 	if !ok {
-		return nil, fmt.Errorf("invalid proof.C[1]: %s", proof.C[1])
+		return false, fmt.Errorf("invalid proof.C[1]: %s", proof.C[1])
 	}
 
 	// Convert proof format: swaps B coordinates [proof.b[0][1], proof.b[0][0]]
@@ -406,12 +924,7 @@ func (s *BackendVerifier) Verify(
 	aFormatted := [2]*big.Int{a0, a1}
 	cFormatted := [2]*big.Int{c0, c1}
 
-	var publicSignalLength int
-	if attestationId == Aadhaar {
-		publicSignalLength = 19
-	} else {
-		publicSignalLength = 21
-	}
+	publicSignalLength := layout.PublicSignalsCount
 
 	publicSignalsArray := make([]*big.Int, publicSignalLength)
 	for i, signal := range publicSignals {
@@ -433,61 +946,22 @@ func (s *BackendVerifier) Verify(
 
 	// Call appropriate verifier based on attestation type
 	var isValid bool
-	if attestationId == Aadhaar {
-		var aadhaarSignals [19]*big.Int
-		copy(aadhaarSignals[:], publicSignalsArray)
-		isValid, err = aadhaarVerifierContract.VerifyProof(nil, aFormatted, bFormatted, cFormatted, aadhaarSignals)
-	} else {
-		var regularSignals [21]*big.Int
-		copy(regularSignals[:], publicSignalsArray)
-		isValid, err = verifierContract.VerifyProof(nil, aFormatted, bFormatted, cFormatted, regularSignals)
-	}
-
-	if err != nil {
-		isProofValid = false
-	} else {
-		isProofValid = isValid
-	}
-
-	if forbiddenCountriesList == nil {
-		discloseIndices, exists = DiscloseIndices[attestationId]
-		if exists {
-			forbiddenCountriesListPacked := make([]string, 4)
-			for i := 0; i < 4; i++ {
-				forbiddenCountriesListPacked[i] = publicSignals[discloseIndices.ForbiddenCountriesListPackedIndex+i]
-			}
-			forbiddenCountriesList = UnpackForbiddenCountriesList(forbiddenCountriesListPacked)
-		}
-	}
-
-	// Calculate cumulative OFAC: true if any OFAC check is enabled
-	cumulativeOfac := false
-	for _, ofacCheck := range genericDiscloseOutput.Ofac {
-		if ofacCheck {
-			cumulativeOfac = true
-			break
+	if panicErr := s.recoverPanic("proof verification", func() {
+		if attestationId == Aadhaar {
+			var aadhaarSignals [19]*big.Int
+			copy(aadhaarSignals[:], publicSignalsArray)
+			isValid, err = aadhaarVerifierContract.VerifyProof(callOpts, aFormatted, bFormatted, cFormatted, aadhaarSignals)
+		} else {
+			var regularSignals [21]*big.Int
+			copy(regularSignals[:], publicSignalsArray)
+			isValid, err = verifierContract.VerifyProof(callOpts, aFormatted, bFormatted, cFormatted, regularSignals)
 		}
+	}); panicErr != nil {
+		return false, panicErr
 	}
 
-	isOfacValid := false
-	if configErr == nil && verificationConfig.Ofac {
-		isOfacValid = cumulativeOfac
-	}
-
-	return &VerificationResult{
-		AttestationId: attestationId,
-		IsValidDetails: IsValidDetails{
-			IsValid:           isProofValid,
-			IsMinimumAgeValid: true,
-			IsOfacValid:       isOfacValid,
-		},
-		ForbiddenCountriesList: forbiddenCountriesList,
-		DiscloseOutput:         genericDiscloseOutput,
-		UserData: UserData{
-			UserIdentifier:  userIdentifier,
-			UserDefinedData: userDefinedData,
-		},
-	}, nil
+	// A failed contract call means the proof does not verify
+	return err == nil && isValid, nil
 }
 
 // validateWithConfig performs config-based validations (forbidden countries, minimum age, timestamp, OFAC)
@@ -531,6 +1005,27 @@ func (s *BackendVerifier) validateWithConfig(
 		})
 	}
 
+	validateSanctionedNationalities(verificationConfig, forbiddenCountriesList, genericDiscloseOutput, issues)
+	validateAllowedIssuingStates(verificationConfig, attestationId, genericDiscloseOutput, issues)
+	validateNationalityMatchesIssuer(verificationConfig, attestationId, genericDiscloseOutput, issues)
+	validateAllowedDocumentSubtypes(verificationConfig, attestationId, genericDiscloseOutput, issues)
+
+	if verificationConfig.RequireBiometricCommitment && !genericDiscloseOutput.BiometricCommitment {
+		*issues = append(*issues, ConfigIssue{
+			Type:    MissingBiometric,
+			Message: "Proof does not commit to a biometric hash",
+		})
+	}
+
+	if s.disclosureMode == DisclosureModeStrict {
+		if unrequested := verificationConfig.unrequestedDisclosures(attestationId, genericDiscloseOutput); len(unrequested) > 0 {
+			*issues = append(*issues, ConfigIssue{
+				Type:    InvalidDisclosure,
+				Message: fmt.Sprintf("Proof discloses fields the policy did not request: %v", unrequested),
+			})
+		}
+	}
+
 	if verificationConfig.MinimumAge != 0 {
 		configMinAge := verificationConfig.MinimumAge
 		circuitMinAge := genericDiscloseOutput.MinimumAge
@@ -550,18 +1045,112 @@ func (s *BackendVerifier) validateWithConfig(
 		}
 	}
 
+	if verificationConfig.MinimumAgeMarginDays > 0 {
+		validateMinimumAgeMargin(attestationId, verificationConfig, genericDiscloseOutput, publicSignals, discloseIndices, issues)
+	}
+
+	if verificationConfig.requiresBackendScreen() && s.ofacScreener == nil {
+		*issues = append(*issues, ConfigIssue{
+			Type:    InvalidOfac,
+			Message: fmt.Sprintf("OFAC mode %q requires an OFACScreener, none is configured", verificationConfig.OfacMode),
+		})
+	}
+
 	s.validateTimestamp(attestationId, publicSignals, discloseIndices, issues)
 
 	return forbiddenCountriesList, genericDiscloseOutput, nil
 }
 
-// validateTimestamp checks if the circuit timestamp is within acceptable range (not too old, not in future)
+// validateTimestamp checks if the circuit timestamp is within acceptable range (not too old, not in future).
+// Every built-in layout commits the current date, so proofs with all date signals zero are rejected
+// as too old: the circuit also checks the document expiry against that date.
 func (s *BackendVerifier) validateTimestamp(
 	attestationId AttestationId,
 	publicSignals []string,
 	discloseIndices DiscloseIndicesEntry,
 	issues *[]ConfigIssue,
 ) {
+	circuitTimestamp, ok := circuitDate(attestationId, publicSignals, discloseIndices)
+	if !ok {
+		*issues = append(*issues, ConfigIssue{
+			Type:    InvalidTimestamp,
+			Message: "Circuit timestamp is missing",
+			Err:     ErrProofTooOld,
+		})
+		return
+	}
+	currentTimestamp := time.Now().UTC()
+
+	// Check if timestamp is further in the future than the allowed skew
+	if circuitTimestamp.After(currentTimestamp.Add(s.futureSkew)) {
+		*issues = append(*issues, ConfigIssue{
+			Type:    InvalidTimestamp,
+			Message: "Circuit timestamp is in the future",
+			Err:     ErrProofFromFuture,
+		})
+	}
+
+	// Check if timestamp is older than the maximum proof age (using end-of-day logic)
+	// Add 23 hours + 59 minutes + 59 seconds to circuit timestamp (matching TypeScript logic)
+	circuitTimestampEOD := circuitTimestamp.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+	if circuitTimestampEOD.Before(currentTimestamp.Add(-s.maxProofAge)) {
+		*issues = append(*issues, ConfigIssue{
+			Type:    InvalidTimestamp,
+			Message: "Circuit timestamp is too old",
+			Err:     ErrProofTooOld,
+		})
+	}
+}
+
+// validateMinimumAgeMargin checks that the holder turned MinimumAge at least MinimumAgeMarginDays
+// before the proof's generation date, from the disclosed date of birth. Proofs carrying no date are
+// measured against the current date.
+func validateMinimumAgeMargin(
+	attestationId AttestationId,
+	config VerificationConfig,
+	output GenericDiscloseOutput,
+	publicSignals []string,
+	discloseIndices DiscloseIndicesEntry,
+	issues *[]ConfigIssue,
+) {
+	referenceDate, ok := circuitDate(attestationId, publicSignals, discloseIndices)
+	if !ok {
+		referenceDate = time.Now().UTC()
+	}
+
+	if !isFieldDisclosedIn(attestationId, output, DisclosureDateOfBirth) {
+		*issues = append(*issues, ConfigIssue{
+			Type:    InvalidMinimumAge,
+			Message: "Minimum age margin requires the proof to disclose the date of birth",
+			Err:     ErrMinimumAgeMarginNotMet,
+		})
+		return
+	}
+	dateOfBirth, ok := parseDateOfBirth(attestationId, removeNullBytes(output.DateOfBirth), referenceDate)
+	if !ok {
+		*issues = append(*issues, ConfigIssue{
+			Type:    InvalidMinimumAge,
+			Message: fmt.Sprintf("Disclosed date of birth %q is not a valid date", removeNullBytes(output.DateOfBirth)),
+			Err:     ErrMinimumAgeMarginNotMet,
+		})
+		return
+	}
+
+	// AddDate normalises a 29 February birthday to 1 March in common years, erring on the strict side
+	marginEnd := dateOfBirth.AddDate(config.MinimumAge, 0, config.MinimumAgeMarginDays)
+	if marginEnd.After(referenceDate) {
+		*issues = append(*issues, ConfigIssue{
+			Type: InvalidMinimumAge,
+			Message: fmt.Sprintf("Holder is not at least %d years and %d days old on %s",
+				config.MinimumAge, config.MinimumAgeMarginDays, referenceDate.Format(isoDateLayout)),
+			Err: ErrMinimumAgeMarginNotMet,
+		})
+	}
+}
+
+// circuitDate returns the date the proof was generated on, as committed in its public signals.
+// Reports false when the date signals are all zero, which validateTimestamp rejects.
+func circuitDate(attestationId AttestationId, publicSignals []string, discloseIndices DiscloseIndicesEntry) (time.Time, bool) {
 	// Extract timestamp components from circuit (YYMMDD format)
 	currentDateIndex := discloseIndices.CurrentDateIndex
 
@@ -624,36 +1213,85 @@ func (s *BackendVerifier) validateTimestamp(
 	}
 	day, _ := strconv.Atoi(dayStr)
 
+	// A proof without a generation date has all date signals zero
+	if month == 0 && day == 0 {
+		return time.Time{}, false
+	}
+
 	// Create circuit timestamp
 	// Note: TypeScript subtracts 1 from month because JS Date is 0-indexed (0=Jan)
 	// Go time.Month is 1-indexed (1=Jan), so we use month directly
-	circuitTimestamp := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
-	currentTimestamp := time.Now().UTC()
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC), true
+}
 
-	// Check if timestamp is more than 1 day in the future
-	oneDayAhead := currentTimestamp.Add(24 * time.Hour)
-	if circuitTimestamp.After(oneDayAhead) {
-		*issues = append(*issues, ConfigIssue{
-			Type:    InvalidTimestamp,
-			Message: "Circuit timestamp is in the future",
-		})
-	}
+// checkUserContextData checks that the user context hash committed in the circuit is the hash of
+// userContextData, and its signature when a ContextDataVerifier is set
+func (s *BackendVerifier) checkUserContextData(userContextData string, userContextHashSignal string, issues *[]ConfigIssue) {
+	// Get user context hash from circuit
+	userContextHashInCircuit := new(big.Int)
+	userContextHashInCircuit.SetString(userContextHashSignal, 10)
 
-	// Check if timestamp is more than 1 day in the past (using end-of-day logic)
-	// Add 23 hours + 59 minutes + 59 seconds to circuit timestamp (matching TypeScript logic)
-	circuitTimestampEOD := circuitTimestamp.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
-	oneDayAgo := currentTimestamp.Add(-24 * time.Hour)
-	if circuitTimestampEOD.Before(oneDayAgo) {
+	// Calculate expected user context hash
+	userContextDataBytes, err := hex.DecodeString(userContextData)
+	if err != nil {
 		*issues = append(*issues, ConfigIssue{
-			Type:    InvalidTimestamp,
-			Message: "Circuit timestamp is too old",
+			Type:    InvalidUserContextHash,
+			Message: fmt.Sprintf("Invalid hex string in userContextData: %v", err),
 		})
+	} else {
+		userContextHashStr := CalculateUserIdentifierHash(userContextDataBytes)
+		userContextHash := new(big.Int)
+		userContextHashStr = strings.TrimPrefix(userContextHashStr, "0x")
+		userContextHash.SetString(userContextHashStr, 16)
+
+		if userContextHashInCircuit.Cmp(userContextHash) != 0 {
+			*issues = append(*issues, ConfigIssue{
+				Type: InvalidUserContextHash,
+				Message: fmt.Sprintf("User context hash does not match with the one in the circuit\nCircuit: %s\nUser context hash: %s",
+					userContextHashInCircuit.String(), userContextHash.String()),
+			})
+		}
+
+		if s.contextDataVerifier != nil {
+			if err := s.contextDataVerifier.Verify(userContextDataBytes); err != nil {
+				*issues = append(*issues, ConfigIssue{
+					Type:    InvalidContextDataSignature,
+					Message: fmt.Sprintf("User context data signature is invalid: %v", err),
+				})
+			}
+		}
 	}
 }
 
 // isEmptyVerificationConfig checks if a VerificationConfig is empty/invalid
-func (s *BackendVerifier) isEmptyVerificationConfig(config VerificationConfig) bool {
+func isEmptyVerificationConfig(config VerificationConfig) bool {
 	return config.MinimumAge == 0 &&
 		len(config.ExcludedCountries) == 0 &&
-		!config.Ofac
+		len(config.SanctionedNationalities) == 0 &&
+		len(config.AllowedIssuingStates) == 0 &&
+		len(config.AllowedDocumentSubtypes) == 0 &&
+		len(config.AdvisoryChecks) == 0 &&
+		config.Disclosures == nil &&
+		config.ExpiryDisclosure == "" &&
+		config.MinimumAgeMarginDays == 0 &&
+		!config.RequireBiometricCommitment &&
+		!config.RequireNationalityMatchesIssuer &&
+		!config.RejectIssuedAsMinor &&
+		!config.Ofac &&
+		config.OfacMode == "" &&
+		!config.TrustProofOfac &&
+		config.Parent == ""
+}
+
+// recoverPanic runs fn, converting a panic into an error matching ErrInternalVerification.
+// The panic is logged with its stack trace.
+func (s *BackendVerifier) recoverPanic(step string, fn func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Printf("self: panic during %s: %v\n%s", step, r, debug.Stack())
+			err = fmt.Errorf("%w: panic during %s", ErrInternalVerification, step)
+		}
+	}()
+	fn()
+	return nil
 }